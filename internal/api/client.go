@@ -6,6 +6,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/Cagangedik/cli-tool/internal/config"
@@ -15,44 +19,129 @@ type Client struct {
 	baseURL    string
 	token      string
 	httpClient *http.Client
+	// networkErr is set when the network settings are invalid; every
+	// request then fails with it
+	networkErr error
+
+	// session is the config the token came from; when set, the token is
+	// read from it and refreshed in place before it expires
+	session *config.Config
+
+	// graph records whether the server has the graph neighbor and path
+	// endpoints; it is shared by copies of the client for the same server
+	graph *graphSupport
+}
+
+// graphSupport remembers that a server lacks the graph endpoints, so later
+// lookups go straight to the graph built from the project's items
+type graphSupport struct {
+	missing atomic.Bool
 }
 
 func NewClient(cfg *config.Config) *Client {
+	httpClient, err := NewHTTPClient(cfg)
+	if err != nil {
+		httpClient = &http.Client{Timeout: DefaultTimeout}
+		err = fmt.Errorf("invalid network settings: %w", err)
+	}
 	return &Client{
-		baseURL: cfg.APIURL,
-		token:   cfg.Token,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:    cfg.APIURL,
+		token:      cfg.Token,
+		httpClient: httpClient,
+		networkErr: err,
+		session:    cfg,
+		graph:      &graphSupport{},
 	}
 }
 
 func (c *Client) WithToken(token string) *Client {
+	session := c.session
+	if token != c.authToken() {
+		// An explicit token (e.g. --token) isn't ours to refresh
+		session = nil
+	}
 	return &Client{
 		baseURL:    c.baseURL,
 		token:      token,
 		httpClient: c.httpClient,
+		networkErr: c.networkErr,
+		session:    session,
+		graph:      c.graph,
 	}
 }
 
 func (c *Client) WithBaseURL(url string) *Client {
+	graph := c.graph
+	if url != c.baseURL {
+		graph = &graphSupport{}
+	}
 	return &Client{
 		baseURL:    url,
 		token:      c.token,
 		httpClient: c.httpClient,
+		networkErr: c.networkErr,
+		session:    c.session,
+		graph:      graph,
 	}
 }
 
 func (c *Client) doRequest(method, path string, body interface{}, projectID string) (*http.Response, error) {
-	url := fmt.Sprintf("%s%s", c.baseURL, path)
-
-	var reqBody io.Reader
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonData)
+	}
+
+	if err := c.ensureFreshToken(); err != nil {
+		return nil, err
+	}
+
+	token := c.authToken()
+	resp, err := c.send(method, path, jsonData, projectID, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// The server may reject a token before its recorded expiry; refresh
+	// once and retry
+	if resp.StatusCode == http.StatusUnauthorized && c.canRefresh() {
+		resp.Body.Close()
+		if err := c.refreshSession(token); err != nil {
+			return nil, err
+		}
+		token = c.authToken()
+		resp, err = c.send(method, path, jsonData, projectID, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		defer resp.Body.Close()
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		authErr := &AuthError{StatusCode: resp.StatusCode, Body: string(bodyBytes)}
+		if token != "" {
+			authErr.TokenType = TokenTypeOf(token)
+		}
+		return nil, authErr
+	}
+
+	return resp, nil
+}
+
+func (c *Client) send(method, path string, jsonData []byte, projectID, token string) (*http.Response, error) {
+	if c.networkErr != nil {
+		return nil, c.networkErr
+	}
+
+	url := fmt.Sprintf("%s%s", c.baseURL, path)
+
+	var reqBody io.Reader
+	if jsonData != nil {
+		reqBody = bytes.NewReader(jsonData)
 	}
 
 	req, err := http.NewRequest(method, url, reqBody)
@@ -61,8 +150,8 @@ func (c *Client) doRequest(method, path string, body interface{}, projectID stri
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	if c.token != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	}
 	if projectID != "" {
 		req.Header.Set("X-Project-ID", projectID)
@@ -76,6 +165,37 @@ func (c *Client) doRequest(method, path string, body interface{}, projectID stri
 	return resp, nil
 }
 
+// AuthError is returned for 401 and 403 responses. Its message explains the
+// failure in terms of the kind of token that was used.
+type AuthError struct {
+	StatusCode int
+	TokenType  string // "" when the request was sent without a token
+	Body       string
+}
+
+// Is makes a rejected user token match ErrSessionExpired. A request sent
+// without a token had no session to expire.
+func (e *AuthError) Is(target error) bool {
+	return target == ErrSessionExpired && e.StatusCode == http.StatusUnauthorized && e.TokenType == TokenTypeUser
+}
+
+func (e *AuthError) Error() string {
+	var hint string
+	switch {
+	case e.TokenType == "":
+		hint = "you are not logged in; run 'hopsule login'"
+	case e.TokenType == TokenTypeService && e.StatusCode == http.StatusUnauthorized:
+		hint = "the service token was rejected; it may have expired or been revoked"
+	case e.TokenType == TokenTypeService:
+		hint = "the service token lacks the scope or project access for this request"
+	case e.StatusCode == http.StatusUnauthorized:
+		hint = "your session is invalid or has expired; run 'hopsule login'"
+	default:
+		hint = "your account does not have access to this resource"
+	}
+	return fmt.Sprintf("API error: %d - %s (%s)", e.StatusCode, strings.TrimSpace(e.Body), hint)
+}
+
 // ListDecisionsResponse is the response from GET /decisions
 type ListDecisionsResponse struct {
 	Decisions []Decision `json:"decisions"`
@@ -225,6 +345,8 @@ type Decision struct {
 	AcceptedAt  *string  `json:"accepted_at,omitempty"`
 	AcceptedBy  *string  `json:"accepted_by,omitempty"`
 	Tags        []string `json:"tags,omitempty"`
+	ScopeKey    string   `json:"scope_key,omitempty"` // empty for project-wide decisions
+	Supersedes  string   `json:"supersedes,omitempty"` // ID of the decision this one replaces
 }
 
 type CreateDecisionRequest struct {
@@ -272,6 +394,16 @@ type DeviceAuthPollResponse struct {
 	Name      string `json:"name,omitempty"`
 	AvatarURL string `json:"avatar_url,omitempty"`
 	Token     string `json:"token,omitempty"`
+
+	// Set when the server issues expiring tokens
+	RefreshToken   string `json:"refresh_token,omitempty"`
+	TokenExpiresAt string `json:"token_expires_at,omitempty"`
+	TokenExpiresIn int    `json:"token_expires_in,omitempty"` // seconds
+}
+
+// Expiry returns the RFC 3339 expiry of the issued token, or "" if unknown
+func (r *DeviceAuthPollResponse) Expiry() string {
+	return tokenExpiry(r.TokenExpiresAt, r.TokenExpiresIn)
 }
 
 // DeviceAuthInit starts the device code flow
@@ -322,6 +454,73 @@ func (c *Client) DeviceAuthPoll(code string) (*DeviceAuthPollResponse, error) {
 	return &result, nil
 }
 
+// Session is a device signed in through the device flow
+type Session struct {
+	ID         string `json:"id"`
+	DeviceName string `json:"device_name"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+	Current    bool   `json:"current,omitempty"` // the session making the request
+}
+
+// ListSessionsResponse is the response from GET /auth/sessions
+type ListSessionsResponse struct {
+	Sessions []Session `json:"sessions"`
+}
+
+// Logout revokes the token the client is using
+func (c *Client) Logout() error {
+	resp, err := c.doRequest("POST", "/auth/logout", nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error: %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
+// ListSessions lists the devices signed in to the current user's account
+func (c *Client) ListSessions() ([]Session, error) {
+	resp, err := c.doRequest("GET", "/auth/sessions", nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result ListSessionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Sessions, nil
+}
+
+// RevokeSession signs a device out remotely
+func (c *Client) RevokeSession(sessionID string) error {
+	resp, err := c.doRequest("DELETE", fmt.Sprintf("/auth/sessions/%s", sessionID), nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error: %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
 // ============================================================================
 // USER & ORGANIZATION TYPES & METHODS
 // ============================================================================
@@ -352,11 +551,19 @@ type Project struct {
 	OrganizationID string `json:"organization_id"`
 }
 
-// MeResponse is the response from GET /me
+// MeResponse is the response from GET /me. For service tokens User is nil
+// and APIToken describes the token instead.
 type MeResponse struct {
 	User          *User           `json:"user"`
 	Organizations []*Organization `json:"organizations"`
 	Projects      []*Project      `json:"projects"`
+	TokenType     string          `json:"token_type,omitempty"` // "user" or "service"
+	APIToken      *APIToken       `json:"api_token,omitempty"`
+}
+
+// IsServiceToken reports whether the request was made with a service token
+func (m *MeResponse) IsServiceToken() bool {
+	return m.TokenType == TokenTypeService || (m.TokenType == "" && m.APIToken != nil)
 }
 
 // GetMe retrieves the current user's info
@@ -422,6 +629,119 @@ func (c *Client) ListProjects() ([]*Project, error) {
 	return result, nil
 }
 
+// ============================================================================
+// API TOKEN (SERVICE ACCOUNT) TYPES & METHODS
+// ============================================================================
+
+// Token types reported by GET /me
+const (
+	TokenTypeUser    = "user"
+	TokenTypeService = "service"
+)
+
+// ServiceTokenPrefix starts every service token, so the token type is known
+// without asking the API
+const ServiceTokenPrefix = "hps_"
+
+// Token scopes for service tokens
+const (
+	TokenScopeRead  = "read"
+	TokenScopeWrite = "write"
+)
+
+// TokenTypeOf returns the type of a raw token based on its prefix
+func TokenTypeOf(token string) string {
+	if strings.HasPrefix(token, ServiceTokenPrefix) {
+		return TokenTypeService
+	}
+	return TokenTypeUser
+}
+
+// APIToken is a project-scoped service token for CI and bots
+type APIToken struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix,omitempty"`
+	ProjectID  string   `json:"project_id"`
+	Scopes     []string `json:"scopes"`
+	CreatedBy  string   `json:"created_by,omitempty"`
+	CreatedAt  string   `json:"created_at"`
+	ExpiresAt  string   `json:"expires_at,omitempty"`
+	LastUsedAt string   `json:"last_used_at,omitempty"`
+	Token      string   `json:"token,omitempty"` // only set when created
+}
+
+// CreateAPITokenRequest is the request body for creating a service token
+type CreateAPITokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days,omitempty"`
+}
+
+// CreateAPIToken mints a service token for a project. The secret is only
+// returned by this call.
+func (c *Client) CreateAPIToken(projectID string, req *CreateAPITokenRequest) (*APIToken, error) {
+	resp, err := c.doRequest("POST", "/auth/tokens", req, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result APIToken
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// ListAPITokensResponse is the response from GET /auth/tokens
+type ListAPITokensResponse struct {
+	Tokens []APIToken `json:"tokens"`
+}
+
+// ListAPITokens lists the service tokens of a project
+func (c *Client) ListAPITokens(projectID string) ([]APIToken, error) {
+	resp, err := c.doRequest("GET", "/auth/tokens", nil, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var result ListAPITokensResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Tokens, nil
+}
+
+// RevokeAPIToken revokes a service token
+func (c *Client) RevokeAPIToken(projectID, tokenID string) error {
+	resp, err := c.doRequest("DELETE", fmt.Sprintf("/auth/tokens/%s", tokenID), nil, projectID)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("API error: %d - %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
 // ============================================================================
 // MEMORY TYPES & METHODS
 // ============================================================================
@@ -739,6 +1059,130 @@ func (c *Client) GetGraphStats(projectID string) (*GraphStats, error) {
 	return &stats, nil
 }
 
+// GetGraphNeighbors retrieves the nodes connected to a node, up to
+// opts.Depth edges away. On servers without the graph endpoints the graph
+// is built from the project's items instead, without code chunks.
+func (c *Client) GetGraphNeighbors(projectID, nodeID string, opts NeighborOptions) (*GraphNeighbors, error) {
+	if !c.HasGraphEndpoints() {
+		return c.localNeighbors(projectID, nodeID, opts)
+	}
+
+	query := url.Values{}
+	if opts.Depth > 0 {
+		query.Set("depth", strconv.Itoa(opts.Depth))
+	}
+	if len(opts.NodeTypes) > 0 {
+		query.Set("types", strings.Join(opts.NodeTypes, ","))
+	}
+	if len(opts.EdgeTypes) > 0 {
+		query.Set("edge_types", strings.Join(opts.EdgeTypes, ","))
+	}
+	path := fmt.Sprintf("/graph/nodes/%s/neighbors", url.PathEscape(nodeID))
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+
+	resp, err := c.doRequest("GET", path, nil, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if c.graphEndpointMissing(resp, body) {
+			return c.localNeighbors(projectID, nodeID, opts)
+		}
+		return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var neighbors GraphNeighbors
+	if err := json.NewDecoder(resp.Body).Decode(&neighbors); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &neighbors, nil
+}
+
+// GetGraphPaths retrieves the paths of at most maxDepth edges between two
+// nodes, shortest first. Like GetGraphNeighbors it falls back to a graph
+// built from the project's items on servers without the graph endpoints.
+func (c *Client) GetGraphPaths(projectID, fromID, toID string, maxDepth int) ([]GraphPath, error) {
+	if !c.HasGraphEndpoints() {
+		return c.localPaths(projectID, fromID, toID, maxDepth)
+	}
+
+	query := url.Values{}
+	query.Set("from", fromID)
+	query.Set("to", toID)
+	if maxDepth > 0 {
+		query.Set("max_depth", strconv.Itoa(maxDepth))
+	}
+
+	resp, err := c.doRequest("GET", "/graph/paths?"+query.Encode(), nil, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if c.graphEndpointMissing(resp, body) {
+			return c.localPaths(projectID, fromID, toID, maxDepth)
+		}
+		return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var result GraphPathsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return result.Paths, nil
+}
+
+// HasGraphEndpoints reports whether the server may have the graph neighbor
+// and path endpoints. It turns false once a lookup finds them missing.
+func (c *Client) HasGraphEndpoints() bool {
+	return c.graph == nil || !c.graph.missing.Load()
+}
+
+// graphEndpointMissing reports whether a response means the server has no
+// such graph endpoint, and remembers it. The endpoints answer errors such
+// as an unknown node with a JSON error body; a server without them answers
+// its generic 404.
+func (c *Client) graphEndpointMissing(resp *http.Response, body []byte) bool {
+	if resp.StatusCode != http.StatusNotFound {
+		return false
+	}
+	var apiErr struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Error != "" {
+		return false
+	}
+	if c.graph != nil {
+		c.graph.missing.Store(true)
+	}
+	return true
+}
+
+func (c *Client) localNeighbors(projectID, nodeID string, opts NeighborOptions) (*GraphNeighbors, error) {
+	g, err := LoadGraph(c, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return g.Neighbors(nodeID, opts)
+}
+
+func (c *Client) localPaths(projectID, fromID, toID string, maxDepth int) ([]GraphPath, error) {
+	g, err := LoadGraph(c, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return g.Paths(fromID, toID, maxDepth)
+}
+
 // ============================================================================
 // HOPPER AI CHAT TYPES & METHODS
 // ============================================================================
@@ -772,9 +1216,18 @@ type ChatRequest struct {
 // SendChatMessage sends a message to Hopper and streams the response
 // The callback is called with each chunk of the response
 func (c *Client) SendChatMessage(projectID string, req *ChatRequest, onChunk func(string)) error {
+	if c.networkErr != nil {
+		return c.networkErr
+	}
+
 	// Create longer timeout client for AI chat
 	chatClient := &http.Client{
-		Timeout: 120 * time.Second,
+		Transport: c.httpClient.Transport,
+		Timeout:   max(c.httpClient.Timeout, 120*time.Second),
+	}
+
+	if err := c.ensureFreshToken(); err != nil {
+		return err
 	}
 
 	url := fmt.Sprintf("%s/ai/hopper/chat", c.baseURL)
@@ -791,8 +1244,8 @@ func (c *Client) SendChatMessage(projectID string, req *ChatRequest, onChunk fun
 
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Accept", "text/event-stream")
-	if c.token != "" {
-		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	if token := c.authToken(); token != "" {
+		httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
 	}
 	if projectID != "" {
 		httpReq.Header.Set("X-Project-Id", projectID)
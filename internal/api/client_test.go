@@ -0,0 +1,320 @@
+package api_test
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/apitest"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/zalando/go-keyring"
+)
+
+func newClient(t *testing.T) (*apitest.Server, *api.Client) {
+	t.Helper()
+	// Refreshed tokens are saved to the config directory
+	t.Setenv("HOPSULE_CONFIG_DIR", t.TempDir())
+	srv := apitest.NewServer()
+	t.Cleanup(srv.Close)
+	return srv, api.NewClient(srv.Config())
+}
+
+func TestDecisionRoundTrip(t *testing.T) {
+	srv, client := newClient(t)
+
+	created, err := client.CreateDecision(apitest.ProjectID, api.CreateDecisionRequest{
+		Statement: "Use Go",
+		Rationale: "One static binary",
+		Tags:      []string{"lang"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID == "" || created.Status != "DRAFT" {
+		t.Errorf("created decision %+v, want a DRAFT with an ID", created)
+	}
+
+	got, err := client.GetDecision(apitest.ProjectID, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Statement != "Use Go" || got.Rationale != "One static binary" {
+		t.Errorf("got %+v", got)
+	}
+
+	accepted, err := client.AcceptDecision(apitest.ProjectID, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepted.Status != "ACCEPTED" || accepted.AcceptedAt == nil {
+		t.Errorf("accepted decision %+v", accepted)
+	}
+	deprecated, err := client.DeprecateDecision(apitest.ProjectID, created.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deprecated.Status != "DEPRECATED" {
+		t.Errorf("deprecated decision has status %s", deprecated.Status)
+	}
+
+	decisions, err := client.ListDecisions(apitest.ProjectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 1 || decisions[0].Status != "DEPRECATED" {
+		t.Errorf("listed %+v", decisions)
+	}
+
+	for _, r := range srv.Requests() {
+		if r.ProjectID != apitest.ProjectID {
+			t.Errorf("%s %s was sent without the project header", r.Method, r.Path)
+		}
+	}
+}
+
+func TestDecisionTransitionConflict(t *testing.T) {
+	srv, client := newClient(t)
+	draft := srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Go"})
+
+	_, err := client.DeprecateDecision(apitest.ProjectID, draft.ID)
+	if err == nil || !strings.Contains(err.Error(), "409") {
+		t.Fatalf("deprecating a draft: got %v, want a 409", err)
+	}
+	if _, err := client.AcceptDecision(apitest.ProjectID, draft.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.AcceptDecision(apitest.ProjectID, draft.ID); err == nil || !strings.Contains(err.Error(), "409") {
+		t.Fatalf("accepting twice: got %v, want a 409", err)
+	}
+	if _, err := client.AcceptDecision(apitest.ProjectID, "nope"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("accepting an unknown decision: got %v, want a 404", err)
+	}
+}
+
+func TestMemoryAndTaskRoundTrip(t *testing.T) {
+	srv, client := newClient(t)
+	d := srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Go"})
+
+	m, err := client.CreateMemory(apitest.ProjectID, api.CreateMemoryRequest{
+		Content:            "Builds take 2s",
+		Tags:               []string{"perf"},
+		RelatedDecisionIds: []string{d.ID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.UpdateMemory(apitest.ProjectID, m.ID, api.UpdateMemoryRequest{Content: "Builds take 3s"}); err != nil {
+		t.Fatal(err)
+	}
+	memories, err := client.ListMemories(apitest.ProjectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(memories) != 1 || memories[0].Content != "Builds take 3s" || len(memories[0].RelatedDecisionIds) != 1 {
+		t.Errorf("listed memories %+v", memories)
+	}
+
+	task, err := client.CreateTask(apitest.ProjectID, api.CreateTaskRequest{Title: "Set up CI", RelatedMemoryIds: []string{m.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	updated, err := client.UpdateTask(apitest.ProjectID, task.ID, api.UpdateTaskRequest{Status: "DONE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Status != "DONE" || updated.Title != "Set up CI" {
+		t.Errorf("updated task %+v", updated)
+	}
+
+	if err := client.DeleteTask(apitest.ProjectID, task.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteMemory(apitest.ProjectID, m.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(srv.Memories(apitest.ProjectID)) != 0 || len(srv.Tasks(apitest.ProjectID)) != 0 {
+		t.Error("deleted items are still on the server")
+	}
+}
+
+func TestServerErrorIsReturned(t *testing.T) {
+	srv, client := newClient(t)
+	srv.Inject(apitest.Fault{Method: "GET", Path: "/decisions", Status: http.StatusInternalServerError, Times: 1})
+
+	if _, err := client.ListDecisions(apitest.ProjectID); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("got %v, want a 500", err)
+	}
+	// The fault was used up
+	if _, err := client.ListDecisions(apitest.ProjectID); err != nil {
+		t.Fatalf("second request: %v", err)
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	srv, _ := newClient(t)
+	cfg := srv.Config()
+	cfg.Timeout = "50ms"
+	client := api.NewClient(cfg)
+
+	srv.Inject(apitest.Fault{Path: "/decisions", Latency: time.Second, Times: 1})
+	start := time.Now()
+	if _, err := client.ListDecisions(apitest.ProjectID); err == nil {
+		t.Fatal("slow request succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("request gave up after %s, want about 50ms", elapsed)
+	}
+}
+
+func TestRejectedTokenIsRefreshed(t *testing.T) {
+	srv, client := newClient(t)
+	srv.RevokeTokens()
+
+	if _, err := client.ListDecisions(apitest.ProjectID); err != nil {
+		t.Fatalf("request after the token was revoked: %v", err)
+	}
+	var refreshed bool
+	for _, r := range srv.Requests() {
+		refreshed = refreshed || r.Path == "/auth/refresh"
+	}
+	if !refreshed {
+		t.Error("the client didn't refresh its token")
+	}
+}
+
+func TestRejectedTokenWithoutRefresh(t *testing.T) {
+	srv, _ := newClient(t)
+	cfg := srv.Config()
+	cfg.RefreshToken = ""
+	client := api.NewClient(cfg)
+	srv.RevokeTokens()
+
+	_, err := client.ListDecisions(apitest.ProjectID)
+	if !errors.Is(err, api.ErrSessionExpired) {
+		t.Fatalf("got %v, want ErrSessionExpired", err)
+	}
+	var authErr *api.AuthError
+	if !errors.As(err, &authErr) || authErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got %v, want a 401 AuthError", err)
+	}
+}
+
+func TestRejectedRequestWithoutToken(t *testing.T) {
+	srv, _ := newClient(t)
+	cfg := srv.Config()
+	cfg.Token = ""
+	cfg.RefreshToken = ""
+	client := api.NewClient(cfg)
+
+	_, err := client.ListDecisions(apitest.ProjectID)
+	var authErr *api.AuthError
+	if !errors.As(err, &authErr) || authErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("got %v, want a 401 AuthError", err)
+	}
+	if errors.Is(err, api.ErrSessionExpired) {
+		t.Errorf("a request without a token reported an expired session: %v", err)
+	}
+}
+
+func TestRefreshedEnvTokenIsNotSaved(t *testing.T) {
+	srv, _ := newClient(t)
+	keyring.MockInit()
+	if err := config.SaveConfig(srv.Config()); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOPSULE_TOKEN", apitest.Token)
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := api.NewClient(cfg)
+	srv.RevokeTokens()
+
+	if _, err := client.ListDecisions(apitest.ProjectID); err != nil {
+		t.Fatalf("request after the token was revoked: %v", err)
+	}
+
+	os.Unsetenv("HOPSULE_TOKEN")
+	saved, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Token != apitest.Token || saved.RefreshToken != apitest.RefreshToken {
+		t.Errorf("config file has token %q and refresh token %q, want the originals", saved.Token, saved.RefreshToken)
+	}
+}
+
+func TestGraphFallsBackToItemsWithoutEndpoints(t *testing.T) {
+	srv, client := newClient(t)
+	d := srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Go"})
+	m := srv.AddMemory(apitest.ProjectID, api.Memory{Content: "Builds are fast", RelatedDecisionIds: []string{d.ID}})
+	srv.Inject(apitest.Fault{Path: "/graph/", Unrouted: true})
+
+	neighbors, err := client.GetGraphNeighbors(apitest.ProjectID, d.ID, api.NeighborOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(neighbors.Neighbors) != 1 || neighbors.Neighbors[0].Node.ID != m.ID {
+		t.Errorf("neighbors = %+v, want %s", neighbors.Neighbors, m.ID)
+	}
+
+	paths, err := client.GetGraphPaths(apitest.ProjectID, m.ID, d.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || len(paths[0].Edges) != 1 {
+		t.Errorf("paths = %+v, want one direct edge", paths)
+	}
+
+	if _, err := client.GetGraphNeighbors(apitest.ProjectID, "nope", api.NeighborOptions{}); err == nil {
+		t.Error("unknown node: no error")
+	}
+	if client.HasGraphEndpoints() {
+		t.Error("the client still expects graph endpoints")
+	}
+	// The missing endpoints are remembered: only the first lookup asks
+	var graphRequests int
+	for _, r := range srv.Requests() {
+		if strings.Contains(r.Path, "/graph/") {
+			graphRequests++
+		}
+	}
+	if graphRequests != 1 {
+		t.Errorf("sent %d graph requests, want 1", graphRequests)
+	}
+}
+
+func TestGraphUnknownNodeIsReturned(t *testing.T) {
+	srv, client := newClient(t)
+	srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Go"})
+
+	_, err := client.GetGraphNeighbors(apitest.ProjectID, "nope", api.NeighborOptions{})
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("got %v, want the server's 404", err)
+	}
+	if _, err := client.GetGraphPaths(apitest.ProjectID, "nope", "other", 2); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("got %v, want the server's 404", err)
+	}
+	for _, r := range srv.Requests() {
+		if !strings.Contains(r.Path, "/graph/") {
+			t.Errorf("%s %s: an unknown node rebuilt the graph from the items", r.Method, r.Path)
+		}
+	}
+	if !client.HasGraphEndpoints() {
+		t.Error("an unknown node marked the graph endpoints missing")
+	}
+}
+
+func TestGraphErrorsOtherThan404AreReturned(t *testing.T) {
+	srv, client := newClient(t)
+	d := srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Go"})
+	srv.Inject(apitest.Fault{Path: "/graph/", Status: http.StatusInternalServerError})
+
+	if _, err := client.GetGraphNeighbors(apitest.ProjectID, d.ID, api.NeighborOptions{}); err == nil {
+		t.Error("a 500 from the graph service was hidden")
+	}
+}
@@ -0,0 +1,421 @@
+package api
+
+import "fmt"
+
+// Node types in the knowledge graph, the same vocabulary as TaggedItem.Type
+// plus code chunks
+const (
+	NodeDecision  = "decision"
+	NodeMemory    = "memory"
+	NodeTask      = "task"
+	NodeCapsule   = "capsule"
+	NodeCodeChunk = "code_chunk"
+)
+
+// Edge types, named <source type>_<target type> as in GraphStats.EdgesByType
+const (
+	EdgeMemoryDecision    = "memory_decision"
+	EdgeTaskDecision      = "task_decision"
+	EdgeTaskMemory        = "task_memory"
+	EdgeCapsuleDecision   = "capsule_decision"
+	EdgeCapsuleMemory     = "capsule_memory"
+	EdgeCodeChunkDecision = "code_chunk_decision"
+	EdgeSupersedes        = "supersedes" // from a decision to the one it replaces
+)
+
+// MaxGraphDepth is the deepest neighbor or path search the API allows
+const MaxGraphDepth = 5
+
+// GraphNode is an item in the knowledge graph
+type GraphNode struct {
+	ID     string   `json:"id"`
+	Type   string   `json:"type"`
+	Label  string   `json:"label"` // statement, content, title or name
+	Status string   `json:"status,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Path   string   `json:"path,omitempty"` // code chunks: file and lines
+}
+
+// GraphEdge links two nodes. Edges have a direction (e.g. a memory points
+// at the decisions it relates to) but are traversed both ways.
+type GraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Type   string `json:"type"`
+}
+
+// Other returns the end of the edge that isn't id
+func (e GraphEdge) Other(id string) string {
+	if e.Source == id {
+		return e.Target
+	}
+	return e.Source
+}
+
+// GraphNeighbor is a node reached from the start of a neighbor search
+type GraphNeighbor struct {
+	Node GraphNode `json:"node"`
+	// Edge is the edge the node was reached by; its other end is the start
+	// node or a neighbor of lower depth
+	Edge  GraphEdge `json:"edge"`
+	Depth int       `json:"depth"`
+}
+
+// GraphNeighbors is the result of a neighbor search
+type GraphNeighbors struct {
+	Node      GraphNode       `json:"node"`
+	Neighbors []GraphNeighbor `json:"neighbors"`
+}
+
+// NeighborOptions narrows a neighbor search
+type NeighborOptions struct {
+	// Depth is how many edges away neighbors may be (1 if 0, at most
+	// MaxGraphDepth)
+	Depth int
+	// NodeTypes limits the neighbors returned to these types; nodes of
+	// other types are still traversed
+	NodeTypes []string
+	// EdgeTypes limits the edges followed to these types
+	EdgeTypes []string
+}
+
+// GraphPath is a chain of edges between two nodes; Nodes has one more
+// entry than Edges
+type GraphPath struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphPathsResponse is the response from GET /graph/paths
+type GraphPathsResponse struct {
+	Paths []GraphPath `json:"paths"`
+}
+
+// maxGraphPaths caps the number of paths a search returns
+const maxGraphPaths = 20
+
+// Graph is a knowledge graph held in memory. Backends without a graph
+// service (the local store, the test server) build one from their items.
+// Nodes and edges keep the order they were added in, so searches are
+// deterministic.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+
+	index map[string]int   // node ID -> position in Nodes
+	adj   map[string][]int // node ID -> positions in Edges
+}
+
+// NewGraph builds the graph of a project's items: every item is a node and
+// every link between items an edge. Links to items that don't exist are
+// left out.
+func NewGraph(decisions []Decision, memories []*Memory, tasks []*Task, capsules []*Capsule) *Graph {
+	g := &Graph{index: map[string]int{}, adj: map[string][]int{}}
+	for _, d := range decisions {
+		g.AddNode(GraphNode{ID: d.ID, Type: NodeDecision, Label: d.Statement, Status: d.Status, Tags: d.Tags})
+	}
+	for _, m := range memories {
+		g.AddNode(GraphNode{ID: m.ID, Type: NodeMemory, Label: m.Content, Tags: m.Tags})
+	}
+	for _, t := range tasks {
+		g.AddNode(GraphNode{ID: t.ID, Type: NodeTask, Label: t.Title, Status: t.Status})
+	}
+	for _, c := range capsules {
+		g.AddNode(GraphNode{ID: c.ID, Type: NodeCapsule, Label: c.Name, Status: c.Status})
+	}
+
+	for _, d := range decisions {
+		if d.Supersedes != "" {
+			g.AddEdge(GraphEdge{Source: d.ID, Target: d.Supersedes, Type: EdgeSupersedes})
+		}
+	}
+	for _, m := range memories {
+		for _, id := range m.RelatedDecisionIds {
+			g.AddEdge(GraphEdge{Source: m.ID, Target: id, Type: EdgeMemoryDecision})
+		}
+	}
+	for _, t := range tasks {
+		for _, id := range t.RelatedDecisionIds {
+			g.AddEdge(GraphEdge{Source: t.ID, Target: id, Type: EdgeTaskDecision})
+		}
+		for _, id := range t.RelatedMemoryIds {
+			g.AddEdge(GraphEdge{Source: t.ID, Target: id, Type: EdgeTaskMemory})
+		}
+	}
+	for _, c := range capsules {
+		for _, id := range c.DecisionIds {
+			g.AddEdge(GraphEdge{Source: c.ID, Target: id, Type: EdgeCapsuleDecision})
+		}
+		for _, id := range c.MemoryIds {
+			g.AddEdge(GraphEdge{Source: c.ID, Target: id, Type: EdgeCapsuleMemory})
+		}
+	}
+	return g
+}
+
+// ProjectItems are the items a project's graph is built from
+type ProjectItems struct {
+	Decisions []Decision
+	Memories  []*Memory
+	Tasks     []*Task
+	Capsules  []*Capsule
+}
+
+// LoadItems fetches a project's decisions, memories, tasks and capsules
+func LoadItems(svc Service, projectID string) (*ProjectItems, error) {
+	decisions, err := svc.ListDecisions(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list decisions: %w", err)
+	}
+	memories, err := svc.ListMemories(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list memories: %w", err)
+	}
+	tasks, err := svc.ListTasks(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	capsules, err := svc.ListCapsules(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list capsules: %w", err)
+	}
+	return &ProjectItems{Decisions: decisions, Memories: memories, Tasks: tasks, Capsules: capsules}, nil
+}
+
+// LoadGraph builds a project's graph from its items
+func LoadGraph(svc Service, projectID string) (*Graph, error) {
+	items, err := LoadItems(svc, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return NewGraph(items.Decisions, items.Memories, items.Tasks, items.Capsules), nil
+}
+
+// Stats counts the graph's nodes and edges by type. The four item types
+// are always present, with zero counts when there are none.
+func (g *Graph) Stats() *GraphStats {
+	stats := &GraphStats{
+		NodeCount: len(g.Nodes),
+		EdgeCount: len(g.Edges),
+		NodesByType: map[string]int{
+			NodeDecision: 0,
+			NodeMemory:   0,
+			NodeTask:     0,
+			NodeCapsule:  0,
+		},
+		EdgesByType: map[string]int{},
+	}
+	for _, n := range g.Nodes {
+		stats.NodesByType[n.Type]++
+	}
+	for _, e := range g.Edges {
+		stats.EdgesByType[e.Type]++
+	}
+	return stats
+}
+
+// AddNode adds a node, replacing a node with the same ID
+func (g *Graph) AddNode(n GraphNode) {
+	if i, ok := g.index[n.ID]; ok {
+		g.Nodes[i] = n
+		return
+	}
+	g.index[n.ID] = len(g.Nodes)
+	g.Nodes = append(g.Nodes, n)
+}
+
+// AddEdge adds an edge between two nodes in the graph. It returns false,
+// adding nothing, if either end is missing.
+func (g *Graph) AddEdge(e GraphEdge) bool {
+	_, hasSource := g.index[e.Source]
+	_, hasTarget := g.index[e.Target]
+	if !hasSource || !hasTarget {
+		return false
+	}
+	g.adj[e.Source] = append(g.adj[e.Source], len(g.Edges))
+	if e.Target != e.Source {
+		g.adj[e.Target] = append(g.adj[e.Target], len(g.Edges))
+	}
+	g.Edges = append(g.Edges, e)
+	return true
+}
+
+// Filter returns the graph of the nodes keep accepts and the edges between
+// them
+func (g *Graph) Filter(keep func(GraphNode) bool) *Graph {
+	sub := &Graph{index: map[string]int{}, adj: map[string][]int{}}
+	for _, n := range g.Nodes {
+		if keep(n) {
+			sub.AddNode(n)
+		}
+	}
+	for _, e := range g.Edges {
+		sub.AddEdge(e)
+	}
+	return sub
+}
+
+// Node returns the node with an ID
+func (g *Graph) Node(id string) (GraphNode, bool) {
+	i, ok := g.index[id]
+	if !ok {
+		return GraphNode{}, false
+	}
+	return g.Nodes[i], true
+}
+
+// EdgesOf returns the edges touching a node
+func (g *Graph) EdgesOf(id string) []GraphEdge {
+	edges := make([]GraphEdge, 0, len(g.adj[id]))
+	for _, i := range g.adj[id] {
+		edges = append(edges, g.Edges[i])
+	}
+	return edges
+}
+
+// Neighbors finds the nodes up to opts.Depth edges from id, nearest first
+func (g *Graph) Neighbors(id string, opts NeighborOptions) (*GraphNeighbors, error) {
+	start, ok := g.Node(id)
+	if !ok {
+		return nil, fmt.Errorf("%s is not in the graph", id)
+	}
+	depth, err := graphDepth(opts.Depth)
+	if err != nil {
+		return nil, err
+	}
+	follow := stringSet(opts.EdgeTypes)
+	show := stringSet(opts.NodeTypes)
+
+	result := &GraphNeighbors{Node: start, Neighbors: []GraphNeighbor{}}
+	seen := map[string]bool{id: true}
+	frontier := []string{id}
+	for d := 1; d <= depth && len(frontier) > 0; d++ {
+		var next []string
+		for _, from := range frontier {
+			for _, e := range g.EdgesOf(from) {
+				if len(follow) > 0 && !follow[e.Type] {
+					continue
+				}
+				to := e.Other(from)
+				if seen[to] {
+					continue
+				}
+				seen[to] = true
+				next = append(next, to)
+				node, _ := g.Node(to)
+				if len(show) == 0 || show[node.Type] {
+					result.Neighbors = append(result.Neighbors, GraphNeighbor{Node: node, Edge: e, Depth: d})
+				}
+			}
+		}
+		frontier = next
+	}
+	return result, nil
+}
+
+// Paths finds the paths of at most maxDepth edges between two nodes that
+// visit no node twice, shortest first
+func (g *Graph) Paths(from, to string, maxDepth int) ([]GraphPath, error) {
+	for _, id := range []string{from, to} {
+		if _, ok := g.Node(id); !ok {
+			return nil, fmt.Errorf("%s is not in the graph", id)
+		}
+	}
+	depth, err := graphDepth(maxDepth)
+	if err != nil {
+		return nil, err
+	}
+
+	// Distances to the target bound the search: a walk only steps to nodes
+	// that can still reach the target within the length being searched
+	dist := g.distancesTo(to, depth)
+	if _, ok := dist[from]; !ok || from == to {
+		return nil, nil
+	}
+
+	// Search one length at a time so the shortest paths are found first
+	// and the search can stop once there are enough
+	var paths []GraphPath
+	onPath := map[string]bool{from: true}
+	var nodes []string
+	var edges []GraphEdge
+	var walk func(at string, length int)
+	walk = func(at string, length int) {
+		if len(paths) == maxGraphPaths {
+			return
+		}
+		if len(edges) == length {
+			if at == to {
+				path := GraphPath{Edges: append([]GraphEdge(nil), edges...)}
+				for _, id := range append([]string{from}, nodes...) {
+					node, _ := g.Node(id)
+					path.Nodes = append(path.Nodes, node)
+				}
+				paths = append(paths, path)
+			}
+			return
+		}
+		if at == to {
+			return
+		}
+		for _, e := range g.EdgesOf(at) {
+			next := e.Other(at)
+			d, ok := dist[next]
+			if !ok || onPath[next] || len(edges)+1+d > length {
+				continue
+			}
+			onPath[next] = true
+			nodes = append(nodes, next)
+			edges = append(edges, e)
+			walk(next, length)
+			onPath[next] = false
+			nodes = nodes[:len(nodes)-1]
+			edges = edges[:len(edges)-1]
+		}
+	}
+	for length := dist[from]; length <= depth; length++ {
+		walk(from, length)
+	}
+	return paths, nil
+}
+
+// distancesTo returns the number of edges from each node within depth edges
+// of id to id, found with a breadth-first search
+func (g *Graph) distancesTo(id string, depth int) map[string]int {
+	dist := map[string]int{id: 0}
+	queue := []string{id}
+	for len(queue) > 0 {
+		at := queue[0]
+		queue = queue[1:]
+		if dist[at] == depth {
+			continue
+		}
+		for _, e := range g.EdgesOf(at) {
+			next := e.Other(at)
+			if _, seen := dist[next]; !seen {
+				dist[next] = dist[at] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+	return dist
+}
+
+// graphDepth checks a requested depth, defaulting 0 to 1
+func graphDepth(depth int) (int, error) {
+	if depth == 0 {
+		return 1, nil
+	}
+	if depth < 0 || depth > MaxGraphDepth {
+		return 0, fmt.Errorf("depth must be between 1 and %d", MaxGraphDepth)
+	}
+	return depth, nil
+}
+
+func stringSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// testGraph is
+//
+//	d1 <-supersedes- d2
+//	d1 <- m1, m1 <- t1, d2 <- t1
+//	c1 -> d1, c1 -> m2 (m2 is unlinked otherwise)
+//
+// plus a link to a decision that doesn't exist
+func testGraph() *Graph {
+	return NewGraph(
+		[]Decision{
+			{ID: "d1", Statement: "Use Postgres", Status: "DEPRECATED"},
+			{ID: "d2", Statement: "Use SQLite", Status: "ACCEPTED", Supersedes: "d1"},
+		},
+		[]*Memory{
+			{ID: "m1", Content: "Postgres was slow", RelatedDecisionIds: []string{"d1", "missing"}},
+			{ID: "m2", Content: "Backups"},
+		},
+		[]*Task{
+			{ID: "t1", Title: "Migrate", RelatedDecisionIds: []string{"d2"}, RelatedMemoryIds: []string{"m1"}},
+		},
+		[]*Capsule{
+			{ID: "c1", Name: "Storage", DecisionIds: []string{"d1"}, MemoryIds: []string{"m2"}},
+		},
+	)
+}
+
+func neighborIDs(n *GraphNeighbors) map[string]int {
+	ids := map[string]int{}
+	for _, nb := range n.Neighbors {
+		ids[nb.Node.ID] = nb.Depth
+	}
+	return ids
+}
+
+func TestNewGraphSkipsMissingLinks(t *testing.T) {
+	g := testGraph()
+	if len(g.Nodes) != 6 {
+		t.Errorf("got %d nodes, want 6", len(g.Nodes))
+	}
+	if len(g.Edges) != 6 {
+		t.Errorf("got %d edges, want 6: %v", len(g.Edges), g.Edges)
+	}
+
+	stats := g.Stats()
+	want := map[string]int{NodeDecision: 2, NodeMemory: 2, NodeTask: 1, NodeCapsule: 1}
+	if !reflect.DeepEqual(stats.NodesByType, want) {
+		t.Errorf("NodesByType = %v, want %v", stats.NodesByType, want)
+	}
+	if stats.EdgesByType[EdgeSupersedes] != 1 || stats.EdgesByType[EdgeMemoryDecision] != 1 {
+		t.Errorf("EdgesByType = %v", stats.EdgesByType)
+	}
+}
+
+func TestStatsOfEmptyGraph(t *testing.T) {
+	stats := NewGraph(nil, nil, nil, nil).Stats()
+	if stats.NodeCount != 0 || len(stats.NodesByType) != 4 {
+		t.Errorf("stats = %+v, want zero counts for the four item types", stats)
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	g := testGraph()
+
+	tests := []struct {
+		name string
+		id   string
+		opts NeighborOptions
+		want map[string]int
+	}{
+		{"default depth", "d1", NeighborOptions{}, map[string]int{"d2": 1, "m1": 1, "c1": 1}},
+		{"depth 2", "d1", NeighborOptions{Depth: 2}, map[string]int{"d2": 1, "m1": 1, "c1": 1, "t1": 2, "m2": 2}},
+		{"node types", "d1", NeighborOptions{Depth: 2, NodeTypes: []string{NodeMemory}}, map[string]int{"m1": 1, "m2": 2}},
+		{"edge types", "d1", NeighborOptions{Depth: 3, EdgeTypes: []string{EdgeSupersedes, EdgeTaskDecision}}, map[string]int{"d2": 1, "t1": 2}},
+		{"unlinked node", "m2", NeighborOptions{}, map[string]int{"c1": 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Neighbors(tt.id, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Node.ID != tt.id {
+				t.Errorf("start node = %s, want %s", got.Node.ID, tt.id)
+			}
+			if ids := neighborIDs(got); !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("neighbors = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestNeighborsEdgeLeadsBack(t *testing.T) {
+	got, err := testGraph().Neighbors("d1", NeighborOptions{Depth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, nb := range got.Neighbors {
+		if nb.Edge.Source != nb.Node.ID && nb.Edge.Target != nb.Node.ID {
+			t.Errorf("%s was reached by %v, which doesn't touch it", nb.Node.ID, nb.Edge)
+		}
+	}
+}
+
+func TestNeighborsErrors(t *testing.T) {
+	g := testGraph()
+	if _, err := g.Neighbors("nope", NeighborOptions{}); err == nil {
+		t.Error("unknown node: no error")
+	}
+	for _, depth := range []int{-1, MaxGraphDepth + 1} {
+		if _, err := g.Neighbors("d1", NeighborOptions{Depth: depth}); err == nil {
+			t.Errorf("depth %d: no error", depth)
+		}
+	}
+}
+
+func TestPaths(t *testing.T) {
+	g := testGraph()
+
+	paths, err := g.Paths("m1", "d2", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got [][]string
+	for _, p := range paths {
+		if len(p.Nodes) != len(p.Edges)+1 {
+			t.Fatalf("path has %d nodes and %d edges", len(p.Nodes), len(p.Edges))
+		}
+		var ids []string
+		for _, n := range p.Nodes {
+			ids = append(ids, n.ID)
+		}
+		got = append(got, ids)
+	}
+	want := [][]string{
+		{"m1", "d1", "d2"},
+		{"m1", "t1", "d2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paths = %v, want %v", got, want)
+	}
+
+	// The shortest path is 3 edges: m2 - c1 - d1 - d2
+	if paths, _ := g.Paths("m2", "d2", 2); len(paths) != 0 {
+		t.Errorf("depth 2 found %d paths, want none", len(paths))
+	}
+	if paths, _ := g.Paths("m2", "d2", 3); len(paths) != 1 || len(paths[0].Edges) != 3 {
+		t.Errorf("depth 3 found %v, want one path of 3 edges", paths)
+	}
+
+	if paths, _ := g.Paths("d1", "d1", 3); len(paths) != 0 {
+		t.Errorf("path from a node to itself: %v", paths)
+	}
+	if _, err := g.Paths("d1", "nope", 3); err == nil {
+		t.Error("unknown node: no error")
+	}
+}
+
+func TestPathsAroundHubs(t *testing.T) {
+	// Every memory is linked to every decision, and the task hangs off one
+	// memory; enumerating every walk of up to 5 edges would take hours
+	var decisions []Decision
+	var ids []string
+	for i := range 60 {
+		id := fmt.Sprintf("d%d", i)
+		decisions = append(decisions, Decision{ID: id})
+		ids = append(ids, id)
+	}
+	var memories []*Memory
+	for i := range 60 {
+		memories = append(memories, &Memory{ID: fmt.Sprintf("m%d", i), RelatedDecisionIds: ids})
+	}
+	tasks := []*Task{{ID: "t1", RelatedMemoryIds: []string{"m0"}}, {ID: "t2"}}
+	g := NewGraph(decisions, memories, tasks, nil)
+
+	paths, err := g.Paths("d1", "t1", MaxGraphDepth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != maxGraphPaths || len(paths[0].Edges) != 2 {
+		t.Errorf("found %d paths, the first of %d edges; want %d, the first of 2", len(paths), len(paths[0].Edges), maxGraphPaths)
+	}
+	if paths, _ := g.Paths("d1", "t2", MaxGraphDepth); len(paths) != 0 {
+		t.Errorf("found %d paths to an unlinked task", len(paths))
+	}
+}
+
+func TestFilter(t *testing.T) {
+	sub := testGraph().Filter(func(n GraphNode) bool { return n.Type != NodeTask })
+	if _, ok := sub.Node("t1"); ok {
+		t.Error("filtered graph still has t1")
+	}
+	for _, e := range sub.Edges {
+		if e.Source == "t1" || e.Target == "t1" {
+			t.Errorf("filtered graph kept edge %v", e)
+		}
+	}
+	if len(sub.Edges) != 4 {
+		t.Errorf("got %d edges, want 4", len(sub.Edges))
+	}
+	if len(sub.EdgesOf("d1")) != 3 {
+		t.Errorf("d1 has edges %v, want 3", sub.EdgesOf("d1"))
+	}
+}
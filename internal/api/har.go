@@ -0,0 +1,416 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// API traffic can be recorded to a HAR 1.2 file (--record) and served back
+// from one without a network (--replay), so a bug report can carry an exact
+// reproduction. Tokens are scrubbed before anything is written.
+
+var (
+	harMu       sync.Mutex
+	harRecorder *recorder
+	harReplayer *replayer
+)
+
+// RecordTo records every API request and response made by this process to
+// a HAR file at path. The file is rewritten after each request, so it is
+// complete even if the command fails.
+func RecordTo(path string) error {
+	harMu.Lock()
+	defer harMu.Unlock()
+
+	if harReplayer != nil {
+		return fmt.Errorf("cannot record and replay at the same time")
+	}
+	r := &recorder{path: path}
+	if err := r.write(); err != nil {
+		return err
+	}
+	harRecorder = r
+	return nil
+}
+
+// ReplayFrom serves API responses from the HAR file at path instead of the
+// network. Requests are matched by method, path and query in the order they
+// were recorded; a request with no recorded response fails.
+func ReplayFrom(path string) error {
+	harMu.Lock()
+	defer harMu.Unlock()
+
+	if harRecorder != nil {
+		return fmt.Errorf("cannot record and replay at the same time")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read replay file: %w", err)
+	}
+	var file harFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse replay file %s: %w", path, err)
+	}
+
+	r := &replayer{path: path, entries: map[string][]harEntry{}}
+	for _, entry := range file.Log.Entries {
+		key, err := replayKey(entry.Request.Method, entry.Request.URL)
+		if err != nil {
+			return fmt.Errorf("invalid entry in replay file %s: %w", path, err)
+		}
+		r.entries[key] = append(r.entries[key], entry)
+	}
+	harReplayer = r
+	return nil
+}
+
+// harTransport returns the transport to use in place of next when recording
+// or replaying, or next itself
+func harTransport(next http.RoundTripper) http.RoundTripper {
+	harMu.Lock()
+	defer harMu.Unlock()
+
+	switch {
+	case harReplayer != nil:
+		return harReplayer
+	case harRecorder != nil:
+		return &recordingTransport{next: next, recorder: harRecorder}
+	default:
+		return next
+	}
+}
+
+// replaying reports whether responses come from a replay file
+func replaying() bool {
+	harMu.Lock()
+	defer harMu.Unlock()
+	return harReplayer != nil
+}
+
+// HAR 1.2 structures, limited to the fields the CLI writes and reads
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// recorder collects entries and writes them to its HAR file
+type recorder struct {
+	mu      sync.Mutex
+	path    string
+	entries []harEntry
+}
+
+func (r *recorder) add(entry harEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return r.write()
+}
+
+func (r *recorder) write() error {
+	file := harFile{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "hopsule", Version: "1"},
+		Entries: r.entries,
+	}}
+	if file.Log.Entries == nil {
+		file.Log.Entries = []harEntry{}
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode recording: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("failed to write recording: %w", err)
+	}
+	return nil
+}
+
+// recordingTransport passes requests on to next and records them. The
+// response is recorded once its body has been read, so streamed responses
+// are captured whole.
+type recordingTransport struct {
+	next     http.RoundTripper
+	recorder *recorder
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil && req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = io.ReadAll(body)
+			body.Close()
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	wait := time.Since(start)
+
+	entry := harEntry{
+		StartedDateTime: start.UTC().Format(time.RFC3339Nano),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.Redacted(),
+			HTTPVersion: req.Proto,
+			Headers:     harHeaders(req.Header),
+			QueryString: harQuery(req),
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Headers:     harHeaders(resp.Header),
+			Cookies:     []harNameValue{},
+			RedirectURL: resp.Header.Get("Location"),
+			HeadersSize: -1,
+		},
+	}
+	if reqBody != nil {
+		entry.Request.PostData = &harPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     scrubBody(reqBody),
+		}
+	}
+
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		done: func(body []byte) {
+			receive := time.Since(start) - wait
+			entry.Time = float64((wait + receive).Milliseconds())
+			entry.Timings = harTimings{Wait: float64(wait.Milliseconds()), Receive: float64(receive.Milliseconds())}
+			entry.Response.BodySize = len(body)
+			entry.Response.Content = harContentFor(resp.Header.Get("Content-Type"), body)
+			if err := t.recorder.add(entry); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		},
+	}
+	return resp, nil
+}
+
+// recordingBody keeps a copy of everything read from the body and hands it
+// to done at EOF or Close, whichever comes first
+type recordingBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	done func([]byte)
+	once sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *recordingBody) finish() {
+	b.once.Do(func() { b.done(b.buf.Bytes()) })
+}
+
+// replayer answers requests from recorded entries
+type replayer struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string][]harEntry
+}
+
+func (r *replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	key, err := replayKey(req.Method, req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	queue := r.entries[key]
+	if len(queue) == 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("no recorded response for %s in %s", key, r.path)
+	}
+	// Responses are served in recorded order; the last one repeats
+	entry := queue[0]
+	if len(queue) > 1 {
+		r.entries[key] = queue[1:]
+	}
+	r.mu.Unlock()
+
+	body := []byte(entry.Response.Content.Text)
+	if entry.Response.Content.Encoding == "base64" {
+		if body, err = base64.StdEncoding.DecodeString(entry.Response.Content.Text); err != nil {
+			return nil, fmt.Errorf("invalid recorded body for %s in %s: %w", key, r.path, err)
+		}
+	}
+
+	header := http.Header{}
+	for _, h := range entry.Response.Headers {
+		header.Add(h.Name, h.Value)
+	}
+	header.Del("Content-Length")
+	header.Del("Content-Encoding")
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", entry.Response.Status, entry.Response.StatusText),
+		StatusCode:    entry.Response.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// replayKey identifies a request by method, path and query, so a recording
+// can be replayed against any API URL
+func replayKey(method, rawURL string) (string, error) {
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	return method + " " + req.URL.RequestURI(), nil
+}
+
+func harHeaders(header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for name, value := range redactHeaders(header) {
+		headers = append(headers, harNameValue{Name: name, Value: value})
+	}
+	sortNameValues(headers)
+	return headers
+}
+
+func harQuery(req *http.Request) []harNameValue {
+	query := []harNameValue{}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			query = append(query, harNameValue{Name: name, Value: value})
+		}
+	}
+	sortNameValues(query)
+	return query
+}
+
+// sortNameValues keeps recordings stable, so they diff cleanly
+func sortNameValues(values []harNameValue) {
+	sort.SliceStable(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+}
+
+func harContentFor(mimeType string, body []byte) harContent {
+	content := harContent{Size: len(body), MimeType: mimeType}
+	if utf8.Valid(body) {
+		content.Text = scrubBody(body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	}
+	return content
+}
+
+// scrubBody redacts secret fields from a JSON body. Other bodies are kept
+// as they are.
+func scrubBody(data []byte) string {
+	var value any
+	if json.Unmarshal(data, &value) != nil {
+		return string(data)
+	}
+	redactJSON(value)
+	out, err := json.Marshal(value)
+	if err != nil {
+		return string(data)
+	}
+	return string(out)
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Cagangedik/cli-tool/internal/config"
+)
+
+// Service is the project data the CLI and TUI work with. Client implements
+// it over HTTP; other backends register themselves with RegisterBackend and
+// are selected with the backend setting.
+//
+// Sign-in, sessions and service tokens only exist on the server, so they
+// stay on Client.
+type Service interface {
+	// Decisions
+	ListDecisions(projectID string) ([]Decision, error)
+	GetDecision(projectID, decisionID string) (*Decision, error)
+	CreateDecision(projectID string, req CreateDecisionRequest) (*Decision, error)
+	AcceptDecision(projectID, decisionID string) (*Decision, error)
+	DeprecateDecision(projectID, decisionID string) (*Decision, error)
+	GetProjectStatus(projectID string) (*ProjectStatus, error)
+
+	// Memories
+	ListMemories(projectID string) ([]*Memory, error)
+	CreateMemory(projectID string, req CreateMemoryRequest) (*Memory, error)
+	UpdateMemory(projectID, memoryID string, req UpdateMemoryRequest) (*Memory, error)
+	DeleteMemory(projectID, memoryID string) error
+
+	// Tasks
+	ListTasks(projectID string) ([]*Task, error)
+	CreateTask(projectID string, req CreateTaskRequest) (*Task, error)
+	UpdateTask(projectID, taskID string, req UpdateTaskRequest) (*Task, error)
+	DeleteTask(projectID, taskID string) error
+
+	// Capsules and graph
+	ListCapsules(projectID string) ([]*Capsule, error)
+	GetGraphStats(projectID string) (*GraphStats, error)
+	GetGraphNeighbors(projectID, nodeID string, opts NeighborOptions) (*GraphNeighbors, error)
+	GetGraphPaths(projectID, fromID, toID string, maxDepth int) ([]GraphPath, error)
+
+	// Identity
+	GetMe() (*MeResponse, error)
+	ListOrganizations() ([]*Organization, error)
+	ListProjects() ([]*Project, error)
+
+	// Hopper chat; onChunk is called with each part of the reply
+	SendChatMessage(projectID string, req *ChatRequest, onChunk func(string)) error
+}
+
+var _ Service = (*Client)(nil)
+
+// BackendFactory creates a Service from the config for the project in dir
+type BackendFactory func(cfg *config.Config, dir string) (Service, error)
+
+var (
+	backendsMu sync.RWMutex
+	backends   = map[string]BackendFactory{
+		config.BackendHTTP: func(cfg *config.Config, dir string) (Service, error) {
+			return NewClient(cfg), nil
+		},
+	}
+)
+
+// RegisterBackend makes a backend available to NewService under name
+func RegisterBackend(name string, factory BackendFactory) {
+	backendsMu.Lock()
+	defer backendsMu.Unlock()
+	backends[name] = factory
+}
+
+// NewService returns the Service for the backend selected for the current
+// directory (see config.ResolveBackend)
+func NewService(cfg *config.Config) (Service, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return NewServiceFor(cfg, dir)
+}
+
+// NewServiceFor returns the Service for the backend selected for dir, e.g.
+// a workspace member that doesn't share the backend of the current
+// directory
+func NewServiceFor(cfg *config.Config, dir string) (Service, error) {
+	name := config.ResolveBackendFor(cfg, dir)
+	backendsMu.RLock()
+	factory, ok := backends[name]
+	backendsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown backend %q (available: %s)", name, strings.Join(registeredBackends(), ", "))
+	}
+	return factory(cfg, dir)
+}
+
+func registeredBackends() []string {
+	backendsMu.RLock()
+	defer backendsMu.RUnlock()
+	var names []string
+	for name := range backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/Cagangedik/cli-tool/internal/logging"
+)
+
+// ErrSessionExpired is returned when the token has expired and can't be
+// refreshed
+var ErrSessionExpired = errors.New("session expired, run 'hopsule login' to sign in again")
+
+// refreshMargin renews tokens slightly before they expire, so a request
+// doesn't race the expiry
+const refreshMargin = time.Minute
+
+// refreshMu serializes refreshes; clients copied with WithToken/WithBaseURL
+// share the same session
+var refreshMu sync.Mutex
+
+// TokenResponse is returned by POST /auth/refresh
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresAt    string `json:"expires_at,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"` // seconds
+}
+
+// Expiry returns the RFC 3339 expiry of the token, or "" if unknown
+func (r *TokenResponse) Expiry() string {
+	return tokenExpiry(r.ExpiresAt, r.ExpiresIn)
+}
+
+// authToken is the token to send: the session's, which may have been
+// refreshed, or the one the client was built with
+func (c *Client) authToken() string {
+	if c.session != nil {
+		return c.session.Token
+	}
+	return c.token
+}
+
+func (c *Client) canRefresh() bool {
+	return c.session != nil && c.session.RefreshToken != ""
+}
+
+// ensureFreshToken refreshes the session token when it is about to expire,
+// and fails early with ErrSessionExpired when it has expired for good
+func (c *Client) ensureFreshToken() error {
+	if c.session == nil || c.session.Token == "" {
+		return nil
+	}
+
+	expiry := c.session.TokenExpiry()
+	if expiry.IsZero() || time.Until(expiry) > refreshMargin {
+		return nil
+	}
+	if !c.canRefresh() {
+		if c.session.TokenExpired() {
+			return ErrSessionExpired
+		}
+		return nil
+	}
+	return c.refreshSession(c.session.Token)
+}
+
+// refreshSession exchanges the refresh token for a new token and saves it.
+// staleToken is the token that needed replacing; if the session has moved
+// on since, another request already refreshed it.
+func (c *Client) refreshSession(staleToken string) error {
+	refreshMu.Lock()
+	defer refreshMu.Unlock()
+
+	session := c.session
+	if session.Token != staleToken {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]string{"refresh_token": session.RefreshToken})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	resp, err := c.send("POST", "/auth/refresh", body, "", "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w (refresh failed: %d - %s)", ErrSessionExpired, resp.StatusCode, string(bodyBytes))
+	}
+
+	var result TokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.Token == "" {
+		return fmt.Errorf("%w (refresh returned no token)", ErrSessionExpired)
+	}
+
+	session.Token = result.Token
+	if result.RefreshToken != "" {
+		session.RefreshToken = result.RefreshToken
+	}
+	session.TokenExpiresAt = result.Expiry()
+
+	// A token from HOPSULE_TOKEN or --token isn't the config file's to
+	// replace; the new token is only used for this run
+	if origin := config.Default().Origin(session, "token"); origin == config.LayerEnv || origin == config.LayerFlag {
+		return nil
+	}
+	if err := config.SaveConfig(session); err != nil {
+		// The new token still works for this run
+		logging.Logger().Warn("could not save refreshed token", slog.String("error", err.Error()))
+	}
+	return nil
+}
+
+// tokenExpiry normalizes an absolute or relative expiry to RFC 3339
+func tokenExpiry(expiresAt string, expiresIn int) string {
+	if expiresAt != "" {
+		if t, err := time.Parse(time.RFC3339, expiresAt); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	if expiresIn > 0 {
+		return time.Now().Add(time.Duration(expiresIn) * time.Second).UTC().Format(time.RFC3339)
+	}
+	return ""
+}
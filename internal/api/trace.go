@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/logging"
+)
+
+// maxLoggedBody is how much of a request or response body is logged
+const maxLoggedBody = 2048
+
+const redacted = "[REDACTED]"
+
+// redactedHeaders are logged with their values replaced
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// redactedFields are JSON body fields logged with their values replaced
+var redactedFields = map[string]bool{
+	"token":         true,
+	"access_token":  true,
+	"accessToken":   true,
+	"refresh_token": true,
+	"refreshToken":  true,
+	"password":      true,
+	"secret":        true,
+}
+
+// tracingTransport logs every request made through it. Each request gets an
+// X-Request-ID so log lines can be matched with server logs.
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	log := logging.Logger()
+	debug := logging.Enabled(logging.LevelDebug)
+
+	requestID := req.Header.Get("X-Request-ID")
+	if requestID == "" {
+		requestID = newRequestID()
+		req = req.Clone(req.Context())
+		req.Header.Set("X-Request-ID", requestID)
+	}
+
+	attrs := []any{
+		slog.String("request_id", requestID),
+		slog.String("method", req.Method),
+		slog.String("url", req.URL.Redacted()),
+	}
+	if debug {
+		log.Debug("http request", append(attrs,
+			slog.Any("headers", redactHeaders(req.Header)),
+			slog.String("body", requestBody(req)),
+		)...)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+	if err != nil {
+		log.Info("http error", append(attrs, slog.String("error", err.Error()))...)
+		return nil, err
+	}
+
+	attrs = append(attrs, slog.Int("status", resp.StatusCode))
+	if debug {
+		attrs = append(attrs,
+			slog.Any("headers", redactHeaders(resp.Header)),
+			slog.String("body", responseBody(resp)),
+		)
+	}
+	log.Info("http response", attrs...)
+	return resp, nil
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func redactHeaders(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for name, values := range header {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			out[name] = redacted
+			continue
+		}
+		out[name] = strings.Join(values, ", ")
+	}
+	return out
+}
+
+// requestBody returns the logged form of req's body without consuming it
+func requestBody(req *http.Request) string {
+	if req.Body == nil || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(io.LimitReader(body, maxLoggedBody+1))
+	return formatBody(data)
+}
+
+// responseBody returns the logged form of resp's body, putting back what it
+// read. Streams are skipped so logging doesn't hold up their first event.
+func responseBody(resp *http.Response) string {
+	if resp.Body == nil || strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return ""
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBody+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	return formatBody(data)
+}
+
+// formatBody redacts secret fields from a JSON body and truncates it
+func formatBody(data []byte) string {
+	truncated := len(data) > maxLoggedBody
+	if truncated {
+		data = data[:maxLoggedBody]
+	}
+
+	var value any
+	if !truncated && json.Unmarshal(data, &value) == nil {
+		redactJSON(value)
+		if out, err := json.Marshal(value); err == nil {
+			return string(out)
+		}
+	}
+
+	// Truncated or not JSON: secrets can't be found reliably, so only log
+	// bodies that don't mention any
+	text := string(data)
+	for field := range redactedFields {
+		if strings.Contains(text, `"`+field+`"`) {
+			return redacted
+		}
+	}
+	if truncated {
+		text += "…(truncated)"
+	}
+	return text
+}
+
+func redactJSON(value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if redactedFields[key] {
+				v[key] = redacted
+				continue
+			}
+			redactJSON(field)
+		}
+	case []any:
+		for _, item := range v {
+			redactJSON(item)
+		}
+	}
+}
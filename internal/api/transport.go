@@ -0,0 +1,140 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/Cagangedik/cli-tool/internal/logging"
+)
+
+// DefaultTimeout applies to API requests when no timeout is configured
+const DefaultTimeout = 30 * time.Second
+
+// NewHTTPClient builds an HTTP client from cfg's network settings: proxy,
+// extra CA bundles, client certificate, TLS verification, timeout and Unix
+// socket
+func NewHTTPClient(cfg *config.Config) (*http.Client, error) {
+	timeout, err := RequestTimeout(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// A replay never touches the network, so its settings don't matter
+	if replaying() {
+		return newClientWith(harTransport(nil), timeout), nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy, err := ProxyFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	tlsConfig, err := tlsConfigFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if cfg.UnixSocket != "" {
+		socket := cfg.UnixSocket
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+	}
+
+	return newClientWith(harTransport(transport), timeout), nil
+}
+
+// newClientWith wraps transport with request logging when it is enabled
+func newClientWith(transport http.RoundTripper, timeout time.Duration) *http.Client {
+	if logging.Enabled(logging.LevelVerbose) {
+		transport = &tracingTransport{next: transport}
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}
+}
+
+// RequestTimeout returns the configured request timeout, or DefaultTimeout
+func RequestTimeout(cfg *config.Config) (time.Duration, error) {
+	if cfg.Timeout == "" {
+		return DefaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(cfg.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must be a duration such as 30s or 2m", cfg.Timeout)
+	}
+	return timeout, nil
+}
+
+// ProxyFunc returns the proxy selection for cfg: the configured proxy, no
+// proxy for "direct" or a Unix socket, and HTTP(S)_PROXY otherwise
+func ProxyFunc(cfg *config.Config) (func(*http.Request) (*url.URL, error), error) {
+	switch {
+	case cfg.UnixSocket != "", cfg.Proxy == config.ProxyDirect:
+		return nil, nil
+	case cfg.Proxy != "":
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy %q", cfg.Proxy)
+		}
+		return http.ProxyURL(proxyURL), nil
+	default:
+		return http.ProxyFromEnvironment, nil
+	}
+}
+
+func tlsConfigFor(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Only meant for local development against self-signed servers
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if files := cfg.CAFiles(); len(files) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, path := range files {
+			pem, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA file: %w", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("no PEM certificates found in CA file %s", path)
+			}
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" {
+		// The key may be in the same PEM file as the certificate
+		keyFile := cfg.ClientKey
+		if keyFile == "" {
+			keyFile = cfg.ClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	} else if cfg.ClientKey != "" {
+		return nil, fmt.Errorf("client_key is set but client_cert is not")
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,744 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+)
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	handle := func(pattern string, h http.HandlerFunc) {
+		mux.HandleFunc(pattern, s.serve(h, false))
+	}
+	public := func(pattern string, h http.HandlerFunc) {
+		mux.HandleFunc(pattern, s.serve(h, true))
+	}
+
+	// Auth
+	public("POST /auth/device/init", s.deviceInit)
+	public("GET /auth/device/{code}/poll", s.devicePoll)
+	public("POST /auth/refresh", s.refresh)
+	handle("POST /auth/logout", s.logout)
+	handle("GET /auth/sessions", s.listSessions)
+	handle("DELETE /auth/sessions/{id}", s.revokeSession)
+	handle("GET /auth/tokens", s.listAPITokens)
+	handle("POST /auth/tokens", s.createAPIToken)
+	handle("DELETE /auth/tokens/{id}", s.revokeAPIToken)
+
+	// Account
+	handle("GET /me", s.me)
+	handle("GET /organizations", s.listOrganizations)
+	handle("GET /projects", s.listProjects)
+	handle("GET /api/v1/projects/{id}/status", s.projectStatus)
+
+	// Decisions
+	handle("GET /decisions", s.listDecisions)
+	handle("GET /decisions/{id}", s.getDecision)
+	handle("POST /decisions/draft", s.createDecision)
+	handle("POST /decisions/accept", s.setDecisionStatus("ACCEPTED", "DRAFT", "PENDING"))
+	handle("POST /decisions/deprecate", s.setDecisionStatus("DEPRECATED", "ACCEPTED"))
+
+	// Memories, tasks, capsules and graph
+	handle("GET /memories", s.listMemories)
+	handle("POST /memories", s.createMemory)
+	handle("PATCH /memories/{id}", s.updateMemory)
+	handle("DELETE /memories/{id}", s.deleteMemory)
+	handle("GET /tasks", s.listTasks)
+	handle("POST /tasks", s.createTask)
+	handle("PUT /tasks/{id}", s.updateTask)
+	handle("DELETE /tasks/{id}", s.deleteTask)
+	handle("GET /capsules", s.listCapsules)
+	handle("GET /graph/stats", s.graphStats)
+	handle("GET /graph/nodes/{id}/neighbors", s.graphNeighbors)
+	handle("GET /graph/paths", s.graphPaths)
+
+	// Hopper
+	handle("POST /ai/hopper/chat", s.chat)
+
+	return mux
+}
+
+// ============================================================================
+// AUTH
+// ============================================================================
+
+func (s *Server) deviceInit(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	code := strings.ToUpper(s.newID("code"))
+	s.deviceCodes[code] = s.devicePending
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, api.DeviceAuthInitResponse{Code: code, ExpiresIn: 600})
+}
+
+func (s *Server) devicePoll(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	code := r.PathValue("code")
+	pending, ok := s.deviceCodes[code]
+	switch {
+	case !ok:
+		writeError(w, http.StatusNotFound, "unknown code")
+	case pending > 0:
+		s.deviceCodes[code] = pending - 1
+		writeJSON(w, http.StatusOK, api.DeviceAuthPollResponse{Status: "pending"})
+	default:
+		delete(s.deviceCodes, code)
+		writeJSON(w, http.StatusOK, api.DeviceAuthPollResponse{
+			Status:         "complete",
+			UserID:         s.user.ID,
+			Email:          s.user.Email,
+			Name:           s.user.Name,
+			Token:          s.issueToken(),
+			RefreshToken:   s.refreshToken,
+			TokenExpiresIn: 3600,
+		})
+	}
+}
+
+func (s *Server) refresh(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.RefreshToken == "" || req.RefreshToken != s.refreshToken {
+		writeError(w, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+	s.refreshToken = fmt.Sprintf("%s-%d", RefreshToken, s.issued+1)
+	writeJSON(w, http.StatusOK, api.TokenResponse{
+		Token:        s.issueToken(),
+		RefreshToken: s.refreshToken,
+		ExpiresIn:    3600,
+	})
+}
+
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listSessions(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, api.ListSessionsResponse{Sessions: append([]api.Session{}, s.sessions...)})
+}
+
+func (s *Server) revokeSession(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, session := range s.sessions {
+		if session.ID == r.PathValue("id") {
+			s.sessions = append(s.sessions[:i], s.sessions[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "session not found")
+}
+
+func (s *Server) listAPITokens(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tokens := []api.APIToken{}
+	for _, token := range s.apiTokens {
+		if token.ProjectID == projectID {
+			tokens = append(tokens, token)
+		}
+	}
+	writeJSON(w, http.StatusOK, api.ListAPITokensResponse{Tokens: tokens})
+}
+
+func (s *Server) createAPIToken(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+	var req api.CreateAPITokenRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Name == "" || len(req.Scopes) == 0 {
+		writeError(w, http.StatusBadRequest, "name and scopes are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret := api.ServiceTokenPrefix + s.newID("secret")
+	token := api.APIToken{
+		ID:        s.newID("tok"),
+		Name:      req.Name,
+		Prefix:    secret[:len(api.ServiceTokenPrefix)+4],
+		ProjectID: projectID,
+		Scopes:    req.Scopes,
+		CreatedBy: s.user.ID,
+		CreatedAt: timestamp(),
+	}
+	s.apiTokens = append(s.apiTokens, token)
+	s.tokens[secret] = true
+
+	token.Token = secret
+	writeJSON(w, http.StatusCreated, token)
+}
+
+func (s *Server) revokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, token := range s.apiTokens {
+		if token.ID == r.PathValue("id") {
+			s.apiTokens = append(s.apiTokens[:i], s.apiTokens[i+1:]...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "token not found")
+}
+
+// ============================================================================
+// ACCOUNT
+// ============================================================================
+
+func (s *Server) me(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := s.user
+	writeJSON(w, http.StatusOK, api.MeResponse{
+		User:          &user,
+		Organizations: s.orgs,
+		Projects:      s.projects,
+		TokenType:     api.TokenTypeUser,
+	})
+}
+
+func (s *Server) listOrganizations(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.orgs)
+}
+
+func (s *Server) listProjects(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.projects)
+}
+
+func (s *Server) projectStatus(w http.ResponseWriter, r *http.Request) {
+	projectID := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := api.ProjectStatus{ProjectID: projectID, TotalDecisions: len(s.decisions[projectID])}
+	for _, d := range s.decisions[projectID] {
+		switch d.Status {
+		case "ACCEPTED":
+			status.Accepted++
+		case "PENDING":
+			status.Pending++
+		case "DRAFT":
+			status.Draft++
+		case "DEPRECATED":
+			status.Deprecated++
+		}
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// ============================================================================
+// DECISIONS
+// ============================================================================
+
+func (s *Server) listDecisions(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	decisions := copyAll(s.decisions[projectID])
+	writeJSON(w, http.StatusOK, api.ListDecisionsResponse{Decisions: decisions, Total: len(decisions)})
+}
+
+func (s *Server) getDecision(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d := find(s.decisions[projectID], r.PathValue("id"), decisionID); d != nil {
+		writeJSON(w, http.StatusOK, d)
+		return
+	}
+	writeError(w, http.StatusNotFound, "decision not found")
+}
+
+func (s *Server) createDecision(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+	var req api.CreateDecisionRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Statement == "" {
+		writeError(w, http.StatusBadRequest, "statement is required")
+		return
+	}
+
+	d := api.Decision{Statement: req.Statement, Rationale: req.Rationale, Tags: req.Tags}
+	if req.ScopeKey != nil {
+		d.ScopeKey = *req.ScopeKey
+	}
+	writeJSON(w, http.StatusCreated, s.AddDecision(projectID, d))
+}
+
+// setDecisionStatus moves a decision to status, which is only allowed from
+// one of the from statuses
+func (s *Server) setDecisionStatus(status string, from ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		projectID, ok := requireProject(w, r)
+		if !ok {
+			return
+		}
+		var req api.AcceptDecisionRequest
+		if !decode(w, r, &req) {
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		d := find(s.decisions[projectID], req.ID, decisionID)
+		if d == nil {
+			writeError(w, http.StatusNotFound, "decision not found")
+			return
+		}
+		if !slices.Contains(from, d.Status) {
+			writeError(w, http.StatusConflict, fmt.Sprintf("decision %s is %s; only %s decisions can be %s",
+				d.ID, d.Status, strings.Join(from, " or "), strings.ToLower(status)))
+			return
+		}
+		d.Status = status
+		d.UpdatedAt = timestamp()
+		if status == "ACCEPTED" {
+			acceptedAt, acceptedBy := d.UpdatedAt, s.user.ID
+			d.AcceptedAt, d.AcceptedBy = &acceptedAt, &acceptedBy
+		}
+		writeJSON(w, http.StatusOK, d)
+	}
+}
+
+// ============================================================================
+// MEMORIES, TASKS, CAPSULES & GRAPH
+// ============================================================================
+
+func (s *Server) listMemories(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	memories := s.memories[projectID]
+	if memories == nil {
+		memories = []*api.Memory{}
+	}
+	writeJSON(w, http.StatusOK, api.ListMemoriesResponse{Memories: memories, Total: len(memories)})
+}
+
+func (s *Server) createMemory(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+	var req api.CreateMemoryRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Content == "" {
+		writeError(w, http.StatusBadRequest, "content is required")
+		return
+	}
+
+	s.mu.Lock()
+	user := s.user
+	s.mu.Unlock()
+	memory := s.AddMemory(projectID, api.Memory{
+		Content:            req.Content,
+		Tags:               req.Tags,
+		RelatedDecisionIds: req.RelatedDecisionIds,
+		CreatedByUserID:    user.ID,
+		CreatedByName:      user.Name,
+	})
+	writeJSON(w, http.StatusCreated, map[string]any{"memory": memory})
+}
+
+func (s *Server) updateMemory(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+	var req api.UpdateMemoryRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := find(s.memories[projectID], r.PathValue("id"), memoryID)
+	if m == nil {
+		writeError(w, http.StatusNotFound, "memory not found")
+		return
+	}
+	if req.Content != "" {
+		m.Content = req.Content
+	}
+	if req.Tags != nil {
+		m.Tags = req.Tags
+	}
+	if req.RelatedDecisionIds != nil {
+		m.RelatedDecisionIds = req.RelatedDecisionIds
+	}
+	m.UpdatedAt = timestamp()
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) deleteMemory(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var removed bool
+	s.memories[projectID], removed = remove(s.memories[projectID], r.PathValue("id"), memoryID)
+	if !removed {
+		writeError(w, http.StatusNotFound, "memory not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listTasks(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tasks := s.tasks[projectID]
+	if tasks == nil {
+		tasks = []*api.Task{}
+	}
+	writeJSON(w, http.StatusOK, api.ListTasksResponse{Tasks: tasks, Total: len(tasks)})
+}
+
+func (s *Server) createTask(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+	var req api.CreateTaskRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if req.Title == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, s.AddTask(projectID, api.Task{
+		Title:              req.Title,
+		Description:        req.Description,
+		Priority:           req.Priority,
+		RelatedDecisionIds: req.RelatedDecisionIds,
+		RelatedMemoryIds:   req.RelatedMemoryIds,
+	}))
+}
+
+func (s *Server) updateTask(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+	var req api.UpdateTaskRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t := find(s.tasks[projectID], r.PathValue("id"), taskID)
+	if t == nil {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	if req.Title != "" {
+		t.Title = req.Title
+	}
+	if req.Description != "" {
+		t.Description = req.Description
+	}
+	if req.Priority != "" {
+		t.Priority = req.Priority
+	}
+	if req.Status != "" {
+		t.Status = req.Status
+	}
+	t.UpdatedAt = timestamp()
+	if t.Status == "DONE" && t.CompletedAt == nil {
+		completedAt := t.UpdatedAt
+		t.CompletedAt = &completedAt
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) deleteTask(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var removed bool
+	s.tasks[projectID], removed = remove(s.tasks[projectID], r.PathValue("id"), taskID)
+	if !removed {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listCapsules(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	capsules := s.capsules[projectID]
+	if capsules == nil {
+		capsules = []*api.Capsule{}
+	}
+	writeJSON(w, http.StatusOK, api.ListCapsulesResponse{Capsules: capsules, Total: len(capsules)})
+}
+
+func (s *Server) graphStats(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stats := s.graph[projectID]; stats != nil {
+		writeJSON(w, http.StatusOK, stats)
+		return
+	}
+
+	// Every entity is a node; links between them are edges
+	stats := s.buildGraph(projectID).Stats()
+	writeJSON(w, http.StatusOK, stats)
+}
+
+func (s *Server) graphNeighbors(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+	opts := api.NeighborOptions{
+		NodeTypes: splitList(r.URL.Query().Get("types")),
+		EdgeTypes: splitList(r.URL.Query().Get("edge_types")),
+	}
+	if d := r.URL.Query().Get("depth"); d != "" {
+		var err error
+		if opts.Depth, err = strconv.Atoi(d); err != nil {
+			writeError(w, http.StatusBadRequest, "depth must be a number")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.buildGraph(projectID)
+	if _, ok := g.Node(r.PathValue("id")); !ok {
+		writeError(w, http.StatusNotFound, "node not found")
+		return
+	}
+	neighbors, err := g.Neighbors(r.PathValue("id"), opts)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, neighbors)
+}
+
+func (s *Server) graphPaths(w http.ResponseWriter, r *http.Request) {
+	projectID, ok := requireProject(w, r)
+	if !ok {
+		return
+	}
+	from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+	if from == "" || to == "" {
+		writeError(w, http.StatusBadRequest, "from and to are required")
+		return
+	}
+	maxDepth := 0
+	if d := r.URL.Query().Get("max_depth"); d != "" {
+		var err error
+		if maxDepth, err = strconv.Atoi(d); err != nil {
+			writeError(w, http.StatusBadRequest, "max_depth must be a number")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.buildGraph(projectID)
+	for _, id := range []string{from, to} {
+		if _, ok := g.Node(id); !ok {
+			writeError(w, http.StatusNotFound, "node not found")
+			return
+		}
+	}
+	paths, err := g.Paths(from, to, maxDepth)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if paths == nil {
+		paths = []api.GraphPath{}
+	}
+	writeJSON(w, http.StatusOK, api.GraphPathsResponse{Paths: paths})
+}
+
+// buildGraph makes the graph of a project's entities and code chunks. The
+// caller holds s.mu.
+func (s *Server) buildGraph(projectID string) *api.Graph {
+	decisions := copyAll(s.decisions[projectID])
+	g := api.NewGraph(decisions, s.memories[projectID], s.tasks[projectID], s.capsules[projectID])
+	for _, c := range s.chunks[projectID] {
+		g.AddNode(c.node)
+		for _, id := range c.decisionIDs {
+			g.AddEdge(api.GraphEdge{Source: c.node.ID, Target: id, Type: api.EdgeCodeChunkDecision})
+		}
+	}
+	return g
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// ============================================================================
+// HOPPER
+// ============================================================================
+
+// chat streams the reply in the format the API uses: an optional progress
+// section, the content between __CONTENT_START__ and __USAGE__, then usage
+func (s *Server) chat(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireProject(w, r); !ok {
+		return
+	}
+	var req api.ChatRequest
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	reply := "You said: " + req.Message
+	if s.chatReply != nil {
+		reply = s.chatReply(&req)
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	write := func(chunk string) {
+		fmt.Fprint(w, chunk)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	write("__PROGRESS__thinking__END_PROGRESS__")
+	write("__CONTENT_START__")
+	for _, word := range strings.SplitAfter(reply, " ") {
+		write(word)
+	}
+	write(`__USAGE__{"input_tokens":1,"output_tokens":1}`)
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func requireProject(w http.ResponseWriter, r *http.Request) (string, bool) {
+	projectID := r.Header.Get("X-Project-ID")
+	if projectID == "" {
+		writeError(w, http.StatusBadRequest, "X-Project-ID header is required")
+		return "", false
+	}
+	return projectID, true
+}
+
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return false
+	}
+	return true
+}
+
+func decisionID(d *api.Decision) string { return d.ID }
+func memoryID(m *api.Memory) string     { return m.ID }
+func taskID(t *api.Task) string         { return t.ID }
+
+func find[T any](items []*T, id string, idOf func(*T) string) *T {
+	for _, item := range items {
+		if idOf(item) == id {
+			return item
+		}
+	}
+	return nil
+}
+
+func remove[T any](items []*T, id string, idOf func(*T) string) ([]*T, bool) {
+	for i, item := range items {
+		if idOf(item) == id {
+			return append(items[:i], items[i+1:]...), true
+		}
+	}
+	return items, false
+}
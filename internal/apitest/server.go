@@ -0,0 +1,408 @@
+// Package apitest provides an in-process fake of decision-api for tests.
+// The fake keeps its state in memory, checks bearer tokens the way the real
+// API does and can inject latency and errors.
+//
+//	srv := apitest.NewServer()
+//	defer srv.Close()
+//	decision := srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Go"})
+//	client := api.NewClient(srv.Config())
+//
+// Clients save refreshed tokens to the config directory, so tests that
+// exercise token refresh should point HOPSULE_CONFIG_DIR at a temporary
+// directory.
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+)
+
+// Fixtures every server starts with
+const (
+	ProjectID      = "proj-1"
+	OrganizationID = "org-1"
+	Token          = "test-token"
+	RefreshToken   = "test-refresh-token"
+)
+
+// Server is a fake decision-api listening on a local port
+type Server struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	user         api.User
+	orgs         []*api.Organization
+	projects     []*api.Project
+	tokens       map[string]bool // bearer tokens that are accepted
+	refreshToken string
+	issued       int
+
+	decisions map[string][]*api.Decision
+	memories  map[string][]*api.Memory
+	tasks     map[string][]*api.Task
+	capsules  map[string][]*api.Capsule
+	graph     map[string]*api.GraphStats
+	chunks    map[string][]*codeChunk
+	sessions  []api.Session
+	apiTokens []api.APIToken
+	nextID    int
+
+	deviceCodes   map[string]int // code -> pending polls left
+	devicePending int
+	chatReply     func(req *api.ChatRequest) string
+
+	faults   []*Fault
+	requests []Request
+}
+
+// Request is a request received by the server, for assertions
+type Request struct {
+	Method    string
+	Path      string
+	ProjectID string
+	Body      string
+}
+
+// Fault makes matching requests slow or fail
+type Fault struct {
+	// Method and Path select the requests the fault applies to. Path
+	// matches as a prefix; empty values match every request.
+	Method string
+	Path   string
+
+	// Latency delays the response
+	Latency time.Duration
+	// Status, when set, is returned instead of the real response, e.g.
+	// http.StatusInternalServerError or http.StatusUnauthorized
+	Status int
+	// Unrouted answers like a server without the endpoint: a plain-text
+	// 404 instead of a JSON error
+	Unrouted bool
+	// Times limits the fault to the next n matching requests; 0 applies
+	// it to every one
+	Times int
+}
+
+// NewServer starts a fake API with one user, organization and project.
+// Requests must carry Token (or a token issued by a refresh or device
+// login) as a bearer token.
+func NewServer() *Server {
+	s := &Server{
+		user: api.User{ID: "user-1", Name: "Test User", Email: "test@example.com"},
+		orgs: []*api.Organization{{ID: OrganizationID, Name: "Test Org", Slug: "test-org"}},
+		projects: []*api.Project{
+			{ID: ProjectID, Name: "Test Project", Slug: "test-project", OrganizationID: OrganizationID},
+		},
+		tokens:       map[string]bool{Token: true},
+		refreshToken: RefreshToken,
+		decisions:    map[string][]*api.Decision{},
+		memories:     map[string][]*api.Memory{},
+		tasks:        map[string][]*api.Task{},
+		capsules:     map[string][]*api.Capsule{},
+		graph:        map[string]*api.GraphStats{},
+		chunks:       map[string][]*codeChunk{},
+		deviceCodes:  map[string]int{},
+		sessions: []api.Session{
+			{ID: "session-1", DeviceName: "CLI on test", CreatedAt: timestamp(), Current: true},
+		},
+	}
+	s.Server = httptest.NewServer(s.handler())
+	return s
+}
+
+// Config returns a CLI config signed in to the server as its user, with
+// ProjectID selected
+func (s *Server) Config() *config.Config {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &config.Config{
+		APIURL:       s.URL,
+		Token:        Token,
+		RefreshToken: s.refreshToken,
+		Project:      ProjectID,
+		Organization: OrganizationID,
+		User:         &config.User{ID: s.user.ID, Email: s.user.Email, Name: s.user.Name},
+	}
+}
+
+// Inject adds a fault. Faults are checked in the order they were added and
+// the first match applies.
+func (s *Server) Inject(f Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fault := f
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes every injected fault
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = nil
+}
+
+// RevokeTokens rejects every token issued so far, as if the session had
+// expired on the server. The refresh token keeps working.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = map[string]bool{}
+}
+
+// SetDevicePending makes device logins stay pending for n polls before
+// they complete
+func (s *Server) SetDevicePending(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devicePending = n
+}
+
+// SetChatReply sets how Hopper answers chat messages. By default it echoes
+// the message.
+func (s *Server) SetChatReply(reply func(req *api.ChatRequest) string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chatReply = reply
+}
+
+// Requests returns the requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// AddDecision stores a decision in a project, filling in the ID, status and
+// timestamps when they are empty
+func (s *Server) AddDecision(projectID string, d api.Decision) api.Decision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if d.ID == "" {
+		d.ID = s.newID("dec")
+	}
+	if d.Status == "" {
+		d.Status = "DRAFT"
+	}
+	if d.CreatedAt == "" {
+		d.CreatedAt = timestamp()
+	}
+	if d.UpdatedAt == "" {
+		d.UpdatedAt = d.CreatedAt
+	}
+	s.decisions[projectID] = append(s.decisions[projectID], &d)
+	return d
+}
+
+// AddMemory stores a memory in a project, filling in the ID and timestamp
+// when they are empty
+func (s *Server) AddMemory(projectID string, m api.Memory) api.Memory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m.ID == "" {
+		m.ID = s.newID("mem")
+	}
+	if m.CreatedAt == "" {
+		m.CreatedAt = timestamp()
+	}
+	s.memories[projectID] = append(s.memories[projectID], &m)
+	return m
+}
+
+// AddTask stores a task in a project, filling in the ID, status, priority
+// and timestamps when they are empty
+func (s *Server) AddTask(projectID string, t api.Task) api.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.ID == "" {
+		t.ID = s.newID("task")
+	}
+	if t.Status == "" {
+		t.Status = "TODO"
+	}
+	if t.Priority == "" {
+		t.Priority = "MEDIUM"
+	}
+	if t.CreatedAt == "" {
+		t.CreatedAt = timestamp()
+	}
+	if t.UpdatedAt == "" {
+		t.UpdatedAt = t.CreatedAt
+	}
+	s.tasks[projectID] = append(s.tasks[projectID], &t)
+	return t
+}
+
+// AddCapsule stores a capsule in a project, filling in the ID, status and
+// timestamps when they are empty
+func (s *Server) AddCapsule(projectID string, c api.Capsule) api.Capsule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.ID == "" {
+		c.ID = s.newID("cap")
+	}
+	if c.Status == "" {
+		c.Status = "DRAFT"
+	}
+	if c.CreatedAt == "" {
+		c.CreatedAt = timestamp()
+	}
+	if c.UpdatedAt == "" {
+		c.UpdatedAt = c.CreatedAt
+	}
+	s.capsules[projectID] = append(s.capsules[projectID], &c)
+	return c
+}
+
+// codeChunk is a code chunk node and the decisions it implements
+type codeChunk struct {
+	node        api.GraphNode
+	decisionIDs []string
+}
+
+// AddCodeChunk adds a code chunk to a project's graph, linked to decisions.
+// The ID is filled in when it is empty.
+func (s *Server) AddCodeChunk(projectID string, chunk api.GraphNode, decisionIDs ...string) api.GraphNode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if chunk.ID == "" {
+		chunk.ID = s.newID("chunk")
+	}
+	chunk.Type = api.NodeCodeChunk
+	s.chunks[projectID] = append(s.chunks[projectID], &codeChunk{node: chunk, decisionIDs: decisionIDs})
+	return chunk
+}
+
+// SetGraphStats fixes the response of GET /graph/stats for a project. By
+// default the stats are computed from the project's entities.
+func (s *Server) SetGraphStats(projectID string, stats api.GraphStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.graph[projectID] = &stats
+}
+
+// Decisions returns a copy of a project's decisions
+func (s *Server) Decisions(projectID string) []api.Decision {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyAll(s.decisions[projectID])
+}
+
+// Memories returns a copy of a project's memories
+func (s *Server) Memories(projectID string) []api.Memory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyAll(s.memories[projectID])
+}
+
+// Tasks returns a copy of a project's tasks
+func (s *Server) Tasks(projectID string) []api.Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyAll(s.tasks[projectID])
+}
+
+// serve records the request, applies faults and checks the token before
+// passing it to next
+func (s *Server) serve(next http.HandlerFunc, public bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(strings.NewReader(string(body)))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, Request{
+			Method:    r.Method,
+			Path:      r.URL.Path,
+			ProjectID: r.Header.Get("X-Project-ID"),
+			Body:      string(body),
+		})
+		fault := s.matchFault(r)
+		authorized := public || s.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		s.mu.Unlock()
+
+		if fault != nil {
+			if fault.Latency > 0 {
+				select {
+				case <-time.After(fault.Latency):
+				case <-r.Context().Done():
+					return
+				}
+			}
+			if fault.Unrouted {
+				http.NotFound(w, r)
+				return
+			}
+			if fault.Status != 0 {
+				writeError(w, fault.Status, fmt.Sprintf("injected %d", fault.Status))
+				return
+			}
+		}
+		if !authorized {
+			writeError(w, http.StatusUnauthorized, "unauthorized")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// matchFault returns the fault for r, using up one of its Times. The caller
+// holds s.mu.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	for i, f := range s.faults {
+		if (f.Method != "" && f.Method != r.Method) || !strings.HasPrefix(r.URL.Path, f.Path) {
+			continue
+		}
+		matched := *f
+		if f.Times > 0 {
+			if f.Times--; f.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return &matched
+	}
+	return nil
+}
+
+// newID returns a new sequential ID. The caller holds s.mu.
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+// issueToken returns a new accepted bearer token. The caller holds s.mu.
+func (s *Server) issueToken() string {
+	s.issued++
+	token := fmt.Sprintf("%s-%d", Token, s.issued)
+	s.tokens[token] = true
+	return token
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func copyAll[T any](items []*T) []T {
+	out := make([]T, 0, len(items))
+	for _, item := range items {
+		out = append(out, *item)
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,298 @@
+// Package auth implements the browser-based device login shared by
+// 'hopsule login' and the interactive dashboard.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+)
+
+const (
+	// DefaultPollInterval is how often the API is asked whether the user
+	// finished signing in
+	DefaultPollInterval = 2 * time.Second
+	// DefaultTimeout bounds the whole flow when the server doesn't say when
+	// the device code expires
+	DefaultTimeout = 10 * time.Minute
+)
+
+// State is a step of the device flow
+type State int
+
+const (
+	StateIdle State = iota
+	StateInitiated
+	StateWaiting
+	StateComplete
+	StateExpired
+	StateCancelled
+	StateFailed
+)
+
+func (s State) String() string {
+	switch s {
+	case StateIdle:
+		return "idle"
+	case StateInitiated:
+		return "initiated"
+	case StateWaiting:
+		return "waiting"
+	case StateComplete:
+		return "complete"
+	case StateExpired:
+		return "expired"
+	case StateCancelled:
+		return "cancelled"
+	case StateFailed:
+		return "failed"
+	}
+	return "unknown"
+}
+
+// Done reports whether the flow has ended
+func (s State) Done() bool {
+	return s >= StateComplete
+}
+
+// Errors reported for flows that end without signing in
+var (
+	ErrExpired   = errors.New("login session expired - please try again")
+	ErrCancelled = errors.New("login cancelled")
+)
+
+// Event describes the flow after a transition
+type Event struct {
+	State   State
+	Code    string        // device code to show the user
+	URL     string        // page where the user approves the code
+	Elapsed time.Duration // time since the flow was initiated
+	Result  *Result       // set when State is StateComplete
+	Err     error         // set when State is StateExpired or StateFailed
+}
+
+// Result is the outcome of a completed login
+type Result struct {
+	Token        string
+	RefreshToken string
+	ExpiresAt    string
+	User         config.User
+}
+
+// DeviceFlow runs the device code login: Start asks the API for a code, the
+// user approves it in a browser, and Poll checks for completion. Callers
+// either call Start and Poll themselves (e.g. from a Bubble Tea command) or
+// let Run drive the loop.
+type DeviceFlow struct {
+	client       *api.Client
+	webURL       string
+	deviceName   string
+	PollInterval time.Duration
+
+	// mu guards the fields below; the TUI polls from Bubble Tea commands
+	// while the key handler may cancel
+	mu       sync.Mutex
+	state    State
+	code     string
+	started  time.Time
+	deadline time.Time
+}
+
+// NewDeviceFlow prepares a flow against cfg's API and web URLs
+func NewDeviceFlow(cfg *config.Config) *DeviceFlow {
+	return &DeviceFlow{
+		// Device auth needs no token, and an expired one must not get in
+		// the way of signing in again
+		client:       api.NewClient(cfg).WithBaseURL(cfg.GetAPIURL()).WithToken(""),
+		webURL:       cfg.GetWebURL(),
+		deviceName:   DeviceName(),
+		PollInterval: DefaultPollInterval,
+	}
+}
+
+// State returns the current state
+func (f *DeviceFlow) State() State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.state
+}
+
+// Start requests a device code
+func (f *DeviceFlow) Start() Event {
+	resp, err := f.client.DeviceAuthInit(f.deviceName)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.state == StateCancelled {
+		return f.event()
+	}
+	if err != nil {
+		return f.fail(fmt.Errorf("failed to initialize login: %w", err))
+	}
+
+	f.code = resp.Code
+	f.started = time.Now()
+	f.deadline = f.started.Add(DefaultTimeout)
+	if resp.ExpiresIn > 0 {
+		f.deadline = f.started.Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	f.state = StateInitiated
+	return f.event()
+}
+
+// Poll checks once whether the user has approved the code
+func (f *DeviceFlow) Poll() Event {
+	f.mu.Lock()
+	if f.state.Done() || f.state == StateIdle {
+		defer f.mu.Unlock()
+		return f.event()
+	}
+	if time.Now().After(f.deadline) {
+		defer f.mu.Unlock()
+		f.state = StateExpired
+		return f.event()
+	}
+	code := f.code
+	f.mu.Unlock()
+
+	// Don't hold the lock over the request so Cancel stays responsive
+	resp, err := f.client.DeviceAuthPoll(code)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.state == StateCancelled {
+		return f.event()
+	}
+	if err != nil {
+		return f.fail(fmt.Errorf("failed to check login status: %w", err))
+	}
+
+	switch resp.Status {
+	case "complete":
+		f.state = StateComplete
+		ev := f.event()
+		ev.Result = &Result{
+			Token:        resp.Token,
+			RefreshToken: resp.RefreshToken,
+			ExpiresAt:    resp.Expiry(),
+			User: config.User{
+				ID:        resp.UserID,
+				Email:     resp.Email,
+				Name:      resp.Name,
+				AvatarURL: resp.AvatarURL,
+			},
+		}
+		return ev
+	case "expired":
+		f.state = StateExpired
+	case "pending":
+		f.state = StateWaiting
+	default:
+		return f.fail(fmt.Errorf("unexpected status: %s", resp.Status))
+	}
+	return f.event()
+}
+
+// Cancel stops the flow; later polls report StateCancelled
+func (f *DeviceFlow) Cancel() Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.state.Done() {
+		f.state = StateCancelled
+	}
+	return f.event()
+}
+
+// Run starts the flow and polls until it ends or ctx is cancelled, calling
+// observe after every transition
+func (f *DeviceFlow) Run(ctx context.Context, observe func(Event)) (*Result, error) {
+	ev := f.Start()
+	observe(ev)
+
+	ticker := time.NewTicker(f.PollInterval)
+	defer ticker.Stop()
+
+	for !ev.State.Done() {
+		select {
+		case <-ctx.Done():
+			ev = f.Cancel()
+			observe(ev)
+		case <-ticker.C:
+			ev = f.Poll()
+			observe(ev)
+		}
+	}
+
+	switch ev.State {
+	case StateComplete:
+		return ev.Result, nil
+	case StateCancelled:
+		return nil, ErrCancelled
+	default:
+		return nil, ev.Err
+	}
+}
+
+func (f *DeviceFlow) fail(err error) Event {
+	f.state = StateFailed
+	ev := f.event()
+	ev.Err = err
+	return ev
+}
+
+func (f *DeviceFlow) event() Event {
+	ev := Event{State: f.state}
+	if f.code != "" {
+		ev.Code = f.code
+		ev.URL = fmt.Sprintf("%s/auth/device?code=%s", f.webURL, f.code)
+		ev.Elapsed = time.Since(f.started)
+	}
+	if f.state == StateExpired {
+		ev.Err = ErrExpired
+	}
+	return ev
+}
+
+// Save stores a completed login in cfg and writes it to disk
+func Save(cfg *config.Config, r *Result) error {
+	user := r.User
+	cfg.Token = r.Token
+	cfg.RefreshToken = r.RefreshToken
+	cfg.TokenExpiresAt = r.ExpiresAt
+	cfg.User = &user
+
+	// The token is only valid for the server that issued it, so keep its URL
+	// even when it came from an env var or flag
+	config.Default().Persist(cfg, "api_url", "token")
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
+
+// DeviceName identifies this machine in the account's session list
+func DeviceName() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	osName := runtime.GOOS
+	switch osName {
+	case "darwin":
+		osName = "macOS"
+	case "linux":
+		osName = "Linux"
+	case "windows":
+		osName = "Windows"
+	}
+
+	return fmt.Sprintf("CLI on %s (%s)", osName, hostname)
+}
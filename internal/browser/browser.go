@@ -0,0 +1,61 @@
+// Package browser opens URLs in the user's browser and decides when that
+// isn't possible, e.g. over SSH or inside a container.
+package browser
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Open opens url in the default browser
+func Open(url string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		cmd = exec.Command("xdg-open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+
+	return cmd.Start()
+}
+
+// Headless reports whether there is likely no browser to open, along with a
+// short reason such as "SSH session" for display
+func Headless() (bool, string) {
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_CLIENT") != "" || os.Getenv("SSH_TTY") != "" {
+		return true, "SSH session"
+	}
+
+	if inContainer() {
+		return true, "container"
+	}
+
+	// macOS and Windows always have a window system; elsewhere a browser
+	// needs X11 or Wayland
+	switch runtime.GOOS {
+	case "darwin", "windows":
+		return false, ""
+	}
+	if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return true, "no display"
+	}
+
+	return false, ""
+}
+
+func inContainer() bool {
+	for _, marker := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(marker); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,18 @@
+package browser
+
+import (
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// QRCode renders text as a QR code for the terminal using half-block
+// characters, two modules per character cell. It is drawn light-on-dark, which
+// phone cameras read fine on both dark and light terminal themes.
+func QRCode(text string) (string, error) {
+	code, err := qrcode.New(text, qrcode.Low)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(code.ToSmallString(false), "\n"), nil
+}
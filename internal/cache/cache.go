@@ -0,0 +1,240 @@
+// Package cache is the offline copy of project data: an SQLite database in
+// the cache directory with an FTS5 index over it, used by 'hopsule search'
+// and the dashboard's filters. Everything in it can be rebuilt from the
+// backend, so a database with an older schema is simply recreated. Items are
+// kept apart per source, the backend they came from, so servers that share
+// project IDs, such as staging and production, don't mix.
+package cache
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // pure-Go driver, registers "sqlite"
+
+	"github.com/Cagangedik/cli-tool/internal/config"
+)
+
+// FileName is the database file in config.CacheDir
+const FileName = "hopsule.db"
+
+// schemaVersion is stored in PRAGMA user_version. Bump it when the schema
+// changes; older databases are dropped and rebuilt on the next sync.
+const schemaVersion = 2
+
+const schema = `
+CREATE TABLE items (
+	rowid      INTEGER PRIMARY KEY,
+	source     TEXT NOT NULL,
+	project_id TEXT NOT NULL,
+	type       TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	title      TEXT NOT NULL DEFAULT '',
+	body       TEXT NOT NULL DEFAULT '',
+	status     TEXT NOT NULL DEFAULT '',
+	tags       TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL DEFAULT '',
+	UNIQUE (source, project_id, type, id)
+);
+
+CREATE VIRTUAL TABLE items_fts USING fts5(
+	title, body, tags,
+	content = 'items', content_rowid = 'rowid',
+	tokenize = 'porter unicode61'
+);
+
+CREATE TRIGGER items_ai AFTER INSERT ON items BEGIN
+	INSERT INTO items_fts (rowid, title, body, tags) VALUES (new.rowid, new.title, new.body, new.tags);
+END;
+CREATE TRIGGER items_ad AFTER DELETE ON items BEGIN
+	INSERT INTO items_fts (items_fts, rowid, title, body, tags) VALUES ('delete', old.rowid, old.title, old.body, old.tags);
+END;
+CREATE TRIGGER items_au AFTER UPDATE ON items BEGIN
+	INSERT INTO items_fts (items_fts, rowid, title, body, tags) VALUES ('delete', old.rowid, old.title, old.body, old.tags);
+	INSERT INTO items_fts (rowid, title, body, tags) VALUES (new.rowid, new.title, new.body, new.tags);
+END;
+
+CREATE TABLE syncs (
+	source     TEXT NOT NULL,
+	project_id TEXT NOT NULL,
+	synced_at  TEXT NOT NULL,
+	PRIMARY KEY (source, project_id)
+);
+`
+
+// Cache is an open cache database, reading and writing the items of one
+// source. It is safe for concurrent use.
+type Cache struct {
+	db     *sql.DB
+	path   string
+	source string
+}
+
+// Source names the backend cfg reads project data from: the API URL, or
+// "local" for the local backend
+func Source(cfg *config.Config) string {
+	if cfg == nil {
+		return ""
+	}
+	if config.ResolveBackend(cfg) == config.BackendLocal {
+		return config.BackendLocal
+	}
+	return cfg.GetAPIURL()
+}
+
+// Open opens the cache in config.CacheDir for source, creating it if needed
+func Open(source string) (*Cache, error) {
+	dir, err := config.CacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return OpenFile(filepath.Join(dir, FileName), source)
+}
+
+// OpenFile opens the cache database at path for source
+func OpenFile(path, source string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// WAL and a busy timeout let the dashboard and a command use the cache
+	// at the same time
+	dsn := "file:" + filepath.ToSlash(path) + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache: %w", err)
+	}
+	c := &Cache{db: db, path: path, source: source}
+	if err := c.migrate(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Path returns the database file
+func (c *Cache) Path() string {
+	return c.path
+}
+
+// Close closes the database
+func (c *Cache) Close() error {
+	return c.db.Close()
+}
+
+// migrate creates the schema, replacing it if it is from another version
+func (c *Cache) migrate() error {
+	var version int
+	if err := c.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read cache: %w", err)
+	}
+	if version == schemaVersion {
+		return nil
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to update cache: %w", err)
+	}
+	defer tx.Rollback()
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS items_fts",
+		"DROP TABLE IF EXISTS items",
+		"DROP TABLE IF EXISTS syncs",
+		schema,
+		fmt.Sprintf("PRAGMA user_version = %d", schemaVersion),
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("failed to create cache schema: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create cache schema: %w", err)
+	}
+	return nil
+}
+
+// Replace swaps the cached items of one type in a project for items
+func (c *Cache) Replace(projectID, itemType string, items []Item) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to update cache: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := c.replaceItems(tx, projectID, itemType, items); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to update cache: %w", err)
+	}
+	return nil
+}
+
+func (c *Cache) replaceItems(tx *sql.Tx, projectID, itemType string, items []Item) error {
+	if _, err := tx.Exec("DELETE FROM items WHERE source = ? AND project_id = ? AND type = ?", c.source, projectID, itemType); err != nil {
+		return fmt.Errorf("failed to update cache: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO items (source, project_id, type, id, title, body, status, tags, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to update cache: %w", err)
+	}
+	defer stmt.Close()
+	for _, item := range items {
+		if _, err := stmt.Exec(c.source, projectID, itemType, item.ID, item.Title, item.Body, item.Status,
+			strings.Join(item.Tags, " "), item.CreatedAt); err != nil {
+			return fmt.Errorf("failed to cache %s %s: %w", itemType, item.ID, err)
+		}
+	}
+	return nil
+}
+
+// SyncedAt returns when a project was last fully synced
+func (c *Cache) SyncedAt(projectID string) (time.Time, bool, error) {
+	var syncedAt string
+	err := c.db.QueryRow("SELECT synced_at FROM syncs WHERE source = ? AND project_id = ?", c.source, projectID).Scan(&syncedAt)
+	if err == sql.ErrNoRows {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to read cache: %w", err)
+	}
+	t, err := time.Parse(time.RFC3339, syncedAt)
+	if err != nil {
+		return time.Time{}, false, nil
+	}
+	return t, true, nil
+}
+
+// Invalidate marks a project's cached copy as out of date, so the next
+// search syncs it first
+func (c *Cache) Invalidate(projectID string) error {
+	if _, err := c.db.Exec("DELETE FROM syncs WHERE source = ? AND project_id = ?", c.source, projectID); err != nil {
+		return fmt.Errorf("failed to invalidate cache: %w", err)
+	}
+	return nil
+}
+
+// Count returns the number of cached items of each type in a project
+func (c *Cache) Count(projectID string) (map[string]int, error) {
+	rows, err := c.db.Query("SELECT type, COUNT(*) FROM items WHERE source = ? AND project_id = ? GROUP BY type", c.source, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+	defer rows.Close()
+	counts := map[string]int{}
+	for rows.Next() {
+		var itemType string
+		var n int
+		if err := rows.Scan(&itemType, &n); err != nil {
+			return nil, fmt.Errorf("failed to read cache: %w", err)
+		}
+		counts[itemType] = n
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,146 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+)
+
+// Item types, the same vocabulary as api.TaggedItem.Type
+const (
+	TypeDecision = "decision"
+	TypeMemory   = "memory"
+	TypeTask     = "task"
+	TypeCapsule  = "capsule"
+)
+
+// Types lists the item types in the order search results name them
+var Types = []string{TypeDecision, TypeMemory, TypeTask, TypeCapsule}
+
+// Item is a cached decision, memory, task or capsule. Title and Body are what the
+// full-text index covers.
+type Item struct {
+	Type      string
+	ID        string
+	Title     string
+	Body      string
+	Status    string
+	Tags      []string
+	CreatedAt string
+}
+
+// DecisionItems converts decisions: the statement is the title and the
+// rationale the body
+func DecisionItems(decisions []api.Decision) []Item {
+	items := make([]Item, 0, len(decisions))
+	for _, d := range decisions {
+		items = append(items, Item{
+			Type:      TypeDecision,
+			ID:        d.ID,
+			Title:     d.Statement,
+			Body:      d.Rationale,
+			Status:    d.Status,
+			Tags:      d.Tags,
+			CreatedAt: d.CreatedAt,
+		})
+	}
+	return items
+}
+
+// MemoryItems converts memories; they have no title, only content
+func MemoryItems(memories []*api.Memory) []Item {
+	items := make([]Item, 0, len(memories))
+	for _, m := range memories {
+		items = append(items, Item{
+			Type:      TypeMemory,
+			ID:        m.ID,
+			Body:      m.Content,
+			Tags:      m.Tags,
+			CreatedAt: m.CreatedAt,
+		})
+	}
+	return items
+}
+
+// TaskItems converts tasks: the title is the title and the description
+// the body
+func TaskItems(tasks []*api.Task) []Item {
+	items := make([]Item, 0, len(tasks))
+	for _, t := range tasks {
+		items = append(items, Item{
+			Type:      TypeTask,
+			ID:        t.ID,
+			Title:     t.Title,
+			Body:      t.Description,
+			Status:    t.Status,
+			CreatedAt: t.CreatedAt,
+		})
+	}
+	return items
+}
+
+// CapsuleItems converts capsules: the name is the title and the
+// description the body
+func CapsuleItems(capsules []*api.Capsule) []Item {
+	items := make([]Item, 0, len(capsules))
+	for _, c := range capsules {
+		items = append(items, Item{
+			Type:      TypeCapsule,
+			ID:        c.ID,
+			Title:     c.Name,
+			Body:      c.Description,
+			Status:    c.Status,
+			CreatedAt: c.CreatedAt,
+		})
+	}
+	return items
+}
+
+// SyncResult counts what a sync cached
+type SyncResult struct {
+	Decisions int
+	Memories  int
+	Tasks     int
+	Capsules  int
+}
+
+// Sync replaces the cached copy of a project with the backend's data
+func (c *Cache) Sync(svc api.Service, projectID string) (*SyncResult, error) {
+	items, err := api.LoadItems(svc, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to update cache: %w", err)
+	}
+	defer tx.Rollback()
+
+	for itemType, items := range map[string][]Item{
+		TypeDecision: DecisionItems(items.Decisions),
+		TypeMemory:   MemoryItems(items.Memories),
+		TypeTask:     TaskItems(items.Tasks),
+		TypeCapsule:  CapsuleItems(items.Capsules),
+	} {
+		if err := c.replaceItems(tx, projectID, itemType, items); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec(`INSERT INTO syncs (source, project_id, synced_at) VALUES (?, ?, ?)
+		ON CONFLICT (source, project_id) DO UPDATE SET synced_at = excluded.synced_at`,
+		c.source, projectID, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		return nil, fmt.Errorf("failed to update cache: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to update cache: %w", err)
+	}
+
+	return &SyncResult{
+		Decisions: len(items.Decisions),
+		Memories:  len(items.Memories),
+		Tasks:     len(items.Tasks),
+		Capsules:  len(items.Capsules),
+	}, nil
+}
@@ -0,0 +1,148 @@
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Markers around matched terms in Result.Title and Result.Snippet. Use
+// Highlight to style them.
+const (
+	MatchStart = "\x02"
+	MatchEnd   = "\x03"
+)
+
+// DefaultLimit is the number of results Search returns when no limit is
+// given
+const DefaultLimit = 20
+
+// SearchOptions narrows a search
+type SearchOptions struct {
+	// Types limits results to these item types; empty means all
+	Types []string
+	// Statuses limits results to items with one of these statuses,
+	// compared case-insensitively
+	Statuses []string
+	// Tags limits results to items carrying every one of these tags
+	Tags []string
+	// Limit is the maximum number of results (DefaultLimit if 0, no limit
+	// if negative)
+	Limit int
+}
+
+// Result is a search hit
+type Result struct {
+	Type      string
+	ID        string
+	Title     string // with matches marked
+	Snippet   string // part of the body around the matches, with matches marked
+	Status    string
+	Tags      []string
+	CreatedAt string
+	// Rank is the BM25 score; lower is a better match
+	Rank float64
+}
+
+// Search finds items in a project matching every word of query (as a
+// prefix, so partial words match while typing), best matches first
+func (c *Cache) Search(projectID, query string, opts SearchOptions) ([]Result, error) {
+	match := MatchQuery(query)
+	if match == "" {
+		return nil, nil
+	}
+
+	// Title matches count most, then tags, then the body
+	q := `SELECT i.type, i.id, i.status, i.tags, i.created_at,
+			highlight(items_fts, 0, ?, ?),
+			snippet(items_fts, 1, ?, ?, '…', 16),
+			bm25(items_fts, 10.0, 1.0, 5.0) AS rank
+		FROM items_fts JOIN items i ON i.rowid = items_fts.rowid
+		WHERE items_fts MATCH ? AND i.source = ? AND i.project_id = ?`
+	args := []any{MatchStart, MatchEnd, MatchStart, MatchEnd, match, c.source, projectID}
+	if len(opts.Types) > 0 {
+		q += " AND i.type IN (?" + strings.Repeat(", ?", len(opts.Types)-1) + ")"
+		for _, t := range opts.Types {
+			args = append(args, t)
+		}
+	}
+	if len(opts.Statuses) > 0 {
+		q += " AND i.status IN (?" + strings.Repeat(", ?", len(opts.Statuses)-1) + ")"
+		for _, s := range opts.Statuses {
+			args = append(args, strings.ToUpper(s))
+		}
+	}
+	for _, tag := range opts.Tags {
+		// Tags are stored space-separated
+		q += " AND (' ' || i.tags || ' ') LIKE ? ESCAPE '\\'"
+		args = append(args, "% "+escapeLike(tag)+" %")
+	}
+	q += " ORDER BY rank, i.created_at DESC"
+	limit := opts.Limit
+	if limit == 0 {
+		limit = DefaultLimit
+	}
+	if limit > 0 {
+		q += fmt.Sprintf(" LIMIT %d", limit)
+	}
+
+	rows, err := c.db.Query(q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search cache: %w", err)
+	}
+	defer rows.Close()
+
+	var results []Result
+	for rows.Next() {
+		var r Result
+		var tags string
+		if err := rows.Scan(&r.Type, &r.ID, &r.Status, &tags, &r.CreatedAt, &r.Title, &r.Snippet, &r.Rank); err != nil {
+			return nil, fmt.Errorf("failed to search cache: %w", err)
+		}
+		r.Tags = strings.Fields(tags)
+		results = append(results, r)
+	}
+	return results, rows.Err()
+}
+
+// MatchQuery turns free text into an FTS5 query: each word is quoted, so
+// FTS syntax in the input is taken literally, and matched as a prefix
+func MatchQuery(query string) string {
+	words := strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, w := range words {
+		words[i] = `"` + w + `"*`
+	}
+	return strings.Join(words, " ")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// Highlight replaces the match markers in s, passing each match to mark
+func Highlight(s string, mark func(string) string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, MatchStart)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(s[start:], MatchEnd)
+		if end < 0 {
+			break
+		}
+		end += start
+		b.WriteString(s[:start])
+		b.WriteString(mark(s[start+len(MatchStart) : end]))
+		s = s[end+len(MatchEnd):]
+	}
+	b.WriteString(s)
+	return strings.NewReplacer(MatchStart, "", MatchEnd, "").Replace(b.String())
+}
+
+// StripMarks removes the match markers from s
+func StripMarks(s string) string {
+	return Highlight(s, func(match string) string { return match })
+}
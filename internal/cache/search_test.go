@@ -0,0 +1,241 @@
+package cache
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/apitest"
+)
+
+func openCache(t *testing.T) *Cache {
+	t.Helper()
+	return openSource(t, filepath.Join(t.TempDir(), FileName), "https://api.example.com")
+}
+
+func openSource(t *testing.T, path, source string) *Cache {
+	t.Helper()
+	c, err := OpenFile(path, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func seed(t *testing.T, c *Cache) {
+	t.Helper()
+	decisions := []Item{
+		{Type: TypeDecision, ID: "d1", Title: "Use Postgres for storage", Body: "Mature and well known", Status: "ACCEPTED", Tags: []string{"db"}, CreatedAt: "2024-01-01T00:00:00Z"},
+		{Type: TypeDecision, ID: "d2", Title: "Cache responses", Body: "Postgres is slow under load", Status: "DRAFT", Tags: []string{"perf", "100%_sure"}, CreatedAt: "2024-01-02T00:00:00Z"},
+	}
+	memories := []Item{
+		{Type: TypeMemory, ID: "m1", Body: "Postgres upgrade broke the replicas", Tags: []string{"db", "ops"}, CreatedAt: "2024-01-03T00:00:00Z"},
+	}
+	if err := c.Replace("p1", TypeDecision, decisions); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Replace("p1", TypeMemory, memories); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Replace("p2", TypeDecision, []Item{{Type: TypeDecision, ID: "other", Title: "Postgres everywhere"}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func resultIDs(results []Result) []string {
+	ids := []string{}
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	c := openCache(t)
+	seed(t, c)
+
+	tests := []struct {
+		name  string
+		query string
+		opts  SearchOptions
+		want  []string
+	}{
+		// A title match ranks above body matches
+		{"ranking", "postgres", SearchOptions{}, []string{"d1", "m1", "d2"}},
+		{"prefix", "postg", SearchOptions{}, []string{"d1", "m1", "d2"}},
+		{"every word", "postgres replicas", SearchOptions{}, []string{"m1"}},
+		{"types", "postgres", SearchOptions{Types: []string{TypeMemory}}, []string{"m1"}},
+		{"statuses", "postgres", SearchOptions{Statuses: []string{"draft"}}, []string{"d2"}},
+		{"tags", "postgres", SearchOptions{Tags: []string{"db"}}, []string{"d1", "m1"}},
+		{"every tag", "postgres", SearchOptions{Tags: []string{"db", "ops"}}, []string{"m1"}},
+		{"tag is not a substring match", "postgres", SearchOptions{Tags: []string{"d"}}, []string{}},
+		{"LIKE wildcards are literal", "postgres", SearchOptions{Tags: []string{"100%_sure"}}, []string{"d2"}},
+		{"LIKE wildcards don't match other tags", "postgres", SearchOptions{Tags: []string{"%"}}, []string{}},
+		{"limit", "postgres", SearchOptions{Limit: 1}, []string{"d1"}},
+		{"FTS syntax is literal", `postgres" OR "cache`, SearchOptions{}, []string{}},
+		{"no words", "  ?! ", SearchOptions{}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := c.Search("p1", tt.query, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resultIDs(results); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchMarksMatches(t *testing.T) {
+	c := openCache(t)
+	seed(t, c)
+
+	results, err := c.Search("p1", "storage", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	marked := Highlight(results[0].Title, func(s string) string { return "[" + s + "]" })
+	if marked != "Use Postgres for [storage]" {
+		t.Errorf("highlighted title = %q", marked)
+	}
+	if StripMarks(results[0].Title) != "Use Postgres for storage" {
+		t.Errorf("stripped title = %q", StripMarks(results[0].Title))
+	}
+	if !reflect.DeepEqual(results[0].Tags, []string{"db"}) {
+		t.Errorf("tags = %v", results[0].Tags)
+	}
+}
+
+func TestMatchQuery(t *testing.T) {
+	tests := map[string]string{
+		"postgres":       `"postgres"*`,
+		"use  post-gres": `"use"* "post"* "gres"*`,
+		`a" OR "b`:       `"a"* "OR"* "b"*`,
+		"NEAR(x y)":      `"NEAR"* "x"* "y"*`,
+		"über café":      `"über"* "café"*`,
+		"":               "",
+	}
+	for query, want := range tests {
+		if got := MatchQuery(query); got != want {
+			t.Errorf("MatchQuery(%q) = %s, want %s", query, got, want)
+		}
+	}
+}
+
+func TestReplaceOnlyTouchesOneType(t *testing.T) {
+	c := openCache(t)
+	seed(t, c)
+
+	if err := c.Replace("p1", TypeDecision, nil); err != nil {
+		t.Fatal(err)
+	}
+	counts, err := c.Count("p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(counts, map[string]int{TypeMemory: 1}) {
+		t.Errorf("counts = %v", counts)
+	}
+	if counts, _ := c.Count("p2"); counts[TypeDecision] != 1 {
+		t.Errorf("p2 counts = %v", counts)
+	}
+}
+
+func TestSyncAndInvalidate(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Go", Rationale: "One static binary"})
+	srv.AddMemory(apitest.ProjectID, api.Memory{Content: "Builds take 2s"})
+	client := api.NewClient(srv.Config())
+
+	c := openCache(t)
+	if _, ok, _ := c.SyncedAt(apitest.ProjectID); ok {
+		t.Fatal("new cache claims to be synced")
+	}
+
+	result, err := c.Sync(client, apitest.ProjectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Decisions != 1 || result.Memories != 1 {
+		t.Errorf("synced %+v", result)
+	}
+	if _, ok, _ := c.SyncedAt(apitest.ProjectID); !ok {
+		t.Error("cache isn't marked synced")
+	}
+	results, err := c.Search(apitest.ProjectID, "binary", SearchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Type != TypeDecision {
+		t.Errorf("results = %+v", results)
+	}
+
+	if err := c.Invalidate(apitest.ProjectID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := c.SyncedAt(apitest.ProjectID); ok {
+		t.Error("cache is still marked synced after Invalidate")
+	}
+	// Invalidating keeps the items for offline searches
+	if counts, _ := c.Count(apitest.ProjectID); counts[TypeDecision] != 1 {
+		t.Errorf("counts = %v after Invalidate", counts)
+	}
+}
+
+func TestSyncFailureKeepsCache(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Go"})
+	client := api.NewClient(srv.Config())
+
+	c := openCache(t)
+	if _, err := c.Sync(client, apitest.ProjectID); err != nil {
+		t.Fatal(err)
+	}
+	srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Rust"})
+	srv.Inject(apitest.Fault{Path: "/tasks", Status: 500})
+
+	_, err := c.Sync(client, apitest.ProjectID)
+	if err == nil || !strings.Contains(err.Error(), "failed to list tasks") {
+		t.Fatalf("got %v, want a task listing error", err)
+	}
+	if counts, _ := c.Count(apitest.ProjectID); counts[TypeDecision] != 1 {
+		t.Errorf("a failed sync changed the cache: %v", counts)
+	}
+}
+
+func TestSourcesAreKeptApart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	staging := openSource(t, path, "https://staging.example.com")
+	prod := openSource(t, path, "https://api.example.com")
+	seed(t, staging)
+
+	if counts, _ := prod.Count("p1"); len(counts) != 0 {
+		t.Errorf("prod counts = %v, want none", counts)
+	}
+	if results, _ := prod.Search("p1", "postgres", SearchOptions{}); len(results) != 0 {
+		t.Errorf("prod search found %v", resultIDs(results))
+	}
+
+	srv := apitest.NewServer()
+	defer srv.Close()
+	srv.AddDecision("p1", api.Decision{Statement: "Use Go"})
+	if _, err := prod.Sync(api.NewClient(srv.Config()), "p1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := staging.SyncedAt("p1"); ok {
+		t.Error("syncing prod marked staging synced")
+	}
+	if counts, _ := staging.Count("p1"); counts[TypeDecision] != 2 {
+		t.Errorf("syncing prod replaced staging's items: %v", counts)
+	}
+}
@@ -0,0 +1,33 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func NewAuthCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Manage authentication",
+		Long: `Manage authentication beyond your own sign-in.
+
+Use 'hopsule auth token' to create service tokens for CI and bots, and
+'hopsule auth sessions' to see and revoke signed-in devices.`,
+	}
+
+	cmd.AddCommand(newAuthTokenCommand())
+	cmd.AddCommand(newAuthSessionsCommand())
+
+	return cmd
+}
+
+// printNotLoggedIn explains why there is no usable session
+func printNotLoggedIn(cfg *config.Config) {
+	if cfg.SessionExpired() {
+		fmt.Println("Your session has expired.")
+		return
+	}
+	fmt.Println("Not logged in.")
+}
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newAuthSessionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "List devices signed in to your account",
+		Long: `List the devices signed in to your account through 'hopsule login'.
+
+Use 'hopsule auth sessions revoke <session-id>' to sign a device out
+remotely, e.g. a lost laptop or a CI runner you no longer use.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			client, err := newClientFromFlags(cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			sessions, err := client.ListSessions()
+			if err != nil {
+				return fmt.Errorf("failed to list sessions: %w", err)
+			}
+
+			if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(sessions)
+			}
+
+			if len(sessions) == 0 {
+				fmt.Println("No active sessions.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "\tID\tDEVICE\tSIGNED IN\tLAST USED")
+			fmt.Fprintln(w, "\t──\t──────\t─────────\t─────────")
+			for _, s := range sessions {
+				marker := " "
+				if s.Current {
+					marker = "*"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					marker,
+					truncateID(s.ID),
+					s.DeviceName,
+					valueOrDash(s.CreatedAt),
+					valueOrDash(s.LastUsedAt),
+				)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	cmd.AddCommand(newAuthSessionsRevokeCommand())
+
+	return cmd
+}
+
+func newAuthSessionsRevokeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <session-id>",
+		Short: "Sign a device out remotely",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			client, err := newClientFromFlags(cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			// Look the session up first so revoking this device also
+			// clears the now useless local token
+			sessions, err := client.ListSessions()
+			if err != nil {
+				return fmt.Errorf("failed to list sessions: %w", err)
+			}
+			current := false
+			found := false
+			for _, s := range sessions {
+				if s.ID == args[0] {
+					found = true
+					current = s.Current
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("session %s not found", args[0])
+			}
+
+			if err := client.RevokeSession(args[0]); err != nil {
+				return fmt.Errorf("failed to revoke session: %w", err)
+			}
+
+			fmt.Printf("✓ Revoked session %s\n", args[0])
+			if current {
+				if err := config.ClearAuth(cfg); err != nil {
+					return fmt.Errorf("failed to clear credentials: %w", err)
+				}
+				fmt.Println("This was the current device; run 'hopsule login' to sign in again.")
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/spf13/cobra"
+)
+
+const defaultTokenExpiryDays = 90
+
+func newAuthTokenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Manage service tokens",
+		Long: `Manage project-scoped service tokens for CI and bots.
+
+Service tokens belong to a project rather than a person, carry read or
+write scopes and expire. Use them instead of a personal login token:
+
+  hopsule auth token create --name ci --scope read
+  export HOPSULE_TOKEN=hps_...`,
+	}
+
+	cmd.AddCommand(newAuthTokenCreateCommand())
+	cmd.AddCommand(newAuthTokenListCommand())
+	cmd.AddCommand(newAuthTokenRevokeCommand())
+
+	return cmd
+}
+
+func newAuthTokenCreateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a service token",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, _ := cmd.Flags().GetString("name")
+			if name == "" {
+				return fmt.Errorf("--name is required")
+			}
+
+			scope, _ := cmd.Flags().GetString("scope")
+			scopes, err := tokenScopes(scope)
+			if err != nil {
+				return err
+			}
+
+			days, _ := cmd.Flags().GetInt("expires-in")
+			if days <= 0 {
+				return fmt.Errorf("--expires-in must be a positive number of days")
+			}
+
+			cfg, err := config.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			projectID, err := resolveProjectID(cmd, cfg)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromFlags(cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			token, err := client.CreateAPIToken(projectID, &api.CreateAPITokenRequest{
+				Name:          name,
+				Scopes:        scopes,
+				ExpiresInDays: days,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create token: %w", err)
+			}
+
+			if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(token)
+			}
+
+			fmt.Println("┌─────────────────────────────────────────┐")
+			fmt.Println("│         ✓ Service Token Created         │")
+			fmt.Println("└─────────────────────────────────────────┘")
+			fmt.Println()
+			fmt.Printf("  Name:    %s\n", token.Name)
+			fmt.Printf("  ID:      %s\n", token.ID)
+			fmt.Printf("  Project: %s\n", token.ProjectID)
+			fmt.Printf("  Scopes:  %s\n", strings.Join(token.Scopes, ", "))
+			if token.ExpiresAt != "" {
+				fmt.Printf("  Expires: %s\n", token.ExpiresAt)
+			}
+			fmt.Println()
+			fmt.Printf("  %s\n", token.Token)
+			fmt.Println()
+			fmt.Println("Copy the token now; it will not be shown again.")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("name", "", "Name of the token, e.g. the CI system using it (required)")
+	cmd.Flags().String("scope", api.TokenScopeRead, "Access scope: read or write")
+	cmd.Flags().Int("expires-in", defaultTokenExpiryDays, "Days until the token expires")
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	return cmd
+}
+
+func newAuthTokenListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List service tokens of the project",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			projectID, err := resolveProjectID(cmd, cfg)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromFlags(cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			tokens, err := client.ListAPITokens(projectID)
+			if err != nil {
+				return fmt.Errorf("failed to list tokens: %w", err)
+			}
+
+			if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				return encoder.Encode(tokens)
+			}
+
+			if len(tokens) == 0 {
+				fmt.Println("No service tokens found.")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "ID\tNAME\tSCOPES\tEXPIRES\tLAST USED")
+			fmt.Fprintln(w, "──\t────\t──────\t───────\t─────────")
+			for _, t := range tokens {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					truncateID(t.ID),
+					t.Name,
+					strings.Join(t.Scopes, ","),
+					valueOrDash(t.ExpiresAt),
+					valueOrDash(t.LastUsedAt),
+				)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	return cmd
+}
+
+func newAuthTokenRevokeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revoke <token-id>",
+		Short: "Revoke a service token",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := config.GetConfig()
+			if err != nil {
+				return fmt.Errorf("failed to load config: %w", err)
+			}
+			projectID, err := resolveProjectID(cmd, cfg)
+			if err != nil {
+				return err
+			}
+			client, err := newClientFromFlags(cmd, cfg)
+			if err != nil {
+				return err
+			}
+
+			if err := client.RevokeAPIToken(projectID, args[0]); err != nil {
+				return fmt.Errorf("failed to revoke token: %w", err)
+			}
+
+			fmt.Printf("✓ Revoked token %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+// tokenScopes expands a --scope value; write access implies read
+func tokenScopes(scope string) ([]string, error) {
+	switch scope {
+	case api.TokenScopeRead:
+		return []string{api.TokenScopeRead}, nil
+	case api.TokenScopeWrite:
+		return []string{api.TokenScopeRead, api.TokenScopeWrite}, nil
+	default:
+		return nil, fmt.Errorf("invalid scope %q (use read or write)", scope)
+	}
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -14,41 +15,47 @@ func NewConfigCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "config",
 		Short: "Configure CLI settings",
-		Long:  "Interactively configure API URL, token, and default project",
-		RunE: func(cmd *cobra.Command, args []string) error {
-			cfg, err := config.GetConfig()
-			if err != nil {
-				cfg = &config.Config{}
-			}
+		Long: `Read and change CLI settings.
 
+Without a subcommand, interactively configure the API URL, token, and
+default project. Use get, set, unset and list to script any setting of the
+active profile, or pass --local to work on the nearest .hopsule file.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			reader := bufio.NewReader(os.Stdin)
 
-			fmt.Printf("API URL [%s]: ", cfg.APIURL)
-			apiURL, _ := reader.ReadString('\n')
-			apiURL = strings.TrimSpace(apiURL)
-			if apiURL != "" {
-				cfg.APIURL = apiURL
-			}
-			if cfg.APIURL == "" {
-				cfg.APIURL = "http://localhost:8080"
+			prompts := []struct {
+				key   string
+				label string
+			}{
+				{"api_url", "API URL"},
+				{"token", "Token"},
+				{"project", "Default Project ID"},
 			}
+			for _, p := range prompts {
+				key, err := config.LookupKey(p.key)
+				if err != nil {
+					return err
+				}
+				current, err := config.GetValue(p.key)
+				if err != nil && !errors.Is(err, config.ErrKeyNotSet) {
+					return err
+				}
+				if key.Secret {
+					current = maskToken(current)
+				} else if current == "" {
+					current = key.Default
+				}
 
-			fmt.Printf("Token [%s]: ", maskToken(cfg.Token))
-			token, _ := reader.ReadString('\n')
-			token = strings.TrimSpace(token)
-			if token != "" {
-				cfg.Token = token
-			}
-
-			fmt.Printf("Default Project ID [%s]: ", cfg.Project)
-			project, _ := reader.ReadString('\n')
-			project = strings.TrimSpace(project)
-			if project != "" {
-				cfg.Project = project
-			}
-
-			if err := config.SaveConfig(cfg); err != nil {
-				return fmt.Errorf("failed to save config: %w", err)
+				fmt.Printf("%s [%s]: ", p.label, current)
+				value, _ := reader.ReadString('\n')
+				value = strings.TrimSpace(value)
+				if value == "" {
+					continue
+				}
+				if err := config.SetValue(p.key, value); err != nil {
+					return fmt.Errorf("failed to save config: %w", err)
+				}
 			}
 
 			fmt.Println("\nConfiguration saved successfully!")
@@ -56,6 +63,15 @@ func NewConfigCommand() *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(newConfigGetCommand())
+	cmd.AddCommand(newConfigSetCommand())
+	cmd.AddCommand(newConfigUnsetCommand())
+	cmd.AddCommand(newConfigListCommand())
+	cmd.AddCommand(newConfigEditCommand())
+	cmd.AddCommand(newConfigPathCommand())
+	cmd.AddCommand(newConfigMigrateCommand())
+	cmd.AddCommand(newConfigValidateCommand())
+
 	return cmd
 }
 
@@ -0,0 +1,321 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func newConfigGetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a setting",
+		Long: `Print the stored value of a setting in the active profile, or in the
+nearest .hopsule file with --local.
+
+Exits with an error if the key is not set. Environment variables such as
+HOPSULE_API_URL are not applied; the value shown is the one on disk.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				value string
+				err   error
+			)
+			if local, _ := cmd.Flags().GetBool("local"); local {
+				configPath, pathErr := projectConfigPathArg(nil)
+				if pathErr != nil {
+					return pathErr
+				}
+				value, err = config.GetProjectValue(configPath, args[0])
+			} else {
+				value, err = config.GetValue(args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(value)
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("local", false, "Read from the nearest .hopsule file")
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func newConfigSetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Change a setting",
+		Long: `Change a setting in the active profile, or in the nearest .hopsule file
+with --local.
+
+URLs are checked before they are saved. Tokens are stored in the
+configured credential store, never in the config file.
+
+Run 'hopsule config list' to see every key.`,
+		Example: `  hopsule config set api_url https://api.hopsule.com
+  hopsule config set organization 3f6c2a1e-...
+  hopsule config set --local project.name "Payments"`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if local, _ := cmd.Flags().GetBool("local"); local {
+				configPath, err := projectConfigPathArg(nil)
+				if err != nil {
+					return err
+				}
+				return config.SetProjectValue(configPath, args[0], args[1])
+			}
+			return config.SetValue(args[0], args[1])
+		},
+	}
+
+	cmd.Flags().Bool("local", false, "Change the nearest .hopsule file")
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func newConfigUnsetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a setting so its default applies",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if local, _ := cmd.Flags().GetBool("local"); local {
+				configPath, err := projectConfigPathArg(nil)
+				if err != nil {
+					return err
+				}
+				return config.UnsetProjectValue(configPath, args[0])
+			}
+			return config.UnsetValue(args[0])
+		},
+	}
+
+	cmd.Flags().Bool("local", false, "Change the nearest .hopsule file")
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func newConfigListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List settings and their values",
+		Long: `List every setting of the active profile with its stored value, or the
+default in parentheses when it isn't set. Tokens are masked; use
+'hopsule config get token' to print one.
+
+With --local, list the settings in the nearest .hopsule file.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+
+			if local, _ := cmd.Flags().GetBool("local"); local {
+				configPath, err := projectConfigPathArg(nil)
+				if err != nil {
+					return err
+				}
+				values, err := config.ListProjectValues(configPath)
+				if err != nil {
+					return err
+				}
+				for _, v := range values {
+					fmt.Fprintf(w, "%s\t%s\n", v.Key, v.Value)
+				}
+				return w.Flush()
+			}
+
+			values, err := config.ListValues()
+			if err != nil {
+				return err
+			}
+			for _, v := range values {
+				value := v.Value
+				switch {
+				case value != "" && v.Key.Secret:
+					value = maskToken(value)
+				case value == "" && v.Key.Default != "":
+					value = "(" + v.Key.Default + ")"
+				case value == "":
+					value = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\n", v.Key.Name, value)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().Bool("local", false, "List the nearest .hopsule file")
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func newConfigEditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in your editor",
+		Long: `Open the global config file, or the nearest .hopsule file with --local,
+in $VISUAL or $EDITOR.
+
+The file is edited as a copy and only replaced if the result is valid.
+Otherwise the edited copy is kept and its path printed.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			local, _ := cmd.Flags().GetBool("local")
+
+			var (
+				configPath string
+				validate   func(path string, data []byte) error
+				err        error
+			)
+			if local {
+				configPath, err = projectConfigPathArg(nil)
+				validate = config.ValidateProjectConfigData
+			} else {
+				configPath, err = config.ConfigFilePath()
+				validate = config.ValidateConfigData
+			}
+			if err != nil {
+				return err
+			}
+
+			return editFile(configPath, validate)
+		},
+	}
+
+	cmd.Flags().Bool("local", false, "Edit the nearest .hopsule file")
+	cmd.SilenceUsage = true
+
+	return cmd
+}
+
+func newConfigPathCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "path",
+		Short: "Print the location of the config file",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				configPath string
+				err        error
+			)
+			if local, _ := cmd.Flags().GetBool("local"); local {
+				configPath, err = projectConfigPathArg(nil)
+			} else {
+				configPath, err = config.ConfigFilePath()
+			}
+			if err != nil {
+				return err
+			}
+
+			fmt.Println(configPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("local", false, "Print the path of the nearest .hopsule file")
+
+	return cmd
+}
+
+// editFile opens a copy of path in the user's editor and replaces path with
+// it once validate accepts the result
+func editFile(path string, validate func(path string, data []byte) error) error {
+	original, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	perm := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(original)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+
+	if err := runEditor(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	edited, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", tmpPath, err)
+	}
+	if string(edited) == string(original) {
+		os.Remove(tmpPath)
+		fmt.Println("No changes.")
+		return nil
+	}
+	if err := validate(path, edited); err != nil {
+		return fmt.Errorf("%w\n\nYour changes were not saved; they are kept in %s", err, tmpPath)
+	}
+
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+
+	fmt.Printf("Saved %s\n", path)
+	return nil
+}
+
+// runEditor opens path in $VISUAL or $EDITOR, falling back to vi (notepad on
+// Windows)
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+
+	// Editors are often configured with arguments, e.g. "code --wait"
+	fields := strings.Fields(editor)
+	editorCmd := exec.Command(fields[0], append(fields[1:], path)...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return fmt.Errorf("editor %q exited with status %d; no changes saved", editor, exitErr.ExitCode())
+		}
+		return fmt.Errorf("failed to run editor %q: %w", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+func newConfigMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate [path]",
+		Short: "Upgrade a .hopsule file to the current schema version",
+		Long: `Upgrade a .hopsule file to the current schema version in place.
+
+Comments and key order are preserved. Without a path, the nearest .hopsule
+file in the current directory or its parents is migrated.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runConfigMigrate,
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Print the migrated file instead of writing it")
+
+	return cmd
+}
+
+func newConfigValidateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate [path]",
+		Short: "Check a .hopsule file against the schema",
+		Long: `Check a .hopsule file against the schema and report every problem
+with its line and column.
+
+Without a path, the nearest .hopsule file in the current directory or its
+parents is validated.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runConfigValidate,
+	}
+
+	return cmd
+}
+
+func runConfigMigrate(cmd *cobra.Command, args []string) error {
+	configPath, err := projectConfigPathArg(args)
+	if err != nil {
+		return err
+	}
+
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	result, err := config.MigrateProjectConfigFile(configPath, dryRun)
+	if err != nil {
+		return err
+	}
+
+	if len(result.Steps) == 0 {
+		fmt.Printf("%s is already at schema version %d.\n", configPath, result.ToVersion)
+		return nil
+	}
+
+	if dryRun {
+		fmt.Print(string(result.Data))
+		return nil
+	}
+
+	fmt.Printf("Migrated %s from version %d to %d:\n", configPath, result.FromVersion, result.ToVersion)
+	for _, step := range result.Steps {
+		fmt.Printf("  • %s\n", step)
+	}
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	configPath, err := projectConfigPathArg(args)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	if err := config.ValidateProjectConfigData(configPath, data); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s is valid.\n", configPath)
+
+	var projectCfg config.ProjectConfig
+	if err := yaml.Unmarshal(data, &projectCfg); err == nil && projectCfg.Version < config.HopsuleFileVersion {
+		fmt.Printf("Schema version %d is outdated; run 'hopsule config migrate' to upgrade to version %d.\n",
+			projectCfg.Version, config.HopsuleFileVersion)
+	}
+	return nil
+}
+
+// projectConfigPathArg returns the .hopsule path given on the command line,
+// or the nearest one above the current directory
+func projectConfigPathArg(args []string) (string, error) {
+	if len(args) == 1 {
+		info, err := os.Stat(args[0])
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+		if info.IsDir() {
+			path, _ := config.ProjectConfigFile(args[0])
+			return path, nil
+		}
+		return args[0], nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+	for dir := cwd; ; {
+		if candidate, ok := config.ProjectConfigFile(dir); ok {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return "", config.ErrProjectConfigNotFound
+}
@@ -22,25 +22,14 @@ func NewCreateCommand() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			projectID, _ := cmd.Flags().GetString("project")
-			if projectID == "" {
-				projectID = cfg.Project
-			}
-			if projectID == "" {
-				return fmt.Errorf("project ID is required (use --project or set in config)")
-			}
-
-			apiURL, _ := cmd.Flags().GetString("api-url")
-			if apiURL == "" {
-				apiURL = cfg.APIURL
-			}
-			if apiURL == "" {
-				return fmt.Errorf("API URL is required (use --api-url or set in config)")
+			projectID, err := resolveProjectID(cmd, cfg)
+			if err != nil {
+				return err
 			}
 
-			token, _ := cmd.Flags().GetString("token")
-			if token == "" {
-				token = cfg.Token
+			scopeKey, err := resolveCreateScope(cmd)
+			if err != nil {
+				return err
 			}
 
 			// Interactive prompts
@@ -67,27 +56,65 @@ func NewCreateCommand() *cobra.Command {
 			}
 			rationale := strings.Join(rationaleLines, "\n")
 
-			client := api.NewClient(cfg).
-				WithBaseURL(apiURL).
-				WithToken(token)
+			client, err := newServiceFromFlags(cmd, cfg)
+			if err != nil {
+				return err
+			}
 
 			req := api.CreateDecisionRequest{
 				Statement: statement,
 				Rationale: rationale,
 			}
+			if scopeKey != "" {
+				req.ScopeKey = &scopeKey
+			}
 
 			decision, err := client.CreateDecision(projectID, req)
 			if err != nil {
 				return fmt.Errorf("failed to create decision: %w", err)
 			}
+			invalidateCache(cmd, cfg, projectID)
 
 			fmt.Printf("\nDecision created successfully!\n")
 			fmt.Printf("ID: %s\n", decision.ID)
 			fmt.Printf("Status: %s\n", decision.Status)
+			if scopeKey != "" {
+				fmt.Printf("Scope: %s\n", scopeKey)
+			}
 
 			return nil
 		},
 	}
 
+	cmd.Flags().String("scope", "", "Scope key for the decision (default: inferred from current directory)")
+	cmd.Flags().Bool("global", false, "Create a project-wide decision, ignoring directory scopes")
+
 	return cmd
 }
+
+// resolveCreateScope picks the scope for a new decision from --scope/--global
+// or, failing that, from the .hopsule scope matching the current directory
+func resolveCreateScope(cmd *cobra.Command) (string, error) {
+	global, _ := cmd.Flags().GetBool("global")
+	if global {
+		return "", nil
+	}
+
+	scopeKey, _ := cmd.Flags().GetString("scope")
+	if scopeKey != "" {
+		projectCfg, _, err := config.LoadProjectConfig()
+		if err == nil && len(projectCfg.Scopes) > 0 && !projectCfg.HasScope(scopeKey) {
+			return "", fmt.Errorf("scope %q is not declared in %s", scopeKey, config.HopsuleFileName)
+		}
+		return scopeKey, nil
+	}
+
+	scopeKey, err := config.CurrentScope()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve scope: %w", err)
+	}
+	if scopeKey != "" {
+		fmt.Printf("Scope: %s (from %s)\n", scopeKey, config.HopsuleFileName)
+	}
+	return scopeKey, nil
+}
@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 
-	"github.com/Cagangedik/cli-tool/internal/api"
 	"github.com/Cagangedik/cli-tool/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -22,35 +21,21 @@ func NewDeprecateCommand() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			projectID, _ := cmd.Flags().GetString("project")
-			if projectID == "" {
-				projectID = cfg.Project
-			}
-			if projectID == "" {
-				return fmt.Errorf("project ID is required (use --project or set in config)")
-			}
-
-			apiURL, _ := cmd.Flags().GetString("api-url")
-			if apiURL == "" {
-				apiURL = cfg.APIURL
-			}
-			if apiURL == "" {
-				return fmt.Errorf("API URL is required (use --api-url or set in config)")
+			projectID, err := resolveProjectID(cmd, cfg)
+			if err != nil {
+				return err
 			}
 
-			token, _ := cmd.Flags().GetString("token")
-			if token == "" {
-				token = cfg.Token
+			client, err := newServiceFromFlags(cmd, cfg)
+			if err != nil {
+				return err
 			}
 
-			client := api.NewClient(cfg).
-				WithBaseURL(apiURL).
-				WithToken(token)
-
 			decision, err := client.DeprecateDecision(projectID, decisionID)
 			if err != nil {
 				return fmt.Errorf("failed to deprecate decision: %w", err)
 			}
+			invalidateCache(cmd, cfg, projectID)
 
 			fmt.Printf("Decision deprecated successfully!\n")
 			fmt.Printf("ID: %s\n", decision.ID)
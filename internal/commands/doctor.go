@@ -0,0 +1,473 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/charmbracelet/x/term"
+	"github.com/muesli/termenv"
+	"github.com/spf13/cobra"
+)
+
+// Check results reported by 'hopsule doctor'
+const (
+	checkPass = "pass"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+// maxClockSkew is how far the local clock may drift from the API server
+// before token expiry checks become unreliable
+const maxClockSkew = 2 * time.Minute
+
+type doctorCheck struct {
+	Name    string            `json:"name"`
+	Status  string            `json:"status"`
+	Message string            `json:"message"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+type doctorReport struct {
+	Version string        `json:"version"`
+	OS      string        `json:"os"`
+	Arch    string        `json:"arch"`
+	Profile string        `json:"profile,omitempty"`
+	APIURL  string        `json:"api_url,omitempty"`
+	Checks  []doctorCheck `json:"checks"`
+}
+
+func (r *doctorReport) add(name, status, message string, details map[string]string) {
+	r.Checks = append(r.Checks, doctorCheck{Name: name, Status: status, Message: message, Details: details})
+}
+
+func (r *doctorReport) count(status string) int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+func NewDoctorCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose configuration and connectivity problems",
+		Long: `Check the CLI setup and print a pass/warn/fail report.
+
+Checks:
+  • Config file location and permissions
+  • Deprecated DECISION_* environment variables
+  • .hopsule discovery and schema
+  • API reachability, latency and clock skew
+  • Token validity and project access
+  • Proxy and TLS settings
+  • Terminal capabilities for the interactive dashboard
+
+Attach the output of 'hopsule doctor --json' to bug reports.`,
+		Args: cobra.NoArgs,
+		// Failed checks are reported in the output; usage would only add noise
+		SilenceUsage: true,
+		RunE:         runDoctor,
+	}
+
+	cmd.Flags().Bool("json", false, "Output as JSON")
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	report := &doctorReport{
+		Version: cmd.Root().Version,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+	}
+
+	cfg := checkConfigFile(report)
+	if cfg != nil {
+		report.Profile = cfg.Profile
+		report.APIURL = cfg.GetAPIURL()
+	}
+
+	checkEnvironment(report)
+	checkProjectFile(report)
+	checkProxy(report, cfg)
+	checkTLS(report, cfg)
+
+	if cfg != nil {
+		client := api.NewClient(cfg).WithBaseURL(cfg.GetAPIURL())
+		if checkAPI(report, cfg) {
+			if checkToken(report, cfg, client) {
+				checkProjectAccess(cmd, report, cfg, client)
+			}
+		}
+	}
+
+	checkTerminal(report)
+
+	if jsonOutput, _ := cmd.Flags().GetBool("json"); jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(report)
+	}
+
+	if failed := report.count(checkFail); failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func checkConfigFile(r *doctorReport) *config.Config {
+	path, err := config.ConfigFilePath()
+	if err != nil {
+		r.add("config file", checkFail, err.Error(), nil)
+		return nil
+	}
+	details := map[string]string{"path": path}
+
+	info, statErr := os.Stat(path)
+	cfg, err := config.GetConfig()
+	switch {
+	case err != nil:
+		r.add("config file", checkFail, err.Error(), details)
+		return nil
+	case os.IsNotExist(statErr):
+		r.add("config file", checkWarn, "not found; using defaults (run 'hopsule login')", details)
+	case statErr != nil:
+		r.add("config file", checkFail, statErr.Error(), details)
+	case runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0:
+		details["mode"] = fmt.Sprintf("%04o", info.Mode().Perm())
+		r.add("config file", checkWarn, fmt.Sprintf("readable by other users; run 'chmod 600 %s'", path), details)
+	default:
+		details["mode"] = fmt.Sprintf("%04o", info.Mode().Perm())
+		r.add("config file", checkPass, path, details)
+	}
+
+	return cfg
+}
+
+func checkEnvironment(r *doctorReport) {
+	deprecated := config.DeprecatedEnv()
+	if len(deprecated) == 0 {
+		return
+	}
+
+	var renames []string
+	for alias, name := range deprecated {
+		renames = append(renames, alias+" → "+name)
+	}
+	sort.Strings(renames)
+	r.add("environment", checkWarn, "deprecated variables set: "+strings.Join(renames, ", "), deprecated)
+}
+
+func checkProjectFile(r *doctorReport) {
+	resolved, err := config.ResolveCurrentProject()
+	switch {
+	case errors.Is(err, config.ErrProjectConfigNotFound):
+		r.add(".hopsule", checkWarn, "no .hopsule file found in this directory or its parents (run 'hopsule init')", nil)
+	case err != nil:
+		r.add(".hopsule", checkFail, err.Error(), nil)
+	default:
+		details := map[string]string{
+			"path":    resolved.ConfigPath,
+			"project": resolved.Project.ID,
+			"version": fmt.Sprintf("%d", resolved.Config.Version),
+		}
+		if resolved.Config.Version < config.HopsuleFileVersion {
+			r.add(".hopsule", checkWarn, fmt.Sprintf("schema version %d is outdated; run 'hopsule config migrate'", resolved.Config.Version), details)
+			return
+		}
+		r.add(".hopsule", checkPass, resolved.ConfigPath, details)
+	}
+}
+
+func checkProxy(r *doctorReport, cfg *config.Config) {
+	details := map[string]string{}
+	for _, name := range []string{"HTTPS_PROXY", "HTTP_PROXY", "NO_PROXY"} {
+		value := os.Getenv(name)
+		if value == "" {
+			value = os.Getenv(strings.ToLower(name))
+		}
+		if value != "" && name != "NO_PROXY" {
+			value = redactProxy(value)
+		}
+		if value != "" {
+			details[strings.ToLower(name)] = value
+		}
+	}
+
+	if cfg == nil {
+		r.add("proxy", checkSkip, "config not loaded", details)
+		return
+	}
+
+	if cfg.Proxy != "" {
+		details["config"] = redactProxy(cfg.Proxy)
+	}
+	if cfg.UnixSocket != "" {
+		details["unix_socket"] = cfg.UnixSocket
+		r.add("proxy", checkPass, fmt.Sprintf("direct connection over Unix socket %s", cfg.UnixSocket), details)
+		return
+	}
+
+	target, err := url.Parse(cfg.GetAPIURL())
+	if err != nil {
+		r.add("proxy", checkFail, fmt.Sprintf("invalid API URL: %v", err), details)
+		return
+	}
+	proxy, err := api.ProxyFunc(cfg)
+	if err != nil {
+		r.add("proxy", checkFail, err.Error(), details)
+		return
+	}
+	if proxy == nil {
+		r.add("proxy", checkPass, "direct connection", details)
+		return
+	}
+	proxyURL, err := proxy(&http.Request{URL: target})
+	if err != nil {
+		r.add("proxy", checkFail, fmt.Sprintf("invalid proxy setting: %v", err), details)
+		return
+	}
+	if proxyURL == nil {
+		r.add("proxy", checkPass, "direct connection", details)
+		return
+	}
+	r.add("proxy", checkPass, fmt.Sprintf("via %s", proxyURL.Redacted()), details)
+}
+
+// redactProxy hides the password in a proxy URL so the report can be shared.
+// Values without a scheme are read as http URLs, as the proxy lookup does.
+func redactProxy(value string) string {
+	raw := value
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "(invalid URL)"
+	}
+	if u.User == nil {
+		return value
+	}
+	return u.Redacted()
+}
+
+func checkTLS(r *doctorReport, cfg *config.Config) {
+	if cfg == nil || !strings.HasPrefix(cfg.GetAPIURL(), "https://") {
+		return
+	}
+
+	details := map[string]string{}
+	if cfg.CAFile != "" {
+		details["ca_file"] = cfg.CAFile
+	}
+	if cfg.ClientCert != "" {
+		details["client_cert"] = cfg.ClientCert
+	}
+	if cfg.InsecureSkipVerify {
+		r.add("tls", checkWarn, "certificate verification is disabled (insecure_skip_verify)", details)
+		return
+	}
+	r.add("tls", checkPass, "certificates verified", details)
+}
+
+// checkAPI measures a round trip to the API and compares clocks using the
+// Date header. It reports whether the API is reachable.
+func checkAPI(r *doctorReport, cfg *config.Config) bool {
+	apiURL := cfg.GetAPIURL()
+	httpClient, err := api.NewHTTPClient(cfg)
+	if err != nil {
+		r.add("api", checkFail, fmt.Sprintf("invalid network settings: %v", err), nil)
+		r.add("clock", checkSkip, "API not reachable", nil)
+		return false
+	}
+	httpClient.Timeout = 10 * time.Second
+
+	start := time.Now()
+	resp, err := httpClient.Get(apiURL)
+	latency := time.Since(start)
+	if err != nil {
+		r.add("api", checkFail, fmt.Sprintf("cannot reach %s: %v", apiURL, err), nil)
+		r.add("clock", checkSkip, "API not reachable", nil)
+		return false
+	}
+	resp.Body.Close()
+
+	details := map[string]string{
+		"url":     apiURL,
+		"source":  config.Default().Origin(cfg, "api_url").String(),
+		"latency": latency.Round(time.Millisecond).String(),
+	}
+	switch {
+	case resp.StatusCode >= 500:
+		details["status"] = resp.Status
+		r.add("api", checkWarn, fmt.Sprintf("reachable, but returned %s", resp.Status), details)
+	case latency > 2*time.Second:
+		r.add("api", checkWarn, fmt.Sprintf("reachable, but slow (%s)", details["latency"]), details)
+	default:
+		r.add("api", checkPass, fmt.Sprintf("reachable in %s", details["latency"]), details)
+	}
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		r.add("clock", checkSkip, "server did not send a Date header", nil)
+		return true
+	}
+	// The Date header is taken roughly halfway through the round trip
+	skew := time.Until(serverTime.Add(latency / 2)).Round(time.Second)
+	if skew < 0 {
+		skew = -skew
+	}
+	clockDetails := map[string]string{"skew": skew.String()}
+	if skew > maxClockSkew {
+		r.add("clock", checkWarn, fmt.Sprintf("local clock differs from the server by %s; token expiry checks may be wrong", skew), clockDetails)
+	} else {
+		r.add("clock", checkPass, fmt.Sprintf("in sync with the server (±%s)", skew), clockDetails)
+	}
+	return true
+}
+
+// checkToken validates the token with GetMe. It reports whether project
+// access is worth checking.
+func checkToken(r *doctorReport, cfg *config.Config, client *api.Client) bool {
+	if cfg.Token == "" {
+		r.add("token", checkWarn, "not logged in (run 'hopsule login')", nil)
+		return false
+	}
+
+	tokenType := api.TokenTypeOf(cfg.Token)
+	details := map[string]string{"type": tokenType}
+	if expiry := cfg.TokenExpiry(); !expiry.IsZero() {
+		details["expires_at"] = expiry.UTC().Format(time.RFC3339)
+	}
+
+	me, err := client.GetMe()
+	if err != nil {
+		r.add("token", checkFail, err.Error(), details)
+		return false
+	}
+
+	switch {
+	case me.IsServiceToken() && me.APIToken != nil:
+		r.add("token", checkPass, fmt.Sprintf("valid service token %q", me.APIToken.Name), details)
+	case me.User != nil:
+		r.add("token", checkPass, fmt.Sprintf("valid, signed in as %s", me.User.Email), details)
+	default:
+		r.add("token", checkPass, "valid", details)
+	}
+	return true
+}
+
+func checkProjectAccess(cmd *cobra.Command, r *doctorReport, cfg *config.Config, client *api.Client) {
+	projectID, err := resolveProjectID(cmd, cfg)
+	if err != nil {
+		r.add("project", checkSkip, "no project configured", nil)
+		return
+	}
+
+	details := map[string]string{"project": projectID}
+	if _, err := client.GetProjectStatus(projectID); err != nil {
+		r.add("project", checkFail, err.Error(), details)
+		return
+	}
+	r.add("project", checkPass, fmt.Sprintf("access to %s", projectID), details)
+}
+
+func checkTerminal(r *doctorReport) {
+	details := map[string]string{
+		"term":      os.Getenv("TERM"),
+		"colorterm": os.Getenv("COLORTERM"),
+	}
+
+	// The report itself may be redirected, e.g. doctor --json > report.json,
+	// so any standard stream attached to the terminal will do
+	tty := terminalFile(os.Stdout, os.Stderr, os.Stdin)
+	if tty == nil {
+		r.add("terminal", checkWarn, "not running in a terminal; the interactive dashboard needs one", details)
+		return
+	}
+
+	width, height, err := term.GetSize(tty.Fd())
+	if err == nil {
+		details["size"] = fmt.Sprintf("%dx%d", width, height)
+	}
+
+	profile := termenv.NewOutput(tty).EnvColorProfile()
+	details["colors"] = colorProfileName(profile)
+
+	switch {
+	case os.Getenv("TERM") == "dumb":
+		r.add("terminal", checkWarn, "TERM=dumb; the interactive dashboard won't render correctly", details)
+	case err == nil && (width < 80 || height < 24):
+		r.add("terminal", checkWarn, fmt.Sprintf("%dx%d is smaller than the 80x24 the dashboard needs", width, height), details)
+	case profile == termenv.Ascii:
+		r.add("terminal", checkWarn, "no color support detected", details)
+	default:
+		r.add("terminal", checkPass, fmt.Sprintf("%s, %s", details["size"], details["colors"]), details)
+	}
+}
+
+// terminalFile returns the first of files that is a terminal, or nil
+func terminalFile(files ...*os.File) *os.File {
+	for _, f := range files {
+		if term.IsTerminal(f.Fd()) {
+			return f
+		}
+	}
+	return nil
+}
+
+func colorProfileName(p termenv.Profile) string {
+	switch p {
+	case termenv.TrueColor:
+		return "truecolor"
+	case termenv.ANSI256:
+		return "256 colors"
+	case termenv.ANSI:
+		return "16 colors"
+	default:
+		return "no colors"
+	}
+}
+
+func printDoctorReport(r *doctorReport) {
+	fmt.Println("┌─────────────────────────────────────────┐")
+	fmt.Println("│           Hopsule Doctor                │")
+	fmt.Println("└─────────────────────────────────────────┘")
+	fmt.Println()
+	fmt.Printf("  Version: %s (%s/%s)\n", r.Version, r.OS, r.Arch)
+	if r.Profile != "" {
+		fmt.Printf("  Profile: %s\n", r.Profile)
+	}
+	fmt.Println()
+
+	symbols := map[string]string{
+		checkPass: "✓",
+		checkWarn: "!",
+		checkFail: "✗",
+		checkSkip: "-",
+	}
+	for _, c := range r.Checks {
+		fmt.Printf("  %s %-11s %s\n", symbols[c.Status], c.Name, c.Message)
+	}
+
+	fmt.Println()
+	fmt.Printf("%d passed, %d warnings, %d failed\n", r.count(checkPass), r.count(checkWarn), r.count(checkFail))
+}
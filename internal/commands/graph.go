@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/Cagangedik/cli-tool/internal/graphexport"
+	"github.com/spf13/cobra"
+)
+
+// exportNodeTypes are the node types graph export draws
+var exportNodeTypes = []string{api.NodeDecision, api.NodeMemory, api.NodeTask, api.NodeCapsule}
+
+func NewGraphCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Work with the project's knowledge graph",
+	}
+
+	cmd.AddCommand(newGraphExportCommand())
+
+	return cmd
+}
+
+func newGraphExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export the knowledge graph as DOT, Mermaid, JSON or GraphML",
+		Long: `Write the project's knowledge graph for rendering in docs or CI.
+
+Decisions, memories, tasks and capsules are the nodes. The edges are the
+links between them: a memory's or task's related decisions and memories,
+the decisions and memories in a capsule, and the decision a decision
+supersedes.
+
+Filters keep the matching nodes and the edges between them:
+  --type     node types to keep (repeatable)
+  --tag      keep nodes with any of these tags; only decisions and memories
+             have tags (repeatable)
+  --status   keep nodes with one of these statuses; memories have no
+             status (repeatable, case-insensitive)
+
+Examples:
+  hopsule graph export > graph.dot
+  hopsule graph export --format mermaid --type decision --type capsule
+  hopsule graph export --status accepted --tag api --out docs/decisions.dot
+  hopsule graph export --format dot | dot -Tsvg -o graph.svg`,
+		Args: cobra.NoArgs,
+		RunE: runGraphExport,
+	}
+
+	cmd.Flags().String("format", "dot", "Output format: "+strings.Join(graphexport.Formats, ", "))
+	cmd.Flags().StringSlice("type", nil, "Only include nodes of this type: decision, memory, task, capsule (repeatable)")
+	cmd.Flags().StringSlice("tag", nil, "Only include nodes with this tag (repeatable)")
+	cmd.Flags().StringSlice("status", nil, "Only include nodes with this status (repeatable)")
+	cmd.Flags().String("out", "", "Write to this file instead of stdout")
+
+	return cmd
+}
+
+func runGraphExport(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	if !slices.Contains(graphexport.Formats, format) {
+		return fmt.Errorf("invalid format %q: must be one of %s", format, strings.Join(graphexport.Formats, ", "))
+	}
+	types, _ := cmd.Flags().GetStringSlice("type")
+	for _, t := range types {
+		if !slices.Contains(exportNodeTypes, t) {
+			return fmt.Errorf("invalid type %q: must be one of %s", t, strings.Join(exportNodeTypes, ", "))
+		}
+	}
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	statuses, _ := cmd.Flags().GetStringSlice("status")
+	out, _ := cmd.Flags().GetString("out")
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	projectID, err := resolveProjectID(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	client, err := newServiceFromFlags(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	g, err := api.LoadGraph(client, projectID)
+	if err != nil {
+		return err
+	}
+	g = g.Filter(func(n api.GraphNode) bool {
+		if len(types) > 0 && !slices.Contains(types, n.Type) {
+			return false
+		}
+		if len(statuses) > 0 && !slices.ContainsFunc(statuses, func(s string) bool { return strings.EqualFold(s, n.Status) }) {
+			return false
+		}
+		if len(tags) > 0 && !slices.ContainsFunc(tags, func(t string) bool { return slices.Contains(n.Tags, t) }) {
+			return false
+		}
+		return true
+	})
+
+	if out == "" {
+		return graphexport.Write(os.Stdout, g, format, projectID)
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", out, err)
+	}
+	if err := graphexport.Write(f, g, format, projectID); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", out, err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d nodes and %d edges to %s\n", len(g.Nodes), len(g.Edges), out)
+	return nil
+}
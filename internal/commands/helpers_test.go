@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/Cagangedik/cli-tool/internal/apitest"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/zalando/go-keyring"
+)
+
+// signIn points the config directory at a temporary directory and signs the
+// CLI in to srv
+func signIn(t *testing.T, srv *apitest.Server) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("HOPSULE_CONFIG_DIR", home)
+	keyring.MockInit()
+	if err := config.SaveConfig(srv.Config()); err != nil {
+		t.Fatal(err)
+	}
+	// Commands read the config loaded by an earlier test unless it is
+	// loaded again
+	if _, err := config.LoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// captureStdout returns what fn prints to stdout
+func captureStdout(t *testing.T, fn func() error) (string, error) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	runErr := fn()
+	w.Close()
+	return <-out, runErr
+}
@@ -30,25 +30,9 @@ decisions are submitted through the proper API channels.`,
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			projectID, _ := cmd.Flags().GetString("project")
-			if projectID == "" {
-				projectID = cfg.Project
-			}
-			if projectID == "" {
-				return fmt.Errorf("project ID is required (use --project or set in config)")
-			}
-
-			apiURL, _ := cmd.Flags().GetString("api-url")
-			if apiURL == "" {
-				apiURL = cfg.APIURL
-			}
-			if apiURL == "" {
-				return fmt.Errorf("API URL is required (use --api-url or set in config)")
-			}
-
-			token, _ := cmd.Flags().GetString("token")
-			if token == "" {
-				token = cfg.Token
+			projectID, err := resolveProjectID(cmd, cfg)
+			if err != nil {
+				return err
 			}
 
 			// Read the import file
@@ -63,9 +47,10 @@ decisions are submitted through the proper API channels.`,
 				return fmt.Errorf("failed to parse import file: %w", err)
 			}
 
-			client := api.NewClient(cfg).
-				WithBaseURL(apiURL).
-				WithToken(token)
+			client, err := newServiceFromFlags(cmd, cfg)
+			if err != nil {
+				return err
+			}
 
 			// Import each decision
 			imported := 0
@@ -81,6 +66,9 @@ decisions are submitted through the proper API channels.`,
 				}
 				imported++
 			}
+			if imported > 0 {
+				invalidateCache(cmd, cfg, projectID)
+			}
 
 			fmt.Printf("Successfully imported %d of %d decisions.\n", imported, len(importData.Decisions))
 			return nil
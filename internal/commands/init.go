@@ -4,11 +4,13 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/Cagangedik/cli-tool/internal/api"
 	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/Cagangedik/cli-tool/internal/localstore"
 	"github.com/spf13/cobra"
 )
 
@@ -25,25 +27,35 @@ The .hopsule file contains:
   - Project ID and slug
   - Organization ID and slug
 
-After initialization, CLI commands will automatically use this project.`,
+After initialization, CLI commands will automatically use this project.
+
+With --local no server is needed: .hopsule becomes a directory holding the
+project's decisions, memories, tasks and capsules as files you commit with
+your code. An existing .hopsule file is converted, keeping its project.
+Use 'hopsule push' to move the project to a server later.`,
 		RunE: runInit,
 	}
 
 	cmd.Flags().String("project", "", "Project ID to use (skip interactive selection)")
 	cmd.Flags().String("org", "", "Organization ID to use (skip interactive selection)")
 	cmd.Flags().Bool("force", false, "Overwrite existing .hopsule file")
+	cmd.Flags().Bool("local", false, "Store the project in files under .hopsule/ instead of on a server")
 
 	return cmd
 }
 
 func runInit(cmd *cobra.Command, args []string) error {
+	if local, _ := cmd.Flags().GetBool("local"); local {
+		return runInitLocal()
+	}
+
 	cfg, err := config.GetConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
 	if !cfg.IsAuthenticated() {
-		fmt.Println("Not logged in.")
+		printNotLoggedIn(cfg)
 		fmt.Println()
 		fmt.Println("Run 'hopsule login' to sign in first.")
 		return nil
@@ -233,3 +245,72 @@ func runInit(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// runInitLocal sets up the current directory for the local backend
+func runInitLocal() error {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	if path, ok := config.ProjectConfigFile(cwd); ok && filepath.Base(path) == config.HopsuleDirConfigName {
+		fmt.Println("┌─────────────────────────────────────────┐")
+		fmt.Println("│     Project Already Initialized         │")
+		fmt.Println("└─────────────────────────────────────────┘")
+		fmt.Println()
+		fmt.Printf("Found existing local project:\n")
+		fmt.Printf("  Path:    %s\n", filepath.Dir(path))
+		return nil
+	}
+
+	name := filepath.Base(cwd)
+	slug := localSlug(name)
+	projectID, err := localstore.NewProjectID(slug)
+	if err != nil {
+		return err
+	}
+	store, err := localstore.Init(cwd, &config.ProjectConfig{
+		Version: config.HopsuleFileVersion,
+		Project: config.ProjectInfo{
+			ID:   projectID,
+			Slug: slug,
+			Name: name,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("┌─────────────────────────────────────────┐")
+	fmt.Println("│     ✓ Local Project Initialized!        │")
+	fmt.Println("└─────────────────────────────────────────┘")
+	fmt.Println()
+	fmt.Printf("Project:      %s (%s)\n", store.Project.Name, store.Project.ID)
+	fmt.Printf("Stored in:    %s/\n", config.HopsuleFileName)
+	fmt.Println()
+	fmt.Println("Next steps:")
+	fmt.Println("  • Run 'hopsule create' to record a decision")
+	fmt.Println("  • Commit .hopsule/ so your team shares the same decisions")
+	fmt.Println("  • Run 'hopsule push --to <project-id>' to move to a server later")
+
+	return nil
+}
+
+// localSlug turns a directory name into a project slug
+func localSlug(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	if slug := strings.TrimSuffix(b.String(), "-"); slug != "" {
+		return slug
+	}
+	return "project"
+}
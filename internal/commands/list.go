@@ -5,7 +5,6 @@ import (
 	"os"
 	"text/tabwriter"
 
-	"github.com/Cagangedik/cli-tool/internal/api"
 	"github.com/Cagangedik/cli-tool/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -21,35 +20,30 @@ func NewListCommand() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			projectID, _ := cmd.Flags().GetString("project")
-			if projectID == "" {
-				projectID = cfg.Project
-			}
-			if projectID == "" {
-				return fmt.Errorf("project ID is required (use --project or set in config)")
+			projectID, err := resolveProjectID(cmd, cfg)
+			if err != nil {
+				return err
 			}
 
-			apiURL, _ := cmd.Flags().GetString("api-url")
-			if apiURL == "" {
-				apiURL = cfg.APIURL
-			}
-			if apiURL == "" {
-				return fmt.Errorf("API URL is required (use --api-url or set in config)")
+			client, err := newServiceFromFlags(cmd, cfg)
+			if err != nil {
+				return err
 			}
 
-			token, _ := cmd.Flags().GetString("token")
-			if token == "" {
-				token = cfg.Token
+			scopeKey, err := resolveFilterScope(cmd)
+			if err != nil {
+				return err
 			}
 
-			client := api.NewClient(cfg).
-				WithBaseURL(apiURL).
-				WithToken(token)
-
 			decisions, err := client.ListDecisions(projectID)
 			if err != nil {
 				return fmt.Errorf("failed to list decisions: %w", err)
 			}
+			decisions = filterDecisionsByScope(decisions, scopeKey)
+
+			if scopeKey != "" {
+				fmt.Printf("Scope: %s (plus project-wide decisions)\n\n", scopeKey)
+			}
 
 			if len(decisions) == 0 {
 				fmt.Println("No decisions found.")
@@ -57,14 +51,19 @@ func NewListCommand() *cobra.Command {
 			}
 
 			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
-			fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tCREATED")
-			fmt.Fprintln(w, "---\t-----\t------\t-------")
+			fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tSCOPE\tCREATED")
+			fmt.Fprintln(w, "---\t-----\t------\t-----\t-------")
 
 			for _, d := range decisions {
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
+				scope := d.ScopeKey
+				if scope == "" {
+					scope = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 					truncate(d.ID, 12),
 					truncate(d.Statement, 40),
 					d.Status,
+					truncate(scope, 16),
 					truncate(d.CreatedAt, 20),
 				)
 			}
@@ -73,6 +72,8 @@ func NewListCommand() *cobra.Command {
 		},
 	}
 
+	addScopeFilterFlags(cmd)
+
 	return cmd
 }
 
@@ -1,22 +1,21 @@
 package commands
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"runtime"
-	"time"
+	"os/signal"
+	"strings"
 
 	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/auth"
+	"github.com/Cagangedik/cli-tool/internal/browser"
 	"github.com/Cagangedik/cli-tool/internal/config"
 	"github.com/spf13/cobra"
 )
 
-const (
-	pollInterval    = 2 * time.Second
-	maxPollAttempts = 300 // 10 minutes / 2 seconds
-)
-
 func NewLoginCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "login",
@@ -28,13 +27,21 @@ This command will:
 2. Wait for you to complete sign-in
 3. Save your credentials locally
 
-After signing in, you can use all authenticated CLI commands.`,
+After signing in, you can use all authenticated CLI commands.
+
+Over SSH, in a container or without a display the browser is not opened;
+scan the QR code or open the printed URL on another device instead.
+
+To sign in with an existing token (e.g. in CI), pipe it on stdin:
+  echo "$HOPSULE_TOKEN" | hopsule login --with-token`,
 		RunE: runLogin,
 	}
 
 	cmd.Flags().String("api-url", "", "Override API URL")
 	cmd.Flags().String("web-url", "", "Override Web URL")
 	cmd.Flags().Bool("no-browser", false, "Don't open browser automatically")
+	cmd.Flags().Bool("with-token", false, "Read a token from standard input instead of signing in with the browser")
+	cmd.Flags().Bool("qr", false, "Always show the sign-in URL as a QR code")
 
 	return cmd
 }
@@ -45,13 +52,6 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		cfg = &config.Config{}
 	}
 
-	// Check if already logged in
-	if cfg.IsAuthenticated() {
-		fmt.Printf("Already logged in as %s (%s)\n", cfg.User.Name, cfg.User.Email)
-		fmt.Println("Use 'hopsule logout' to sign out first.")
-		return nil
-	}
-
 	// Override URLs if provided
 	apiURL, _ := cmd.Flags().GetString("api-url")
 	if apiURL != "" {
@@ -69,69 +69,73 @@ func runLogin(cmd *cobra.Command, args []string) error {
 		cfg.WebURL = "http://localhost:3000"
 	}
 
-	noBrowser, _ := cmd.Flags().GetBool("no-browser")
+	// A piped token replaces the current session, so scripts can switch
+	// tokens without logging out first
+	withToken, _ := cmd.Flags().GetBool("with-token")
+	if withToken {
+		return loginWithToken(cfg, cmd.InOrStdin())
+	}
 
-	// Create API client
-	client := api.NewClient(cfg)
+	// Check if already logged in
+	if cfg.IsAuthenticated() {
+		fmt.Printf("Already logged in as %s (%s)\n", cfg.User.Name, cfg.User.Email)
+		fmt.Println("Use 'hopsule logout' to sign out first.")
+		return nil
+	}
 
-	// Get device name
-	deviceName := getDeviceName()
+	noBrowser, _ := cmd.Flags().GetBool("no-browser")
+	showQR, _ := cmd.Flags().GetBool("qr")
+
+	headless, reason := browser.Headless()
+	if headless {
+		noBrowser = true
+	}
+	if noBrowser {
+		showQR = true
+	}
 
 	fmt.Println("┌─────────────────────────────────────────┐")
 	fmt.Println("│           Hopsule CLI Login             │")
 	fmt.Println("└─────────────────────────────────────────┘")
 	fmt.Println()
 
-	// Step 1: Initialize device auth
-	fmt.Print("Initializing login... ")
-	initResp, err := client.DeviceAuthInit(deviceName)
-	if err != nil {
-		fmt.Println("✗")
-		return fmt.Errorf("failed to initialize login: %w", err)
-	}
-	fmt.Println("✓")
-
-	// Step 2: Open browser
-	authURL := fmt.Sprintf("%s/auth/device?code=%s", cfg.WebURL, initResp.Code)
+	// Ctrl+C cancels the flow instead of killing the process mid-write
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-	fmt.Println()
-	fmt.Printf("Device Code: %s\n", initResp.Code)
-	fmt.Println()
+	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	spinnerIdx := 0
 
-	if !noBrowser {
-		fmt.Println("Opening browser to complete sign-in...")
-		if err := openBrowser(authURL); err != nil {
-			fmt.Printf("Could not open browser automatically.\n")
+	fmt.Print("Initializing login... ")
+	flow := auth.NewDeviceFlow(cfg)
+	result, err := flow.Run(ctx, func(ev auth.Event) {
+		switch ev.State {
+		case auth.StateInitiated:
+			fmt.Println("✓")
+			printDeviceCode(ev, noBrowser, showQR, headless, reason)
+		case auth.StateWaiting:
+			fmt.Printf("\r%s Waiting for browser authentication... (%ds)", spinner[spinnerIdx], int(ev.Elapsed.Seconds()))
+			spinnerIdx = (spinnerIdx + 1) % len(spinner)
+		case auth.StateComplete:
+			fmt.Printf("\r✓ Authentication complete!                    \n")
+		case auth.StateFailed:
+			if ev.Code == "" {
+				fmt.Println("✗")
+			} else {
+				fmt.Println()
+			}
+		default:
+			fmt.Println()
 		}
-	}
-
-	fmt.Println()
-	fmt.Println("If the browser doesn't open, visit this URL:")
-	fmt.Printf("  %s\n", authURL)
-	fmt.Println()
-
-	// Step 3: Poll for completion
-	fmt.Println("Waiting for authentication...")
-	fmt.Println("(Press Ctrl+C to cancel)")
-	fmt.Println()
-
-	token, userInfo, err := pollForCompletion(client, initResp.Code)
+	})
 	if err != nil {
 		return err
 	}
 
-	// Step 4: Save config
-	cfg.Token = token
-	cfg.User = &config.User{
-		ID:        userInfo.UserID,
-		Email:     userInfo.Email,
-		Name:      userInfo.Name,
-		AvatarURL: userInfo.AvatarURL,
-	}
-
-	if err := config.SaveConfig(cfg); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	if err := auth.Save(cfg, result); err != nil {
+		return err
 	}
+	userInfo := result.User
 
 	fmt.Println()
 	fmt.Println("┌─────────────────────────────────────────┐")
@@ -149,74 +153,103 @@ func runLogin(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func pollForCompletion(client *api.Client, code string) (string, *api.DeviceAuthPollResponse, error) {
-	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-	spinnerIdx := 0
+// loginWithToken reads a token from r, checks it against the API and saves it
+func loginWithToken(cfg *config.Config, r io.Reader) error {
+	token, err := readToken(r)
+	if err != nil {
+		return err
+	}
 
-	for attempt := 0; attempt < maxPollAttempts; attempt++ {
-		// Show spinner
-		fmt.Printf("\r%s Waiting for browser authentication... (%ds)", spinner[spinnerIdx], attempt*2)
-		spinnerIdx = (spinnerIdx + 1) % len(spinner)
+	client := api.NewClient(cfg).WithToken(token)
+	me, err := client.GetMe()
+	if err != nil {
+		return fmt.Errorf("failed to validate token: %w", err)
+	}
 
-		resp, err := client.DeviceAuthPoll(code)
-		if err != nil {
-			fmt.Println()
-			return "", nil, fmt.Errorf("failed to check login status: %w", err)
-		}
+	service := me.IsServiceToken() && me.APIToken != nil
 
-		switch resp.Status {
-		case "complete":
-			fmt.Printf("\r✓ Authentication complete!                    \n")
-			return resp.Token, resp, nil
-		case "expired":
-			fmt.Println()
-			return "", nil, fmt.Errorf("login session expired - please try again")
-		case "pending":
-			// Continue polling
-		default:
-			fmt.Println()
-			return "", nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	cfg.Token = token
+	cfg.RefreshToken = ""
+	cfg.TokenExpiresAt = ""
+	switch {
+	case service:
+		cfg.User = &config.User{
+			ID:   me.APIToken.ID,
+			Name: me.APIToken.Name,
+		}
+	case me.User != nil:
+		cfg.User = &config.User{
+			ID:        me.User.ID,
+			Email:     me.User.Email,
+			Name:      me.User.Name,
+			AvatarURL: me.User.AvatarURL,
 		}
+	default:
+		return fmt.Errorf("failed to validate token: no user returned")
+	}
 
-		time.Sleep(pollInterval)
+	config.Default().Persist(cfg, "api_url", "token")
+	if err := config.SaveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
 	}
 
-	fmt.Println()
-	return "", nil, fmt.Errorf("login timed out after %d minutes", maxPollAttempts*int(pollInterval.Seconds())/60)
+	if service {
+		fmt.Printf("✓ Signed in with service token: %s (project %s)\n", me.APIToken.Name, me.APIToken.ProjectID)
+	} else {
+		fmt.Printf("✓ Signed in as: %s (%s)\n", me.User.Name, me.User.Email)
+	}
+	return nil
 }
 
-func openBrowser(url string) error {
-	var cmd *exec.Cmd
-
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", url)
-	case "linux":
-		cmd = exec.Command("xdg-open", url)
-	case "windows":
-		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+// readToken reads the first non-empty line of r
+func readToken(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if token := strings.TrimSpace(scanner.Text()); token != "" {
+			return token, nil
+		}
 	}
-
-	return cmd.Start()
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	return "", fmt.Errorf("no token provided on standard input")
 }
 
-func getDeviceName() string {
-	hostname, err := os.Hostname()
-	if err != nil {
-		hostname = "unknown"
+// printDeviceCode tells the user where to approve the device code, opening
+// the browser unless there is none
+func printDeviceCode(ev auth.Event, noBrowser, showQR, headless bool, reason string) {
+	fmt.Println()
+	fmt.Printf("Device Code: %s\n", ev.Code)
+	fmt.Println()
+
+	if !noBrowser {
+		fmt.Println("Opening browser to complete sign-in...")
+		if err := browser.Open(ev.URL); err != nil {
+			fmt.Printf("Could not open browser automatically.\n")
+		}
+	} else if headless {
+		fmt.Printf("No browser available (%s).\n", reason)
+	}
+
+	if showQR {
+		if qr, err := browser.QRCode(ev.URL); err == nil {
+			fmt.Println()
+			fmt.Println("Scan this code with your phone to sign in:")
+			fmt.Println()
+			fmt.Println(qr)
+		}
 	}
 
-	osName := runtime.GOOS
-	switch osName {
-	case "darwin":
-		osName = "macOS"
-	case "linux":
-		osName = "Linux"
-	case "windows":
-		osName = "Windows"
+	fmt.Println()
+	if noBrowser {
+		fmt.Println("Open this URL on any device to sign in:")
+	} else {
+		fmt.Println("If the browser doesn't open, visit this URL:")
 	}
+	fmt.Printf("  %s\n", ev.URL)
+	fmt.Println()
 
-	return fmt.Sprintf("CLI on %s (%s)", osName, hostname)
+	fmt.Println("Waiting for authentication...")
+	fmt.Println("(Press Ctrl+C to cancel)")
+	fmt.Println()
 }
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Cagangedik/cli-tool/internal/apitest"
+	"github.com/Cagangedik/cli-tool/internal/config"
+)
+
+func TestLoginWithTokenReplacesSession(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	signIn(t, srv)
+
+	cmd := NewLoginCommand()
+	cmd.SetArgs([]string{"--with-token"})
+	cmd.SetIn(strings.NewReader(apitest.Token + "\n"))
+	cmd.SilenceUsage = true
+	out, err := captureStdout(t, cmd.Execute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "Already logged in") {
+		t.Fatalf("the piped token was ignored:\n%s", out)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Token != apitest.Token || cfg.RefreshToken != "" {
+		t.Errorf("saved token %q and refresh token %q, want the piped token alone", cfg.Token, cfg.RefreshToken)
+	}
+}
@@ -2,7 +2,9 @@ package commands
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/Cagangedik/cli-tool/internal/api"
 	"github.com/Cagangedik/cli-tool/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -13,11 +15,16 @@ func NewLogoutCommand() *cobra.Command {
 		Short: "Sign out from Hopsule",
 		Long: `Sign out from Hopsule and clear stored credentials.
 
-This will remove your authentication token from local storage.
-You will need to run 'hopsule login' again to use authenticated commands.`,
+This revokes your token on the server and removes it from local storage.
+You will need to run 'hopsule login' again to use authenticated commands.
+
+Service tokens are only removed locally; revoke them with
+'hopsule auth token revoke'.`,
 		RunE: runLogout,
 	}
 
+	cmd.Flags().Bool("local", false, "Only remove local credentials without revoking the token on the server")
+
 	return cmd
 }
 
@@ -28,7 +35,7 @@ func runLogout(cmd *cobra.Command, args []string) error {
 	}
 
 	// Check if logged in
-	if !cfg.IsAuthenticated() {
+	if cfg.Token == "" {
 		fmt.Println("You are not currently logged in.")
 		return nil
 	}
@@ -41,6 +48,18 @@ func runLogout(cmd *cobra.Command, args []string) error {
 		userEmail = cfg.User.Email
 	}
 
+	// Revoke the token server-side so a copied config can't be reused. A
+	// failure here shouldn't keep the user signed in locally.
+	revoked := false
+	localOnly, _ := cmd.Flags().GetBool("local")
+	if !localOnly && api.TokenTypeOf(cfg.Token) == api.TokenTypeUser && !cfg.SessionExpired() {
+		if err := api.NewClient(cfg).Logout(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not revoke token on the server: %v\n", err)
+		} else {
+			revoked = true
+		}
+	}
+
 	// Clear auth data
 	if err := config.ClearAuth(cfg); err != nil {
 		return fmt.Errorf("failed to clear credentials: %w", err)
@@ -57,6 +76,9 @@ func runLogout(cmd *cobra.Command, args []string) error {
 	}
 	fmt.Println()
 	fmt.Println("Your credentials have been removed from this device.")
+	if revoked {
+		fmt.Println("The token has been revoked on the server.")
+	}
 	fmt.Println("Run 'hopsule login' to sign in again.")
 
 	return nil
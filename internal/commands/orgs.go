@@ -5,7 +5,6 @@ import (
 	"os"
 	"text/tabwriter"
 
-	"github.com/Cagangedik/cli-tool/internal/api"
 	"github.com/Cagangedik/cli-tool/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -32,14 +31,17 @@ func runOrgs(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	if !cfg.IsAuthenticated() {
-		fmt.Println("Not logged in.")
+	if !cfg.IsAuthenticated() && config.ResolveBackend(cfg) == config.BackendHTTP {
+		printNotLoggedIn(cfg)
 		fmt.Println()
 		fmt.Println("Run 'hopsule login' to sign in first.")
 		return nil
 	}
 
-	client := api.NewClient(cfg)
+	client, err := newServiceFromFlags(cmd, cfg)
+	if err != nil {
+		return err
+	}
 
 	fmt.Println("Fetching organizations...")
 	fmt.Println()
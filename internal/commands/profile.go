@@ -0,0 +1,172 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/spf13/cobra"
+)
+
+func NewProfileCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage named config profiles",
+		Long: `Manage named config profiles.
+
+Each profile holds its own API URL, web URL, token, user and default
+organization/project, so you can switch between environments or accounts
+without logging in again.
+
+The active profile is chosen by --profile, then HOPSULE_PROFILE, then the
+profile selected with 'hopsule profile use'.`,
+	}
+
+	cmd.AddCommand(newProfileListCommand())
+	cmd.AddCommand(newProfileUseCommand())
+	cmd.AddCommand(newProfileAddCommand())
+	cmd.AddCommand(newProfileRemoveCommand())
+
+	return cmd
+}
+
+func newProfileListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List config profiles",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := config.ListProfiles()
+			if err != nil {
+				return fmt.Errorf("failed to load profiles: %w", err)
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+			fmt.Fprintln(w, "\tNAME\tAPI URL\tUSER\tPROJECT")
+			fmt.Fprintln(w, "\t────\t───────\t────\t───────")
+			for _, p := range profiles {
+				marker := " "
+				if p.Current {
+					marker = "*"
+				}
+				user := "-"
+				if p.Config.User != nil {
+					user = p.Config.User.Email
+				}
+				apiURL := p.Config.APIURL
+				if apiURL == "" {
+					apiURL = p.Config.GetAPIURL()
+				}
+				project := p.Config.Project
+				if project == "" {
+					project = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", marker, p.Name, apiURL, user, truncateID(project))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newProfileUseCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Switch the current profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.UseProfile(args[0]); err != nil {
+				return fmt.Errorf("failed to switch profile: %w", err)
+			}
+			fmt.Printf("Switched to profile %s.\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newProfileAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Create a new profile",
+		Long: `Create a new profile.
+
+Settings not given as flags are left empty (or copied with --from; the
+sign-in of the copied profile is not). Run 'hopsule login --profile <name>'
+afterwards to sign in with it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			c := &config.Config{}
+
+			from, _ := cmd.Flags().GetString("from")
+			if from != "" {
+				profiles, err := config.ListProfiles()
+				if err != nil {
+					return fmt.Errorf("failed to load profiles: %w", err)
+				}
+				found := false
+				for _, p := range profiles {
+					if p.Name == from {
+						// Copy the settings but not the sign-in: the new
+						// profile logs in on its own
+						*c = *p.Config
+						c.Token = ""
+						c.RefreshToken = ""
+						c.TokenExpiresAt = ""
+						c.TokenRef = ""
+						c.User = nil
+						found = true
+					}
+				}
+				if !found {
+					return fmt.Errorf("profile %q does not exist", from)
+				}
+			}
+
+			if v, _ := cmd.Flags().GetString("api-url"); v != "" {
+				c.APIURL = v
+			}
+			if v, _ := cmd.Flags().GetString("web-url"); v != "" {
+				c.WebURL = v
+			}
+			if v, _ := cmd.Flags().GetString("org"); v != "" {
+				c.Organization = v
+			}
+			if v, _ := cmd.Flags().GetString("project"); v != "" {
+				c.Project = v
+			}
+
+			if err := config.AddProfile(name, c); err != nil {
+				return fmt.Errorf("failed to add profile: %w", err)
+			}
+
+			fmt.Printf("Profile %s created.\n", name)
+			fmt.Printf("Run 'hopsule login --profile %s' to sign in.\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().String("api-url", "", "API URL for this profile")
+	cmd.Flags().String("web-url", "", "Web URL for this profile")
+	cmd.Flags().String("org", "", "Default organization ID")
+	cmd.Flags().String("from", "", "Copy URLs and defaults from an existing profile")
+
+	return cmd
+}
+
+func newProfileRemoveCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "rm <name>",
+		Aliases: []string{"remove"},
+		Short:   "Delete a profile and its stored credentials",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.RemoveProfile(args[0]); err != nil {
+				return fmt.Errorf("failed to remove profile: %w", err)
+			}
+			fmt.Printf("Profile %s removed.\n", args[0])
+			return nil
+		},
+	}
+}
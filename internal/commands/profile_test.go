@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Cagangedik/cli-tool/internal/apitest"
+	"github.com/Cagangedik/cli-tool/internal/config"
+)
+
+func TestProfileAddFromCopiesSettingsButNotSignIn(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	signIn(t, srv)
+
+	// With the plaintext store the whole sign-in is in the profile
+	path, err := config.ConfigFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := "credential_store: plaintext\nprofiles:\n  default:\n" +
+		"    api_url: " + srv.URL + "\n    project: " + apitest.ProjectID + "\n" +
+		"    token: " + apitest.Token + "\n    refresh_token: " + apitest.RefreshToken + "\n" +
+		"    token_expires_at: \"2099-01-01T00:00:00Z\"\n    user:\n      id: user-1\n"
+	if err := os.WriteFile(path, []byte(file), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newProfileAddCommand()
+	cmd.SetArgs([]string{"staging", "--from", config.DefaultProfile})
+	if _, err := captureStdout(t, cmd.Execute); err != nil {
+		t.Fatal(err)
+	}
+
+	store := config.NewStore("")
+	store.SetProfile("staging")
+	c, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.APIURL != srv.URL || c.Project != apitest.ProjectID {
+		t.Errorf("settings weren't copied: %+v", c)
+	}
+	if c.Token != "" || c.RefreshToken != "" || c.TokenExpiresAt != "" || c.TokenRef != "" || c.User != nil {
+		t.Errorf("sign-in was copied: %+v", c)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), apitest.RefreshToken); n != 1 {
+		t.Errorf("the refresh token is in the config file %d times, want once:\n%s", n, data)
+	}
+}
@@ -0,0 +1,365 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/Cagangedik/cli-tool/internal/localstore"
+	"github.com/spf13/cobra"
+)
+
+func NewPushCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push",
+		Short: "Copy a local project to a server project",
+		Long: `Copy the decisions, memories and tasks of a local project (see
+'hopsule init --local') to a project on the server.
+
+Decisions keep their status: accepted and deprecated decisions are accepted
+(and deprecated) on the server after they are created. Links between items
+are kept. Items that were already pushed to the project are recorded in
+.hopsule/push.yaml and skipped, so an interrupted push can be run again.
+An item whose status couldn't be set is finished on the next run instead
+of being created twice.
+
+Capsules can't be created through the API and are not pushed.
+
+Examples:
+  hopsule push --to proj-123 --dry-run
+  hopsule push --to proj-123
+  hopsule push --to proj-123 --switch`,
+		RunE: runPush,
+	}
+
+	cmd.Flags().String("to", "", "Server project ID to push to (required)")
+	cmd.Flags().Bool("dry-run", false, "Show what would be pushed without changing anything")
+	cmd.Flags().Bool("switch", false, "Use the server project in this directory after pushing")
+	cmd.MarkFlagRequired("to")
+
+	return cmd
+}
+
+func runPush(cmd *cobra.Command, args []string) error {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+	store, err := localstore.Open(cwd)
+	if err != nil {
+		return err
+	}
+
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" && !cfg.IsAuthenticated() {
+		printNotLoggedIn(cfg)
+		fmt.Println()
+		fmt.Println("Run 'hopsule login' to sign in first.")
+		return nil
+	}
+	client, err := newClientFromFlags(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	target, _ := cmd.Flags().GetString("to")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	switchProject, _ := cmd.Flags().GetBool("switch")
+
+	// Make sure the target exists before creating anything in it
+	meResp, err := client.GetMe()
+	if err != nil {
+		return fmt.Errorf("failed to fetch projects: %w", err)
+	}
+	var targetProject *api.Project
+	for _, proj := range meResp.Projects {
+		if proj.ID == target {
+			targetProject = proj
+		}
+	}
+	if targetProject == nil {
+		return fmt.Errorf("project %s not found (run 'hopsule projects' to list yours)", target)
+	}
+
+	localID := store.Project.ID
+	decisions, err := store.ListDecisions(localID)
+	if err != nil {
+		return err
+	}
+	memories, err := store.ListMemories(localID)
+	if err != nil {
+		return err
+	}
+	tasks, err := store.ListTasks(localID)
+	if err != nil {
+		return err
+	}
+	capsules, err := store.ListCapsules(localID)
+	if err != nil {
+		return err
+	}
+	pushed, pending, err := store.Pushed(target)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("┌─────────────────────────────────────────┐")
+	fmt.Println("│           Push to Server                │")
+	fmt.Println("└─────────────────────────────────────────┘")
+	fmt.Println()
+	fmt.Printf("From: %s (%s)\n", store.Project.Name, store.Dir)
+	fmt.Printf("To:   %s (%s)\n", targetProject.Name, targetProject.ID)
+	if dryRun {
+		fmt.Println("Mode: dry run, nothing is changed")
+	}
+	fmt.Println()
+
+	p := &pusher{client: client, store: store, target: target, pushed: pushed, pending: pending, dryRun: dryRun}
+	for _, d := range decisions {
+		p.pushDecision(d)
+	}
+	for _, m := range memories {
+		p.pushMemory(m)
+	}
+	for _, t := range tasks {
+		p.pushTask(t)
+	}
+
+	fmt.Println()
+	verb := "Pushed"
+	if dryRun {
+		verb = "Would push"
+	}
+	fmt.Printf("%s %d item(s), %d already pushed, %d failed.\n", verb, p.created, p.skipped, p.failed)
+	if len(capsules) > 0 {
+		fmt.Printf("Skipped %d capsule(s): the API can't create capsules, recreate them on the server.\n", len(capsules))
+	}
+	if p.failed > 0 {
+		return fmt.Errorf("%d item(s) failed to push; run 'hopsule push --to %s' again to retry", p.failed, target)
+	}
+
+	if switchProject && !dryRun {
+		projectCfg, _, err := config.LoadProjectConfigFrom(cwd)
+		if err != nil {
+			return err
+		}
+		projectCfg.Backend = ""
+		projectCfg.Project = config.ProjectInfo{
+			ID:   targetProject.ID,
+			Slug: targetProject.Slug,
+			Name: targetProject.Name,
+		}
+		for _, org := range meResp.Organizations {
+			if org.ID == targetProject.OrganizationID {
+				projectCfg.Project.Organization = config.OrganizationInfo{ID: org.ID, Slug: org.Slug, Name: org.Name}
+			}
+		}
+		if err := config.SaveProjectConfig(filepath.Dir(store.Dir), projectCfg); err != nil {
+			return fmt.Errorf("failed to save project config: %w", err)
+		}
+		fmt.Println()
+		fmt.Printf("This directory now uses %s on the server.\n", targetProject.Name)
+		fmt.Printf("The decisions, memories, tasks and capsules directories and push.yaml in\n%s are no longer read and can be removed. Keep %s:\nit links this directory to the server project.\n",
+			store.Dir, config.HopsuleDirConfigName)
+	}
+
+	return nil
+}
+
+// pusher creates local items on the server, mapping local IDs to the new
+// server IDs as it goes
+type pusher struct {
+	client  *api.Client
+	store   *localstore.Store
+	target  string
+	pushed  map[string]string // local ID -> server ID
+	pending map[string]bool   // pushed, but the status isn't set yet
+	dryRun  bool
+
+	created, skipped, failed int
+}
+
+func (p *pusher) pushDecision(d api.Decision) {
+	if p.skip(d.ID) {
+		return
+	}
+	remoteID, resume := p.pushed[d.ID]
+	if resume {
+		fmt.Printf("  ~ decision %s  setting status of %s [%s]\n", d.ID, remoteID, d.Status)
+	} else {
+		fmt.Printf("  + decision %s  %s [%s]\n", d.ID, truncate(d.Statement, 40), d.Status)
+	}
+	if p.dryRun {
+		p.done(d.ID, d.ID)
+		return
+	}
+
+	var remote *api.Decision
+	var err error
+	if resume {
+		if remote, err = p.client.GetDecision(p.target, remoteID); err != nil {
+			p.fail(fmt.Errorf("failed to fetch %s: %w", remoteID, err))
+			return
+		}
+	} else {
+		req := api.CreateDecisionRequest{Statement: d.Statement, Rationale: d.Rationale, Tags: d.Tags}
+		if d.ScopeKey != "" {
+			req.ScopeKey = &d.ScopeKey
+		}
+		if remote, err = p.client.CreateDecision(p.target, req); err != nil {
+			p.fail(err)
+			return
+		}
+		needsStatus := d.Status == localstore.StatusAccepted || d.Status == localstore.StatusDeprecated
+		if !p.record(d.ID, remote.ID, needsStatus) {
+			return
+		}
+	}
+
+	// Replay the transitions that lead from the server status to the local one
+	status := remote.Status
+	switch d.Status {
+	case localstore.StatusAccepted, localstore.StatusDeprecated:
+		if status == localstore.StatusDraft || status == localstore.StatusPending {
+			if _, err := p.client.AcceptDecision(p.target, remote.ID); err != nil {
+				p.fail(fmt.Errorf("created as %s but failed to accept: %w", remote.ID, err))
+				return
+			}
+			status = localstore.StatusAccepted
+		}
+		if d.Status == localstore.StatusDeprecated && status == localstore.StatusAccepted {
+			if _, err := p.client.DeprecateDecision(p.target, remote.ID); err != nil {
+				p.fail(fmt.Errorf("created as %s but failed to deprecate: %w", remote.ID, err))
+				return
+			}
+		}
+	case localstore.StatusPending, localstore.StatusRejected:
+		fmt.Printf("    note: the API can't set %s; it is a draft on the server\n", d.Status)
+	}
+	p.finish(d.ID, remote.ID)
+}
+
+func (p *pusher) pushMemory(m *api.Memory) {
+	if p.skip(m.ID) {
+		return
+	}
+	fmt.Printf("  + memory   %s  %s\n", m.ID, truncate(m.Content, 40))
+	if p.dryRun {
+		p.done(m.ID, m.ID)
+		return
+	}
+
+	remote, err := p.client.CreateMemory(p.target, api.CreateMemoryRequest{
+		Content:            m.Content,
+		Tags:               m.Tags,
+		RelatedDecisionIds: p.mapIDs(m.RelatedDecisionIds),
+	})
+	if err != nil {
+		p.fail(err)
+		return
+	}
+	if p.record(m.ID, remote.ID, false) {
+		p.created++
+	}
+}
+
+func (p *pusher) pushTask(t *api.Task) {
+	if p.skip(t.ID) {
+		return
+	}
+	remoteID, resume := p.pushed[t.ID]
+	if resume {
+		fmt.Printf("  ~ task     %s  setting status of %s [%s]\n", t.ID, remoteID, t.Status)
+	} else {
+		fmt.Printf("  + task     %s  %s [%s]\n", t.ID, truncate(t.Title, 40), t.Status)
+	}
+	if p.dryRun {
+		p.done(t.ID, t.ID)
+		return
+	}
+
+	if !resume {
+		remote, err := p.client.CreateTask(p.target, api.CreateTaskRequest{
+			Title:              t.Title,
+			Description:        t.Description,
+			Priority:           t.Priority,
+			RelatedDecisionIds: p.mapIDs(t.RelatedDecisionIds),
+			RelatedMemoryIds:   p.mapIDs(t.RelatedMemoryIds),
+		})
+		if err != nil {
+			p.fail(err)
+			return
+		}
+		resume = t.Status != "" && t.Status != remote.Status
+		if !p.record(t.ID, remote.ID, resume) {
+			return
+		}
+		remoteID = remote.ID
+	}
+	if resume {
+		if _, err := p.client.UpdateTask(p.target, remoteID, api.UpdateTaskRequest{Status: t.Status}); err != nil {
+			p.fail(fmt.Errorf("created as %s but failed to set status: %w", remoteID, err))
+			return
+		}
+	}
+	p.finish(t.ID, remoteID)
+}
+
+// skip reports whether an item is already on the server with its status set
+func (p *pusher) skip(localID string) bool {
+	if _, ok := p.pushed[localID]; ok && !p.pending[localID] {
+		p.skipped++
+		return true
+	}
+	return false
+}
+
+// record saves the server ID of a new item so it is never pushed twice.
+// pending marks that its status still has to be set, so a failed
+// transition is retried on the next run.
+func (p *pusher) record(localID, remoteID string, pending bool) bool {
+	if err := p.store.SetPushed(p.target, localID, remoteID, pending); err != nil {
+		p.fail(fmt.Errorf("created as %s but failed to record it: %w", remoteID, err))
+		return false
+	}
+	p.pushed[localID] = remoteID
+	p.pending[localID] = pending
+	return true
+}
+
+// finish counts an item whose status is set, clearing its pending mark
+func (p *pusher) finish(localID, remoteID string) {
+	if p.pending[localID] && !p.record(localID, remoteID, false) {
+		return
+	}
+	p.created++
+}
+
+func (p *pusher) done(localID, remoteID string) {
+	p.pushed[localID] = remoteID
+	p.created++
+}
+
+func (p *pusher) fail(err error) {
+	fmt.Fprintf(os.Stderr, "    Warning: %v\n", err)
+	p.failed++
+}
+
+// mapIDs returns the server IDs of linked items. Links to items that
+// failed to push are dropped.
+func (p *pusher) mapIDs(ids []string) []string {
+	var mapped []string
+	for _, id := range ids {
+		if remote, ok := p.pushed[id]; ok {
+			mapped = append(mapped, remote)
+		}
+	}
+	return mapped
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/apitest"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/Cagangedik/cli-tool/internal/localstore"
+)
+
+// newLocalProject creates a local-backend project in a temporary directory,
+// makes it the working directory and points the CLI at srv
+func newLocalProject(t *testing.T, srv *apitest.Server) *localstore.Store {
+	t.Helper()
+	signIn(t, srv)
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	store, err := localstore.Init(dir, &config.ProjectConfig{Project: config.ProjectInfo{ID: "local-1", Name: "Local"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func runPushTo(t *testing.T, target string) error {
+	t.Helper()
+	cmd := NewPushCommand()
+	cmd.SetArgs([]string{"--to", target})
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return cmd.Execute()
+}
+
+func TestPushRetriesFailedTransition(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	store := newLocalProject(t, srv)
+
+	d, err := store.CreateDecision(store.Project.ID, api.CreateDecisionRequest{Statement: "Use Postgres"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.AcceptDecision(store.Project.ID, d.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.Inject(apitest.Fault{Method: http.MethodPost, Path: "/decisions/accept", Status: http.StatusInternalServerError, Times: 1})
+	if err := runPushTo(t, apitest.ProjectID); err == nil {
+		t.Fatal("first push succeeded despite the failed accept")
+	}
+	if err := runPushTo(t, apitest.ProjectID); err != nil {
+		t.Fatalf("second push: %v", err)
+	}
+
+	client := api.NewClient(srv.Config())
+	decisions, err := client.ListDecisions(apitest.ProjectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 1 {
+		t.Fatalf("server has %d decisions, want 1", len(decisions))
+	}
+	if decisions[0].Status != "ACCEPTED" {
+		t.Errorf("server decision is %s, want ACCEPTED", decisions[0].Status)
+	}
+
+	_, pending, err := store.Pushed(apitest.ProjectID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("pending after a successful push: %v", pending)
+	}
+}
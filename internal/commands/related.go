@@ -0,0 +1,195 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// graphNodeTypes are the node types --type accepts
+var graphNodeTypes = []string{api.NodeDecision, api.NodeMemory, api.NodeTask, api.NodeCapsule, api.NodeCodeChunk}
+
+// defaultPathDepth is the longest path searched with --to unless --depth
+// is given
+const defaultPathDepth = 3
+
+func NewRelatedCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "related <id>",
+		Short: "Show what is connected to an item in the knowledge graph",
+		Long: `List the decisions, memories, tasks, capsules and code chunks connected
+to an item in the project's knowledge graph, nearest first.
+
+Each result shows how far away it is, the type of the edge it was reached by
+and the item on the other end of that edge. --depth follows edges further
+out (up to 5); --type limits the results to some node types and --edge-type
+limits the edges followed.
+
+With --to, the paths between the two items are shown instead, shortest
+first, up to --depth edges long (default 3).
+
+Examples:
+  hopsule related dec-123
+  hopsule related dec-123 --depth 2 --type task --type code_chunk
+  hopsule related mem-456 --edge-type memory_decision
+  hopsule related dec-123 --to task-789
+  hopsule related dec-123 -o json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runRelated,
+	}
+
+	cmd.Flags().Int("depth", 1, "How many edges away to look")
+	cmd.Flags().StringSlice("type", nil, "Only show nodes of this type: decision, memory, task, capsule, code_chunk (repeatable)")
+	cmd.Flags().StringSlice("edge-type", nil, "Only follow edges of this type, e.g. memory_decision (repeatable)")
+	cmd.Flags().String("to", "", "Show the paths to this item instead")
+	cmd.Flags().StringP("output", "o", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+func runRelated(cmd *cobra.Command, args []string) error {
+	nodeID := args[0]
+	depth, _ := cmd.Flags().GetInt("depth")
+	to, _ := cmd.Flags().GetString("to")
+	if to != "" && !cmd.Flags().Changed("depth") {
+		depth = defaultPathDepth
+	}
+	if depth < 1 || depth > api.MaxGraphDepth {
+		return fmt.Errorf("--depth must be between 1 and %d", api.MaxGraphDepth)
+	}
+	types, _ := cmd.Flags().GetStringSlice("type")
+	for _, t := range types {
+		if !slices.Contains(graphNodeTypes, t) {
+			return fmt.Errorf("invalid type %q: must be one of %s", t, strings.Join(graphNodeTypes, ", "))
+		}
+	}
+	edgeTypes, _ := cmd.Flags().GetStringSlice("edge-type")
+	output, _ := cmd.Flags().GetString("output")
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	projectID, err := resolveProjectID(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	client, err := newServiceFromFlags(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	if to != "" {
+		paths, err := client.GetGraphPaths(projectID, nodeID, to, depth)
+		if err != nil {
+			return fmt.Errorf("failed to find paths: %w", err)
+		}
+		if output == "json" {
+			return printJSON(api.GraphPathsResponse{Paths: paths})
+		}
+		printGraphPaths(nodeID, to, depth, paths)
+		return nil
+	}
+
+	result, err := client.GetGraphNeighbors(projectID, nodeID, api.NeighborOptions{
+		Depth:     depth,
+		NodeTypes: types,
+		EdgeTypes: edgeTypes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get related items: %w", err)
+	}
+	if output == "json" {
+		return printJSON(result)
+	}
+
+	fmt.Println(graphNodeLine(result.Node))
+	fmt.Println()
+	if len(result.Neighbors) == 0 {
+		fmt.Printf("Nothing related within %d edge(s).\n", depth)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "DEPTH\tTYPE\tID\tEDGE\tVIA\tLABEL")
+	fmt.Fprintln(w, "-----\t----\t--\t----\t---\t-----")
+	for _, n := range result.Neighbors {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			n.Depth,
+			n.Node.Type,
+			n.Node.ID,
+			n.Edge.Type,
+			n.Edge.Other(n.Node.ID),
+			truncate(graphNodeLabel(n.Node), 50),
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("\n%d related item(s) within %d edge(s).\n", len(result.Neighbors), depth)
+	return nil
+}
+
+// printGraphPaths shows each path as a column of nodes joined by their edges
+func printGraphPaths(from, to string, depth int, paths []api.GraphPath) {
+	if len(paths) == 0 {
+		fmt.Printf("No path from %s to %s within %d edge(s).\n", from, to, depth)
+		return
+	}
+
+	for i, path := range paths {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("Path %d (%d edge(s)):\n", i+1, len(path.Edges))
+		for j, node := range path.Nodes {
+			fmt.Printf("  %s\n", graphNodeLine(node))
+			if j < len(path.Edges) {
+				e := path.Edges[j]
+				arrow := "↓"
+				if e.Source != node.ID {
+					arrow = "↑"
+				}
+				fmt.Printf("  │ %s %s\n", arrow, e.Type)
+			}
+		}
+	}
+	fmt.Printf("\n%d path(s) from %s to %s.\n", len(paths), from, to)
+}
+
+// graphNodeLine describes a node on one line: type, ID, label and status
+func graphNodeLine(n api.GraphNode) string {
+	line := fmt.Sprintf("%-10s %s  %s", n.Type, n.ID, truncate(graphNodeLabel(n), 60))
+	if n.Status != "" {
+		line += "  [" + n.Status + "]"
+	}
+	return line
+}
+
+// graphNodeLabel is the first line of a node's label, or a code chunk's
+// path when it has no label
+func graphNodeLabel(n api.GraphNode) string {
+	label, _, _ := strings.Cut(n.Label, "\n")
+	if label == "" {
+		label = n.Path
+	}
+	return label
+}
+
+func printJSON(v any) error {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode output: %w", err)
+	}
+	fmt.Println(string(jsonData))
+	return nil
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// resolveProjectID picks the project for a command: the --project flag, then
+// the .hopsule file (or workspace mapping) for the current directory, then
+// the default project from the global config
+func resolveProjectID(cmd *cobra.Command, cfg *config.Config) (string, error) {
+	projectID, _ := cmd.Flags().GetString("project")
+	if projectID != "" {
+		return projectID, nil
+	}
+
+	resolved, err := config.ResolveCurrentProject()
+	if err != nil && !errors.Is(err, config.ErrProjectConfigNotFound) {
+		return "", err
+	}
+	if resolved != nil {
+		return resolved.Project.ID, nil
+	}
+
+	if cfg.Project != "" {
+		return cfg.Project, nil
+	}
+	return "", fmt.Errorf("project ID is required (use --project, run 'hopsule init' or set in config)")
+}
+
+// newClientFromFlags builds an API client from the config, honouring the
+// --api-url and --token overrides
+func newClientFromFlags(cmd *cobra.Command, cfg *config.Config) (*api.Client, error) {
+	apiURL, _ := cmd.Flags().GetString("api-url")
+	if apiURL == "" {
+		apiURL = cfg.APIURL
+	}
+	if apiURL == "" {
+		return nil, fmt.Errorf("API URL is required (use --api-url or set in config)")
+	}
+
+	token, _ := cmd.Flags().GetString("token")
+	if token == "" {
+		token = cfg.Token
+	}
+
+	return api.NewClient(cfg).
+		WithBaseURL(apiURL).
+		WithToken(token), nil
+}
+
+// newServiceFromFlags returns the backend selected for the current
+// directory. For the HTTP backend the --api-url and --token overrides apply.
+func newServiceFromFlags(cmd *cobra.Command, cfg *config.Config) (api.Service, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return newServiceForDir(cmd, cfg, cwd)
+}
+
+// newServiceForDir returns the backend selected for dir, e.g. a workspace
+// member, with the same overrides as newServiceFromFlags
+func newServiceForDir(cmd *cobra.Command, cfg *config.Config, dir string) (api.Service, error) {
+	if config.ResolveBackendFor(cfg, dir) == config.BackendHTTP {
+		client, err := newClientFromFlags(cmd, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+	return api.NewServiceFor(cfg, dir)
+}
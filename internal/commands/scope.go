@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// addScopeFilterFlags registers the flags used by commands that filter
+// decisions by the scope you're standing in
+func addScopeFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().String("scope", "", "Only show decisions for this scope (plus project-wide ones)")
+	cmd.Flags().Bool("all-scopes", false, "Show decisions from every scope")
+}
+
+// resolveFilterScope returns the scope to filter by, or an empty string when
+// every decision should be shown
+func resolveFilterScope(cmd *cobra.Command) (string, error) {
+	allScopes, _ := cmd.Flags().GetBool("all-scopes")
+	if allScopes {
+		return "", nil
+	}
+
+	scopeKey, _ := cmd.Flags().GetString("scope")
+	if scopeKey != "" {
+		return scopeKey, nil
+	}
+
+	scopeKey, err := config.CurrentScope()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve scope: %w", err)
+	}
+	return scopeKey, nil
+}
+
+// filterDecisionsByScope keeps the decisions in scopeKey plus project-wide ones
+func filterDecisionsByScope(decisions []api.Decision, scopeKey string) []api.Decision {
+	if scopeKey == "" {
+		return decisions
+	}
+
+	var filtered []api.Decision
+	for _, d := range decisions {
+		if d.ScopeKey == "" || d.ScopeKey == scopeKey {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// statusFromDecisions computes project status counts from a decision list
+func statusFromDecisions(projectID string, decisions []api.Decision) *api.ProjectStatus {
+	status := &api.ProjectStatus{
+		ProjectID:      projectID,
+		TotalDecisions: len(decisions),
+	}
+	for _, d := range decisions {
+		switch d.Status {
+		case "ACCEPTED":
+			status.Accepted++
+		case "PENDING":
+			status.Pending++
+		case "DRAFT":
+			status.Draft++
+		case "DEPRECATED":
+			status.Deprecated++
+		}
+	}
+	return status
+}
@@ -0,0 +1,273 @@
+package commands
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/cache"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/Cagangedik/cli-tool/internal/ui"
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// cacheMaxAge is how old the cache may be before search syncs it first
+const cacheMaxAge = 5 * time.Minute
+
+// OpenDashboard runs the dashboard opened on a search hit. main replaces it
+// so the dashboard's actions (e.g. logout) are handled the same way as when
+// it is started without a command.
+var OpenDashboard = func(target *ui.Target) error {
+	_, err := ui.RunInteractiveAt(target)
+	return err
+}
+
+func NewSearchCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Search decisions, memories, tasks and capsules",
+		Long: `Search the project's decisions, memories, tasks and capsules, best matches
+first.
+
+Every word must match, as a whole word or the start of one. Statements, titles
+and capsule names count more than tags, and tags more than rationales,
+content and descriptions.
+
+Results can be narrowed with --type (decision, memory, task, capsule),
+--status and --tag; each flag is repeatable. A result must have one of the
+given types and statuses and every given tag.
+
+With --open the chosen hit is opened: a decision is printed as by
+'hopsule get', anything else is shown in the dashboard. When there is more
+than one hit you are asked which to open.
+
+Search runs against the offline cache. The cache is synced first when it is
+older than 5 minutes, after create, accept, deprecate or import, or with
+--refresh; local projects are synced every time, as their files are cheap to
+read. If the backend can't be reached the cached copy is searched and a
+warning is printed.
+
+Examples:
+  hopsule search postgres
+  hopsule search "error handling" --limit 5
+  hopsule search cache --type decision --status accepted
+  hopsule search login --type task --type memory --tag auth
+  hopsule search postgres --open
+  hopsule search auth -o json`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runSearch,
+	}
+
+	cmd.Flags().Int("limit", cache.DefaultLimit, "Maximum number of results")
+	cmd.Flags().Bool("refresh", false, "Sync the cache before searching")
+	cmd.Flags().StringSlice("type", nil, "Only show results of this type: decision, memory, task, capsule (repeatable)")
+	cmd.Flags().StringSlice("status", nil, "Only show results with this status, e.g. accepted (repeatable)")
+	cmd.Flags().StringSlice("tag", nil, "Only show results with this tag (repeatable)")
+	cmd.Flags().Bool("open", false, "Open a result: decisions as 'hopsule get', others in the dashboard")
+	cmd.Flags().StringP("output", "o", "text", "Output format (text, json)")
+
+	return cmd
+}
+
+func runSearch(cmd *cobra.Command, args []string) error {
+	types, _ := cmd.Flags().GetStringSlice("type")
+	for _, t := range types {
+		if !slices.Contains(cache.Types, t) {
+			return fmt.Errorf("invalid type %q: must be one of %s", t, strings.Join(cache.Types, ", "))
+		}
+	}
+	output, _ := cmd.Flags().GetString("output")
+	open, _ := cmd.Flags().GetBool("open")
+	if open && output == "json" {
+		return fmt.Errorf("--open can't be used with -o json")
+	}
+
+	cfg, err := config.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	projectID, err := resolveProjectID(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	refresh, _ := cmd.Flags().GetBool("refresh")
+	c, err := openSyncedCache(cmd, cfg, projectID, refresh)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	query := strings.Join(args, " ")
+	limit, _ := cmd.Flags().GetInt("limit")
+	statuses, _ := cmd.Flags().GetStringSlice("status")
+	tags, _ := cmd.Flags().GetStringSlice("tag")
+	results, err := c.Search(projectID, query, cache.SearchOptions{
+		Types:    types,
+		Statuses: statuses,
+		Tags:     tags,
+		Limit:    limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if output == "json" {
+		return printSearchJSON(results)
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No results for %q.\n", query)
+		return nil
+	}
+
+	mark := color.New(color.FgYellow, color.Bold).SprintFunc()
+	dim := color.New(color.Faint).SprintFunc()
+	for i, r := range results {
+		title := r.Title
+		if title == "" {
+			title = r.Snippet
+		}
+		header := fmt.Sprintf("%-8s %s", r.Type, r.ID)
+		if r.Status != "" {
+			header += "  " + r.Status
+		}
+		if len(r.Tags) > 0 {
+			header += "  #" + strings.Join(r.Tags, " #")
+		}
+		fmt.Printf("%2d. %s\n", i+1, dim(header))
+		fmt.Printf("    %s\n", cache.Highlight(title, func(s string) string { return mark(s) }))
+		if r.Title != "" && r.Snippet != "" {
+			fmt.Printf("    %s\n", dim(cache.Highlight(r.Snippet, func(s string) string { return mark(s) })))
+		}
+	}
+	fmt.Println()
+
+	if open {
+		return openResult(cmd, projectID, results)
+	}
+	fmt.Printf("%d result(s). Run 'hopsule get <id>' for a decision's details, or add --open.\n", len(results))
+
+	return nil
+}
+
+// openResult opens a search hit, asking which one if there are several
+func openResult(cmd *cobra.Command, projectID string, results []cache.Result) error {
+	r := results[0]
+	if len(results) > 1 {
+		fmt.Print("Open which result? Enter number: ")
+
+		reader := bufio.NewReader(os.Stdin)
+		input, _ := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+
+		idx, err := strconv.Atoi(input)
+		if err != nil || idx < 1 || idx > len(results) {
+			return fmt.Errorf("invalid selection")
+		}
+		r = results[idx-1]
+		fmt.Println()
+	}
+
+	if r.Type == cache.TypeDecision {
+		return runGet(cmd, r.ID)
+	}
+	return OpenDashboard(&ui.Target{ProjectID: projectID, Type: r.Type, ID: r.ID})
+}
+
+// searchResultJSON is a search result in -o json output, without match
+// markers
+type searchResultJSON struct {
+	Type      string   `json:"type"`
+	ID        string   `json:"id"`
+	Title     string   `json:"title,omitempty"`
+	Snippet   string   `json:"snippet,omitempty"`
+	Status    string   `json:"status,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	CreatedAt string   `json:"created_at,omitempty"`
+	Rank      float64  `json:"rank"`
+}
+
+func printSearchJSON(results []cache.Result) error {
+	out := make([]searchResultJSON, 0, len(results))
+	for _, r := range results {
+		out = append(out, searchResultJSON{
+			Type:      r.Type,
+			ID:        r.ID,
+			Title:     cache.StripMarks(r.Title),
+			Snippet:   cache.StripMarks(r.Snippet),
+			Status:    r.Status,
+			Tags:      r.Tags,
+			CreatedAt: r.CreatedAt,
+			Rank:      r.Rank,
+		})
+	}
+	jsonData, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode results: %w", err)
+	}
+	fmt.Println(string(jsonData))
+	return nil
+}
+
+// openSyncedCache opens the cache, syncing the project first if asked to,
+// if it was never synced, if the copy is older than cacheMaxAge or if the
+// project uses the local backend. A failed sync is only fatal when there is
+// no copy to fall back on.
+func openSyncedCache(cmd *cobra.Command, cfg *config.Config, projectID string, refresh bool) (*cache.Cache, error) {
+	c, err := cache.Open(cacheSource(cmd, cfg))
+	if err != nil {
+		return nil, err
+	}
+
+	syncedAt, synced, err := c.SyncedAt(projectID)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	local := config.ResolveBackend(cfg) == config.BackendLocal
+	if !refresh && !local && synced && time.Since(syncedAt) < cacheMaxAge {
+		return c, nil
+	}
+
+	client, err := newServiceFromFlags(cmd, cfg)
+	if err == nil {
+		_, err = c.Sync(client, projectID)
+	}
+	if err != nil {
+		if !synced {
+			c.Close()
+			return nil, fmt.Errorf("failed to sync cache: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: using data cached %s (sync failed: %v)\n\n", syncedAt.Local().Format("2006-01-02 15:04"), err)
+	}
+	return c, nil
+}
+
+// cacheSource is the cache source for the backend a command uses, honouring
+// the --api-url override
+func cacheSource(cmd *cobra.Command, cfg *config.Config) string {
+	apiURL, _ := cmd.Flags().GetString("api-url")
+	if apiURL != "" && config.ResolveBackend(cfg) == config.BackendHTTP {
+		return apiURL
+	}
+	return cache.Source(cfg)
+}
+
+// invalidateCache makes the next search sync a project that was just
+// changed. The cache is only a copy, so failures are ignored.
+func invalidateCache(cmd *cobra.Command, cfg *config.Config, projectID string) {
+	c, err := cache.Open(cacheSource(cmd, cfg))
+	if err != nil {
+		return
+	}
+	defer c.Close()
+	c.Invalidate(projectID)
+}
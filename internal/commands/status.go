@@ -3,6 +3,9 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
 
 	"github.com/Cagangedik/cli-tool/internal/api"
 	"github.com/Cagangedik/cli-tool/internal/config"
@@ -20,34 +23,40 @@ func NewStatusCommand() *cobra.Command {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			projectID, _ := cmd.Flags().GetString("project")
-			if projectID == "" {
-				projectID = cfg.Project
-			}
-			if projectID == "" {
-				return fmt.Errorf("project ID is required (use --project or set in config)")
+			allProjects, _ := cmd.Flags().GetBool("all-projects")
+			if allProjects {
+				return runWorkspaceStatus(cmd, cfg)
 			}
 
-			apiURL, _ := cmd.Flags().GetString("api-url")
-			if apiURL == "" {
-				apiURL = cfg.APIURL
-			}
-			if apiURL == "" {
-				return fmt.Errorf("API URL is required (use --api-url or set in config)")
+			client, err := newServiceFromFlags(cmd, cfg)
+			if err != nil {
+				return err
 			}
 
-			token, _ := cmd.Flags().GetString("token")
-			if token == "" {
-				token = cfg.Token
+			projectID, err := resolveProjectID(cmd, cfg)
+			if err != nil {
+				return err
 			}
 
-			client := api.NewClient(cfg).
-				WithBaseURL(apiURL).
-				WithToken(token)
-
-			status, err := client.GetProjectStatus(projectID)
+			scopeKey, err := resolveFilterScope(cmd)
 			if err != nil {
-				return fmt.Errorf("failed to get status: %w", err)
+				return err
+			}
+
+			// The status endpoint has no notion of scopes, so scoped
+			// status is computed from the decision list instead
+			var status *api.ProjectStatus
+			if scopeKey != "" {
+				decisions, err := client.ListDecisions(projectID)
+				if err != nil {
+					return fmt.Errorf("failed to get status: %w", err)
+				}
+				status = statusFromDecisions(projectID, filterDecisionsByScope(decisions, scopeKey))
+			} else {
+				status, err = client.GetProjectStatus(projectID)
+				if err != nil {
+					return fmt.Errorf("failed to get status: %w", err)
+				}
 			}
 
 			output, _ := cmd.Flags().GetString("output")
@@ -55,7 +64,11 @@ func NewStatusCommand() *cobra.Command {
 				jsonData, _ := json.MarshalIndent(status, "", "  ")
 				fmt.Println(string(jsonData))
 			} else {
-				fmt.Printf("Project: %s\n\n", status.ProjectID)
+				fmt.Printf("Project: %s\n", status.ProjectID)
+				if scopeKey != "" {
+					fmt.Printf("Scope:   %s (plus project-wide decisions)\n", scopeKey)
+				}
+				fmt.Println()
 				fmt.Printf("Total Decisions: %d\n", status.TotalDecisions)
 				fmt.Printf("  Accepted:   %d\n", status.Accepted)
 				fmt.Printf("  Pending:   %d\n", status.Pending)
@@ -68,6 +81,129 @@ func NewStatusCommand() *cobra.Command {
 	}
 
 	cmd.Flags().StringP("output", "o", "text", "Output format (text, json)")
+	cmd.Flags().Bool("all-projects", false, "Aggregate status across every project in the workspace")
+	addScopeFilterFlags(cmd)
 
 	return cmd
 }
+
+// workspaceProjectStatus is one row of 'status --all-projects'
+type workspaceProjectStatus struct {
+	Path   string             `json:"path"`
+	Name   string             `json:"name"`
+	Status *api.ProjectStatus `json:"status,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// runWorkspaceStatus sums the status of every workspace member. Members can
+// use different backends, e.g. a local project next to server projects, so
+// each is queried through the backend selected for its directory. The scope
+// of the current directory only means something in its own project, so the
+// workspace is unscoped unless --scope names a scope to apply to every member.
+func runWorkspaceStatus(cmd *cobra.Command, cfg *config.Config) error {
+	scopeKey, _ := cmd.Flags().GetString("scope")
+	if allScopes, _ := cmd.Flags().GetBool("all-scopes"); allScopes {
+		scopeKey = ""
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	ws, err := config.LoadWorkspace(cwd)
+	if err != nil {
+		return fmt.Errorf("failed to load workspace: %w", err)
+	}
+	if len(ws.Members) == 0 {
+		return fmt.Errorf("no projects found in workspace %s", ws.Root)
+	}
+
+	var rows []workspaceProjectStatus
+	total := &api.ProjectStatus{ProjectID: "total"}
+	for _, member := range ws.Members {
+		rel, err := filepath.Rel(ws.Root, member.Dir)
+		if err != nil {
+			rel = member.Dir
+		}
+		name := member.Project.Name
+		if name == "" {
+			name = member.Project.Slug
+		}
+
+		row := workspaceProjectStatus{Path: filepath.ToSlash(rel), Name: name}
+		status, err := memberStatus(cmd, cfg, member, scopeKey)
+		if err != nil {
+			row.Error = err.Error()
+		} else {
+			row.Status = status
+			total.TotalDecisions += status.TotalDecisions
+			total.Accepted += status.Accepted
+			total.Pending += status.Pending
+			total.Draft += status.Draft
+			total.Deprecated += status.Deprecated
+		}
+		rows = append(rows, row)
+	}
+
+	output, _ := cmd.Flags().GetString("output")
+	if output == "json" {
+		result := map[string]interface{}{
+			"root":     ws.Root,
+			"projects": rows,
+			"total":    total,
+		}
+		if scopeKey != "" {
+			result["scope"] = scopeKey
+		}
+		jsonData, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	fmt.Printf("Workspace: %s\n", ws.Root)
+	if scopeKey != "" {
+		fmt.Printf("Scope:     %s (plus project-wide decisions)\n", scopeKey)
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "PATH\tPROJECT\tTOTAL\tACCEPTED\tPENDING\tDRAFT\tDEPRECATED")
+	fmt.Fprintln(w, "----\t-------\t-----\t--------\t-------\t-----\t----------")
+	for _, row := range rows {
+		if row.Status == nil {
+			fmt.Fprintf(w, "%s\t%s\terror: %s\t\t\t\t\n", row.Path, truncate(row.Name, 30), truncate(row.Error, 40))
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\n",
+			row.Path,
+			truncate(row.Name, 30),
+			row.Status.TotalDecisions,
+			row.Status.Accepted,
+			row.Status.Pending,
+			row.Status.Draft,
+			row.Status.Deprecated,
+		)
+	}
+	fmt.Fprintf(w, "\tTOTAL\t%d\t%d\t%d\t%d\t%d\n",
+		total.TotalDecisions, total.Accepted, total.Pending, total.Draft, total.Deprecated)
+
+	return w.Flush()
+}
+
+// memberStatus fetches the status of one workspace member from its backend,
+// counting only the decisions in scopeKey and project-wide ones when set
+func memberStatus(cmd *cobra.Command, cfg *config.Config, member config.WorkspaceMember, scopeKey string) (*api.ProjectStatus, error) {
+	client, err := newServiceForDir(cmd, cfg, member.Dir)
+	if err != nil {
+		return nil, err
+	}
+	if scopeKey == "" {
+		return client.GetProjectStatus(member.Project.ID)
+	}
+	decisions, err := client.ListDecisions(member.Project.ID)
+	if err != nil {
+		return nil, err
+	}
+	return statusFromDecisions(member.Project.ID, filterDecisionsByScope(decisions, scopeKey)), nil
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/apitest"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/Cagangedik/cli-tool/internal/localstore"
+)
+
+// newMixedWorkspace creates a workspace whose root is the server project
+// and whose notes/ directory is a local project with two draft decisions
+func newMixedWorkspace(t *testing.T) (root, notes string) {
+	t.Helper()
+	root = t.TempDir()
+	if err := config.SaveProjectConfig(root, &config.ProjectConfig{
+		Project: config.ProjectInfo{ID: apitest.ProjectID, Name: "Server"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	notes = filepath.Join(root, "notes")
+	if err := os.Mkdir(notes, 0755); err != nil {
+		t.Fatal(err)
+	}
+	store, err := localstore.Init(notes, &config.ProjectConfig{
+		Project: config.ProjectInfo{ID: "local-notes", Name: "Notes"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, statement := range []string{"Keep notes in Markdown", "Review notes weekly"} {
+		if _, err := store.CreateDecision("local-notes", api.CreateDecisionRequest{Statement: statement}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root, notes
+}
+
+// runWorkspaceStatusJSON runs 'status --all-projects -o json' with args
+func runWorkspaceStatusJSON(t *testing.T, args ...string) api.ProjectStatus {
+	t.Helper()
+	cmd := NewStatusCommand()
+	cmd.SetArgs(append([]string{"--all-projects", "-o", "json"}, args...))
+	out, err := captureStdout(t, cmd.Execute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct {
+		Projects []workspaceProjectStatus `json:"projects"`
+		Total    api.ProjectStatus        `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("%v:\n%s", err, out)
+	}
+	for _, row := range result.Projects {
+		if row.Error != "" {
+			t.Errorf("%s: %s", row.Path, row.Error)
+		}
+	}
+	return result.Total
+}
+
+func TestWorkspaceStatusWithMixedBackends(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	signIn(t, srv)
+	srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Go", Status: "ACCEPTED"})
+	root, notes := newMixedWorkspace(t)
+
+	for _, dir := range []string{root, notes} {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			t.Chdir(dir)
+			total := runWorkspaceStatusJSON(t)
+			if total.TotalDecisions != 3 || total.Accepted != 1 || total.Draft != 2 {
+				t.Errorf("total = %+v, want 1 accepted server decision and 2 local drafts", total)
+			}
+		})
+	}
+}
+
+func TestWorkspaceStatusAppliesScopeToEveryMember(t *testing.T) {
+	srv := apitest.NewServer()
+	defer srv.Close()
+	signIn(t, srv)
+	srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use Go", Status: "ACCEPTED"})
+	srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use gRPC", ScopeKey: "api"})
+	srv.AddDecision(apitest.ProjectID, api.Decision{Statement: "Use React", ScopeKey: "web"})
+	root, _ := newMixedWorkspace(t)
+	t.Chdir(root)
+
+	if total := runWorkspaceStatusJSON(t); total.TotalDecisions != 5 {
+		t.Errorf("unscoped total = %+v, want 5 decisions", total)
+	}
+	// The web decision is left out; project-wide ones are kept
+	if total := runWorkspaceStatusJSON(t, "--scope", "api"); total.TotalDecisions != 4 || total.Accepted != 1 || total.Draft != 3 {
+		t.Errorf("scoped total = %+v, want 1 accepted and 3 drafts", total)
+	}
+	if total := runWorkspaceStatusJSON(t, "--scope", "api", "--all-scopes"); total.TotalDecisions != 5 {
+		t.Errorf("total with --all-scopes = %+v, want 5 decisions", total)
+	}
+}
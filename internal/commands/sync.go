@@ -3,7 +3,7 @@ package commands
 import (
 	"fmt"
 
-	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/cache"
 	"github.com/Cagangedik/cli-tool/internal/config"
 	"github.com/spf13/cobra"
 )
@@ -12,45 +12,39 @@ func NewSyncCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "sync",
 		Short: "Sync with remote decision-api",
-		Long:  "Sync local state with the remote decision-api (currently a no-op, future: cache management)",
+		Long: `Sync local state with the backend: the project's decisions, memories and
+tasks are copied to the offline cache used by 'hopsule search' and the
+dashboard's filters.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg, err := config.GetConfig()
 			if err != nil {
 				return fmt.Errorf("failed to load config: %w", err)
 			}
 
-			projectID, _ := cmd.Flags().GetString("project")
-			if projectID == "" {
-				projectID = cfg.Project
-			}
-			if projectID == "" {
-				return fmt.Errorf("project ID is required (use --project or set in config)")
+			projectID, err := resolveProjectID(cmd, cfg)
+			if err != nil {
+				return err
 			}
 
-			apiURL, _ := cmd.Flags().GetString("api-url")
-			if apiURL == "" {
-				apiURL = cfg.APIURL
-			}
-			if apiURL == "" {
-				return fmt.Errorf("API URL is required (use --api-url or set in config)")
+			client, err := newServiceFromFlags(cmd, cfg)
+			if err != nil {
+				return err
 			}
 
-			token, _ := cmd.Flags().GetString("token")
-			if token == "" {
-				token = cfg.Token
+			c, err := cache.Open(cacheSource(cmd, cfg))
+			if err != nil {
+				return err
 			}
+			defer c.Close()
 
-			client := api.NewClient(cfg).
-				WithBaseURL(apiURL).
-				WithToken(token)
-
-			// Test connection
-			_, err = client.ListDecisions(projectID)
+			result, err := c.Sync(client, projectID)
 			if err != nil {
 				return fmt.Errorf("failed to sync: %w", err)
 			}
 
 			fmt.Println("Sync completed successfully.")
+			fmt.Printf("Cached %d decisions, %d memories, %d tasks and %d capsules in %s\n",
+				result.Decisions, result.Memories, result.Tasks, result.Capsules, c.Path())
 			return nil
 		},
 	}
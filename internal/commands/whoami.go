@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/Cagangedik/cli-tool/internal/api"
 	"github.com/Cagangedik/cli-tool/internal/config"
@@ -32,9 +33,13 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	if cfg.Token != "" && api.TokenTypeOf(cfg.Token) == api.TokenTypeService {
+		return runServiceWhoami(cfg)
+	}
+
 	// Check if logged in
 	if !cfg.IsAuthenticated() {
-		fmt.Println("Not logged in.")
+		printNotLoggedIn(cfg)
 		fmt.Println()
 		fmt.Println("Run 'hopsule login' to sign in.")
 		return nil
@@ -100,8 +105,50 @@ func runWhoami(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	printWhoamiFooter(cfg, api.TokenTypeUser)
+
+	return nil
+}
+
+// runServiceWhoami describes the service token in use instead of a user
+func runServiceWhoami(cfg *config.Config) error {
+	fmt.Println("┌─────────────────────────────────────────┐")
+	fmt.Println("│           Service Token                 │")
+	fmt.Println("└─────────────────────────────────────────┘")
 	fmt.Println()
-	fmt.Println("Config file: ~/.decision-cli/config.yaml")
+
+	client := api.NewClient(cfg)
+	meResp, err := client.GetMe()
+	if err != nil {
+		fmt.Printf("  (Could not fetch token details: %v)\n", err)
+	} else if meResp.APIToken != nil {
+		token := meResp.APIToken
+		fmt.Printf("  Name:    %s\n", token.Name)
+		fmt.Printf("  ID:      %s\n", token.ID)
+		fmt.Printf("  Project: %s\n", token.ProjectID)
+		fmt.Printf("  Scopes:  %s\n", strings.Join(token.Scopes, ", "))
+		if token.ExpiresAt != "" {
+			fmt.Printf("  Expires: %s\n", token.ExpiresAt)
+		}
+	}
+
+	printWhoamiFooter(cfg, api.TokenTypeService)
 
 	return nil
 }
+
+func printWhoamiFooter(cfg *config.Config, tokenType string) {
+	fmt.Println()
+	fmt.Printf("Token type:  %s\n", tokenType)
+	if expiry := cfg.TokenExpiry(); !expiry.IsZero() {
+		status := ""
+		if cfg.TokenExpired() {
+			status = " (expired)"
+		}
+		fmt.Printf("Expires:     %s%s\n", expiry.Local().Format("2006-01-02 15:04"), status)
+	}
+	fmt.Printf("Profile:     %s\n", cfg.Profile)
+	if configPath, err := config.ConfigFilePath(); err == nil {
+		fmt.Printf("Config file: %s\n", configPath)
+	}
+}
@@ -1,128 +1,297 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
 
-	"github.com/spf13/viper"
+	"github.com/Cagangedik/cli-tool/internal/credentials"
 )
 
 type Config struct {
-	APIURL       string `mapstructure:"api_url"`
-	WebURL       string `mapstructure:"web_url"`
-	Token        string `mapstructure:"token"`
-	Project      string `mapstructure:"project"`
-	Organization string `mapstructure:"organization"`
-	User         *User  `mapstructure:"user"`
+	APIURL       string `yaml:"api_url,omitempty"`
+	WebURL       string `yaml:"web_url,omitempty"`
+	Token        string `yaml:"token,omitempty"`
+	Project      string `yaml:"project,omitempty"`
+	Organization string `yaml:"organization,omitempty"`
+	User         *User  `yaml:"user,omitempty"`
+
+	// Backend selects the api.Service implementation; see Backends
+	Backend string `yaml:"backend,omitempty"`
+
+	// RefreshToken, when the server issued one, is used to renew Token
+	// before it expires
+	RefreshToken string `yaml:"refresh_token,omitempty"`
+	// TokenExpiresAt is the RFC 3339 expiry of Token from the login
+	// response; JWT claims are used when it is empty
+	TokenExpiresAt string `yaml:"token_expires_at,omitempty"`
+
+	// TokenRef names the credential store holding the token when it isn't
+	// kept in the file itself (keyring, file or helper)
+	TokenRef string `yaml:"token_ref,omitempty"`
+
+	// Network settings, used by api.NewHTTPClient. Proxy is a proxy URL, or
+	// "direct" to ignore HTTP(S)_PROXY; CAFile lists extra CA bundles
+	// separated by commas; UnixSocket connects to the API over a Unix
+	// domain socket instead of TCP.
+	Proxy              string `yaml:"proxy,omitempty"`
+	CAFile             string `yaml:"ca_file,omitempty"`
+	ClientCert         string `yaml:"client_cert,omitempty"`
+	ClientKey          string `yaml:"client_key,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify,omitempty"`
+	Timeout            string `yaml:"timeout,omitempty"`
+	UnixSocket         string `yaml:"unix_socket,omitempty"`
+
+	// Profile is the name of the profile this config was loaded from
+	Profile string `yaml:"-"`
 }
 
 // User represents the authenticated user info stored in config
 type User struct {
-	ID        string `mapstructure:"id"`
-	Email     string `mapstructure:"email"`
-	Name      string `mapstructure:"name"`
-	AvatarURL string `mapstructure:"avatar_url"`
+	ID        string `yaml:"id,omitempty"`
+	Email     string `yaml:"email,omitempty"`
+	Name      string `yaml:"name,omitempty"`
+	AvatarURL string `yaml:"avatar_url,omitempty"`
 }
 
-var cfg *Config
+// configFile is the on-disk layout of config.yaml
+type configFile struct {
+	CurrentProfile string             `yaml:"current_profile,omitempty"`
+	Profiles       map[string]*Config `yaml:"profiles,omitempty"`
+
+	// CredentialStore selects where tokens are kept: auto (default),
+	// keyring, file or plaintext
+	CredentialStore string `yaml:"credential_store,omitempty"`
+	// CredentialHelper is an external git-style credential helper command;
+	// setting it overrides CredentialStore
+	CredentialHelper string `yaml:"credential_helper,omitempty"`
+
+	// Files written before profiles existed keep their settings at the top
+	// level. They are read as the default profile and moved under profiles
+	// the next time the config is saved.
+	Legacy Config `yaml:",inline"`
+}
+
+const (
+	// DefaultProfile is used when no profile is selected
+	DefaultProfile = "default"
+
+	defaultAPIURL = "http://localhost:8080"
+	defaultWebURL = "http://localhost:3000"
+)
+
+// envOverrides map environment variables to the settings they override on
+// top of the selected profile
+var envOverrides = []struct {
+	name string
+	key  string
+}{
+	{"HOPSULE_API_URL", "api_url"},
+	{"HOPSULE_WEB_URL", "web_url"},
+	{"HOPSULE_TOKEN", "token"},
+	{"HOPSULE_PROJECT", "project"},
+	{"HOPSULE_ORGANIZATION", "organization"},
+	{"HOPSULE_BACKEND", "backend"},
+}
+
+// defaultStore backs the package-level functions below, which commands use
+// for the config of the current process
+var defaultStore = NewStore("")
+
+// Default returns the store used by the package-level functions
+func Default() *Store {
+	return defaultStore
+}
+
+// SetActiveProfile selects the profile used by LoadConfig, overriding
+// HOPSULE_PROFILE and the current profile stored in the config file
+func SetActiveProfile(name string) {
+	defaultStore.SetProfile(name)
+}
 
 // LoadConfig loads configuration from file and environment variables
 func LoadConfig() (*Config, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
-	}
+	return defaultStore.Load()
+}
 
-	configDir := filepath.Join(homeDir, ".decision-cli")
+// GetConfig returns the loaded config or loads it if not yet loaded
+func GetConfig() (*Config, error) {
+	return defaultStore.Config()
+}
 
-	viper.SetConfigName("config")
-	viper.SetConfigType("yaml")
-	viper.AddConfigPath(configDir)
-	viper.AddConfigPath(".")
+// SaveConfig saves the current config to file, under its profile
+func SaveConfig(c *Config) error {
+	return defaultStore.Save(c)
+}
 
-	// Environment variables
-	viper.SetEnvPrefix("DECISION")
-	viper.AutomaticEnv()
-	viper.BindEnv("api_url", "DECISION_API_URL")
-	viper.BindEnv("token", "DECISION_TOKEN")
-	viper.BindEnv("project", "DECISION_PROJECT")
+// ClearAuth clears authentication data from config
+func ClearAuth(c *Config) error {
+	return defaultStore.ClearAuth(c)
+}
 
-	// Defaults
-	viper.SetDefault("api_url", "http://localhost:8080")
-	viper.SetDefault("web_url", "http://localhost:3000")
+// ProfileInfo summarizes a stored profile
+type ProfileInfo struct {
+	Name    string
+	Current bool
+	Config  *Config
+}
 
-	// Read config file (optional - file may not exist)
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			return nil, fmt.Errorf("error reading config file: %w", err)
-		}
-		// Config file not found is OK - we'll use defaults/env vars
-	}
+// ListProfiles returns every stored profile, sorted by name
+func ListProfiles() ([]ProfileInfo, error) {
+	return defaultStore.Profiles()
+}
+
+// UseProfile makes name the profile used when no --profile or
+// HOPSULE_PROFILE is given
+func UseProfile(name string) error {
+	return defaultStore.UseProfile(name)
+}
+
+// AddProfile stores a new profile
+func AddProfile(name string, c *Config) error {
+	return defaultStore.AddProfile(name, c)
+}
 
-	cfg = &Config{}
-	if err := viper.Unmarshal(cfg); err != nil {
-		return nil, fmt.Errorf("error unmarshaling config: %w", err)
+// RemoveProfile deletes a stored profile. If it was the current profile,
+// the default profile becomes current.
+func RemoveProfile(name string) error {
+	return defaultStore.RemoveProfile(name)
+}
+
+// ConfigFilePath returns the path of the global config file
+func ConfigFilePath() (string, error) {
+	return defaultStore.Path()
+}
+
+// profile returns a copy of the named profile, or nil if it doesn't exist
+func (f *configFile) profile(name string) *Config {
+	if c, ok := f.Profiles[name]; ok && c != nil {
+		copied := *c
+		return &copied
+	}
+	if name == DefaultProfile && f.Legacy != (Config{}) {
+		copied := f.Legacy
+		return &copied
 	}
+	return nil
+}
 
-	// Ensure config directory exists for future writes
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create config directory: %w", err)
+// credentialOptions returns the credential store settings from the file
+func (f *configFile) credentialOptions(dir string) credentials.Options {
+	return credentials.Options{
+		Backend: f.CredentialStore,
+		Helper:  f.CredentialHelper,
+		Dir:     dir,
 	}
+}
 
-	return cfg, nil
+// loadSecrets fills c's token and refresh token from the store named by
+// c.TokenRef
+func (f *configFile) loadSecrets(dir, profile string, c *Config) error {
+	store, err := credentials.OpenByName(c.TokenRef, f.credentialOptions(dir))
+	if err != nil {
+		return err
+	}
+	for _, secret := range c.secrets() {
+		value, err := store.Get(credentialKey(profile) + secret.suffix)
+		if errors.Is(err, credentials.ErrNotFound) && secret.suffix != "" {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		*secret.value = value
+	}
+	return nil
 }
 
-// GetConfig returns the loaded config or loads it if not yet loaded
-func GetConfig() (*Config, error) {
-	if cfg != nil {
-		return cfg, nil
+// saveProfile stores a copy of c under the named profile, moving its
+// secrets into the credential store
+func (f *configFile) saveProfile(dir, name string, c *Config) error {
+	stored := *c
+	if err := f.storeToken(dir, name, &stored); err != nil {
+		return fmt.Errorf("failed to store token: %w", err)
 	}
-	return LoadConfig()
+	c.TokenRef = stored.TokenRef
+	f.Profiles[name] = &stored
+	return nil
 }
 
-// SaveConfig saves the current config to file
-func SaveConfig(c *Config) error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return fmt.Errorf("failed to get home directory: %w", err)
+// storeToken moves c's token and refresh token into the configured
+// credential store, leaving only a reference in c. With the plaintext store
+// they stay in c.
+func (f *configFile) storeToken(dir, profile string, c *Config) error {
+	// Forget the secrets in the store they were previously saved to, so
+	// switching backends or logging out doesn't leave them behind
+	if c.TokenRef != "" {
+		if previous, err := credentials.OpenByName(c.TokenRef, f.credentialOptions(dir)); err == nil {
+			for _, secret := range c.secrets() {
+				if *secret.value == "" || previous.Name() != f.targetStoreName() {
+					if err := previous.Delete(credentialKey(profile) + secret.suffix); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		c.TokenRef = ""
 	}
 
-	configDir := filepath.Join(homeDir, ".decision-cli")
-	configFile := filepath.Join(configDir, "config.yaml")
+	if c.Token == "" && c.RefreshToken == "" {
+		return nil
+	}
 
-	viper.Set("api_url", c.APIURL)
-	viper.Set("web_url", c.WebURL)
-	viper.Set("token", c.Token)
-	viper.Set("project", c.Project)
-	viper.Set("organization", c.Organization)
-	
-	if c.User != nil {
-		viper.Set("user.id", c.User.ID)
-		viper.Set("user.email", c.User.Email)
-		viper.Set("user.name", c.User.Name)
-		viper.Set("user.avatar_url", c.User.AvatarURL)
-	} else {
-		viper.Set("user", nil)
+	store, err := credentials.Open(f.credentialOptions(dir))
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return nil
 	}
 
-	if err := os.MkdirAll(configDir, 0700); err != nil { // 0700 for security
-		return fmt.Errorf("failed to create config directory: %w", err)
+	for _, secret := range c.secrets() {
+		if *secret.value == "" {
+			continue
+		}
+		if err := store.Set(credentialKey(profile)+secret.suffix, *secret.value); err != nil {
+			return err
+		}
+		*secret.value = ""
 	}
+	c.TokenRef = store.Name()
+	return nil
+}
 
-	return viper.WriteConfigAs(configFile)
+type secretField struct {
+	suffix string
+	value  *string
 }
 
-// ClearAuth clears authentication data from config
-func ClearAuth(c *Config) error {
-	c.Token = ""
-	c.User = nil
-	return SaveConfig(c)
+// secrets lists the fields of c kept in the credential store, keyed by the
+// suffix added to the profile's credential key
+func (c *Config) secrets() []secretField {
+	return []secretField{
+		{suffix: "", value: &c.Token},
+		{suffix: ":refresh", value: &c.RefreshToken},
+	}
+}
+
+// targetStoreName is the backend name new tokens will be saved to, or empty
+// when it can't be known without probing
+func (f *configFile) targetStoreName() string {
+	if f.CredentialHelper != "" {
+		return credentials.BackendHelper
+	}
+	return f.CredentialStore
+}
+
+func credentialKey(profile string) string {
+	return "profile:" + profile
 }
 
 // IsAuthenticated returns true if user is logged in
 func (c *Config) IsAuthenticated() bool {
-	return c.Token != "" && c.User != nil
+	return c.Token != "" && c.User != nil && !c.SessionExpired()
 }
 
 // GetWebURL returns the web URL with fallback to default
@@ -130,7 +299,7 @@ func (c *Config) GetWebURL() string {
 	if c.WebURL != "" {
 		return c.WebURL
 	}
-	return "http://localhost:3000"
+	return defaultWebURL
 }
 
 // GetAPIURL returns the API URL with fallback to default
@@ -138,5 +307,46 @@ func (c *Config) GetAPIURL() string {
 	if c.APIURL != "" {
 		return c.APIURL
 	}
-	return "http://localhost:8080"
+	return defaultAPIURL
+}
+
+// GetBackend returns the selected backend with fallback to BackendHTTP
+func (c *Config) GetBackend() string {
+	if c.Backend != "" {
+		return c.Backend
+	}
+	return BackendHTTP
+}
+
+// ResolveBackend returns the backend to use in the current directory (see
+// ResolveBackendFor)
+func ResolveBackend(c *Config) string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return c.GetBackend()
+	}
+	return ResolveBackendFor(c, dir)
+}
+
+// ResolveBackendFor returns the backend to use in dir: HOPSULE_BACKEND wins,
+// then the backend of the nearest .hopsule file, then the config file
+func ResolveBackendFor(c *Config, dir string) string {
+	if origin := defaultStore.Origin(c, "backend"); origin == LayerEnv || origin == LayerFlag {
+		return c.GetBackend()
+	}
+	if resolved, err := ResolveProject(dir); err == nil && resolved.Config.Backend != "" {
+		return resolved.Config.Backend
+	}
+	return c.GetBackend()
+}
+
+// CAFiles returns the extra CA bundle paths from CAFile
+func (c *Config) CAFiles() []string {
+	var files []string
+	for _, path := range strings.Split(c.CAFile, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			files = append(files, path)
+		}
+	}
+	return files
 }
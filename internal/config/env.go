@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// legacyEnvPrefix is the prefix environment variables used before they were
+// renamed to HOPSULE_*. It is still read, with a deprecation warning.
+const legacyEnvPrefix = "DECISION_"
+
+var warnedEnv sync.Map
+
+// getenv returns the HOPSULE_* variable name, falling back to its
+// deprecated DECISION_* alias. A warning is printed once per alias used.
+func (s *Store) getenv(name string) string {
+	if value := s.env(name); value != "" {
+		return value
+	}
+
+	alias := legacyEnvName(name)
+	value := s.env(alias)
+	if value != "" {
+		if _, warned := warnedEnv.LoadOrStore(alias, true); !warned {
+			fmt.Fprintf(os.Stderr, "Warning: %s is deprecated; use %s instead\n", alias, name)
+		}
+	}
+	return value
+}
+
+// DeprecatedEnv lists the DECISION_* variables that are set, mapped to
+// their HOPSULE_* replacements
+func DeprecatedEnv() map[string]string {
+	deprecated := map[string]string{}
+	for _, override := range envOverrides {
+		if alias := legacyEnvName(override.name); os.Getenv(alias) != "" {
+			deprecated[alias] = override.name
+		}
+	}
+	return deprecated
+}
+
+func legacyEnvName(name string) string {
+	return legacyEnvPrefix + strings.TrimPrefix(name, "HOPSULE_")
+}
@@ -0,0 +1,406 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/credentials"
+	"gopkg.in/yaml.v3"
+)
+
+// ProxyDirect as the proxy setting disables proxies, including those from
+// HTTP_PROXY and HTTPS_PROXY
+const ProxyDirect = "direct"
+
+// Backends selected with the backend setting. BackendHTTP talks to
+// decision-api; BackendLocal keeps project data in files under the
+// project's .hopsule directory.
+const (
+	BackendHTTP  = "http"
+	BackendLocal = "local"
+)
+
+// Backends lists the valid values of the backend setting
+var Backends = []string{BackendHTTP, BackendLocal}
+
+// ErrKeyNotSet is returned by GetValue when a key has no stored value
+var ErrKeyNotSet = errors.New("key is not set")
+
+// Key describes a global setting that can be read and changed with
+// 'hopsule config get/set/unset'
+type Key struct {
+	Name        string
+	Description string
+	// Default is used when the key isn't set
+	Default string
+	// Secret values are masked when listed
+	Secret bool
+	// ReadOnly keys are written by login and can only be read
+	ReadOnly bool
+	// global keys apply to every profile and are stored at the top level
+	global bool
+
+	validate func(value string) error
+	get      func(f *configFile, c *Config) string
+	set      func(f *configFile, c *Config, value string)
+}
+
+// KeyValue is a key with its stored value, as returned by ListValues
+type KeyValue struct {
+	Key   Key
+	Value string
+}
+
+var keys = []Key{
+	{
+		Name:        "api_url",
+		Description: "Base URL of the Hopsule API",
+		Default:     defaultAPIURL,
+		validate:    validateURL,
+		get:         func(_ *configFile, c *Config) string { return c.APIURL },
+		set:         func(_ *configFile, c *Config, v string) { c.APIURL = v },
+	},
+	{
+		Name:        "web_url",
+		Description: "Base URL of the Hopsule dashboard",
+		Default:     defaultWebURL,
+		validate:    validateURL,
+		get:         func(_ *configFile, c *Config) string { return c.WebURL },
+		set:         func(_ *configFile, c *Config, v string) { c.WebURL = v },
+	},
+	{
+		Name:        "token",
+		Description: "Authentication token",
+		Secret:      true,
+		get:         func(_ *configFile, c *Config) string { return c.Token },
+		set: func(_ *configFile, c *Config, v string) {
+			if v != c.Token {
+				// The stored expiry and refresh token belong to another token
+				c.Token, c.RefreshToken, c.TokenExpiresAt = v, "", ""
+			}
+		},
+	},
+	{
+		Name:        "refresh_token",
+		Description: "Token used to renew the authentication token",
+		Secret:      true,
+		ReadOnly:    true,
+		get:         func(_ *configFile, c *Config) string { return c.RefreshToken },
+	},
+	{
+		Name:        "token_expires_at",
+		Description: "Expiry of the authentication token (RFC 3339)",
+		validate: func(v string) error {
+			if _, err := time.Parse(time.RFC3339, v); err != nil {
+				return fmt.Errorf("must be an RFC 3339 timestamp such as 2006-01-02T15:04:05Z")
+			}
+			return nil
+		},
+		get: func(_ *configFile, c *Config) string { return c.TokenExpiresAt },
+		set: func(_ *configFile, c *Config, v string) { c.TokenExpiresAt = v },
+	},
+	{
+		Name:        "token_ref",
+		Description: "Credential store holding the token",
+		ReadOnly:    true,
+		get:         func(_ *configFile, c *Config) string { return c.TokenRef },
+	},
+	{
+		Name:        "project",
+		Description: "Default project ID",
+		get:         func(_ *configFile, c *Config) string { return c.Project },
+		set:         func(_ *configFile, c *Config, v string) { c.Project = v },
+	},
+	{
+		Name:        "backend",
+		Description: "Where decisions and other project data are stored: " + strings.Join(Backends, " or "),
+		Default:     BackendHTTP,
+		validate: func(v string) error {
+			if !validBackend(v) {
+				return fmt.Errorf("must be one of: %s", strings.Join(Backends, ", "))
+			}
+			return nil
+		},
+		get: func(_ *configFile, c *Config) string { return c.Backend },
+		set: func(_ *configFile, c *Config, v string) { c.Backend = v },
+	},
+	{
+		Name:        "organization",
+		Description: "Default organization ID",
+		get:         func(_ *configFile, c *Config) string { return c.Organization },
+		set:         func(_ *configFile, c *Config, v string) { c.Organization = v },
+	},
+	{
+		Name:        "user.id",
+		Description: "ID of the logged-in user",
+		ReadOnly:    true,
+		get:         func(_ *configFile, c *Config) string { return storedUser(c).ID },
+	},
+	{
+		Name:        "user.email",
+		Description: "Email of the logged-in user",
+		ReadOnly:    true,
+		get:         func(_ *configFile, c *Config) string { return storedUser(c).Email },
+	},
+	{
+		Name:        "user.name",
+		Description: "Name of the logged-in user",
+		ReadOnly:    true,
+		get:         func(_ *configFile, c *Config) string { return storedUser(c).Name },
+	},
+	{
+		Name:        "user.avatar_url",
+		Description: "Avatar of the logged-in user",
+		ReadOnly:    true,
+		get:         func(_ *configFile, c *Config) string { return storedUser(c).AvatarURL },
+	},
+	{
+		Name:        "proxy",
+		Description: "Proxy URL for API requests, or direct to ignore HTTP(S)_PROXY",
+		validate: func(v string) error {
+			if v == ProxyDirect {
+				return nil
+			}
+			u, err := url.Parse(v)
+			if err != nil {
+				return err
+			}
+			switch u.Scheme {
+			case "http", "https", "socks5", "socks5h":
+			default:
+				return fmt.Errorf("must be an http://, https:// or socks5:// URL, or %q", ProxyDirect)
+			}
+			if u.Host == "" {
+				return fmt.Errorf("missing host in %q", v)
+			}
+			return nil
+		},
+		get: func(_ *configFile, c *Config) string { return c.Proxy },
+		set: func(_ *configFile, c *Config, v string) { c.Proxy = v },
+	},
+	{
+		Name:        "ca_file",
+		Description: "Extra CA bundles to trust, separated by commas",
+		validate: func(v string) error {
+			for _, path := range (&Config{CAFile: v}).CAFiles() {
+				if err := validateFile(path); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		get: func(_ *configFile, c *Config) string { return c.CAFile },
+		set: func(_ *configFile, c *Config, v string) { c.CAFile = v },
+	},
+	{
+		Name:        "client_cert",
+		Description: "Client certificate for mutual TLS (PEM)",
+		validate:    validateFile,
+		get:         func(_ *configFile, c *Config) string { return c.ClientCert },
+		set:         func(_ *configFile, c *Config, v string) { c.ClientCert = v },
+	},
+	{
+		Name:        "client_key",
+		Description: "Private key for client_cert, if not in the same file",
+		validate:    validateFile,
+		get:         func(_ *configFile, c *Config) string { return c.ClientKey },
+		set:         func(_ *configFile, c *Config, v string) { c.ClientKey = v },
+	},
+	{
+		Name:        "insecure_skip_verify",
+		Description: "Skip TLS certificate verification (local development only)",
+		Default:     "false",
+		validate: func(v string) error {
+			_, err := strconv.ParseBool(v)
+			return err
+		},
+		get: func(_ *configFile, c *Config) string {
+			if c.InsecureSkipVerify {
+				return "true"
+			}
+			return ""
+		},
+		set: func(_ *configFile, c *Config, v string) { c.InsecureSkipVerify, _ = strconv.ParseBool(v) },
+	},
+	{
+		Name:        "timeout",
+		Description: "Timeout for API requests, e.g. 30s or 2m",
+		Default:     "30s",
+		validate: func(v string) error {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("must be a duration such as 30s or 2m")
+			}
+			if d <= 0 {
+				return fmt.Errorf("must be positive")
+			}
+			return nil
+		},
+		get: func(_ *configFile, c *Config) string { return c.Timeout },
+		set: func(_ *configFile, c *Config, v string) { c.Timeout = v },
+	},
+	{
+		Name:        "unix_socket",
+		Description: "Connect to the API over this Unix domain socket",
+		get:         func(_ *configFile, c *Config) string { return c.UnixSocket },
+		set:         func(_ *configFile, c *Config, v string) { c.UnixSocket = v },
+	},
+	{
+		Name:        "credential_store",
+		Description: "Where tokens are kept: auto, keyring, file or plaintext (all profiles)",
+		Default:     credentials.BackendAuto,
+		global:      true,
+		validate: func(v string) error {
+			switch v {
+			case credentials.BackendAuto, credentials.BackendKeyring, credentials.BackendFile, credentials.BackendPlaintext:
+				return nil
+			}
+			return fmt.Errorf("must be one of auto, keyring, file or plaintext")
+		},
+		get: func(f *configFile, _ *Config) string { return f.CredentialStore },
+		set: func(f *configFile, _ *Config, v string) { f.CredentialStore = v },
+	},
+	{
+		Name:        "credential_helper",
+		Description: "External credential helper command (all profiles)",
+		global:      true,
+		get:         func(f *configFile, _ *Config) string { return f.CredentialHelper },
+		set:         func(f *configFile, _ *Config, v string) { f.CredentialHelper = v },
+	},
+}
+
+// Keys returns every global setting, in display order
+func Keys() []Key {
+	return append([]Key(nil), keys...)
+}
+
+// LookupKey returns the global setting called name
+func LookupKey(name string) (*Key, error) {
+	for i := range keys {
+		if keys[i].Name == name {
+			return &keys[i], nil
+		}
+	}
+
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.Name
+	}
+	return nil, fmt.Errorf("unknown config key %q (valid keys: %s)", name, strings.Join(names, ", "))
+}
+
+// Validate checks value before it is stored under the key
+func (k *Key) Validate(value string) error {
+	if k.ReadOnly {
+		return fmt.Errorf("%s is read-only; it is set by 'hopsule login'", k.Name)
+	}
+	if k.validate == nil {
+		return nil
+	}
+	if err := k.validate(value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", k.Name, err)
+	}
+	return nil
+}
+
+// GetValue returns the stored value of a key in the active profile.
+// Environment overrides and defaults are not applied.
+func GetValue(name string) (string, error) {
+	return defaultStore.GetValue(name)
+}
+
+// ListValues returns every global setting with its stored value in the
+// active profile
+func ListValues() ([]KeyValue, error) {
+	return defaultStore.ListValues()
+}
+
+// SetValue validates value and stores it under a key in the active profile
+func SetValue(name, value string) error {
+	return defaultStore.SetValue(name, value)
+}
+
+// UnsetValue removes a key from the active profile, so its default applies
+func UnsetValue(name string) error {
+	return defaultStore.UnsetValue(name)
+}
+
+// ValidateConfigData checks the contents of a global config file: unknown
+// keys, and values that 'hopsule config set' would reject
+func ValidateConfigData(configPath string, data []byte) error {
+	var file configFile
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+
+	profiles := map[string]*Config{DefaultProfile + " (top level)": &file.Legacy}
+	for name, c := range file.Profiles {
+		if c != nil {
+			profiles[name] = c
+		}
+	}
+	for name, c := range profiles {
+		for _, k := range keys {
+			if k.validate == nil || k.set == nil {
+				continue
+			}
+			if value := k.get(&file, c); value != "" {
+				if err := k.validate(value); err != nil {
+					return fmt.Errorf("%s: profile %s: invalid value for %s: %w", configPath, name, k.Name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validBackend(name string) bool {
+	for _, backend := range Backends {
+		if name == backend {
+			return true
+		}
+	}
+	return false
+}
+
+// storedUser returns c's user, or an empty one when not logged in
+func storedUser(c *Config) *User {
+	if c.User == nil {
+		return &User{}
+	}
+	return c.User
+}
+
+func validateFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	return nil
+}
+
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("must be an http:// or https:// URL, got %q", value)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("missing host in %q", value)
+	}
+	return nil
+}
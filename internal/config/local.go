@@ -0,0 +1,223 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectValue is a setting stored in a .hopsule file, as returned by
+// ListProjectValues
+type ProjectValue struct {
+	Key   string
+	Value string
+}
+
+// ProjectKeys returns the .hopsule settings that can be changed with
+// 'hopsule config set --local', as dotted paths
+func ProjectKeys() []string {
+	var names []string
+	collectScalarKeys(hopsuleSchema, "", &names)
+	sort.Strings(names)
+	return names
+}
+
+// GetProjectValue returns a setting from the .hopsule file at configPath
+func GetProjectValue(configPath, key string) (string, error) {
+	if _, err := lookupProjectKey(key); err != nil {
+		return "", err
+	}
+
+	doc, _, err := readProjectDocument(configPath)
+	if err != nil {
+		return "", err
+	}
+
+	node := doc.Content[0]
+	for _, part := range strings.Split(key, ".") {
+		node = mappingValue(node, part)
+	}
+	if node == nil || node.Kind != yaml.ScalarNode || node.Value == "" {
+		return "", fmt.Errorf("%s: %w", key, ErrKeyNotSet)
+	}
+	return node.Value, nil
+}
+
+// ListProjectValues returns every setting in the .hopsule file at
+// configPath. Lists are reported per item, e.g. scopes.0.key.
+func ListProjectValues(configPath string) ([]ProjectValue, error) {
+	doc, _, err := readProjectDocument(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []ProjectValue
+	flattenNode(doc.Content[0], "", &values)
+	return values, nil
+}
+
+// SetProjectValue changes a setting in the .hopsule file at configPath,
+// keeping comments and key order. The file is only written if the result
+// is valid.
+func SetProjectValue(configPath, key, value string) error {
+	schema, err := lookupProjectKey(key)
+	if err != nil {
+		return err
+	}
+	if schema.tag == "!!int" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s must be an integer, got %q", key, value)
+		}
+		value = strconv.Itoa(n)
+	}
+
+	return updateProjectDocument(configPath, func(root *yaml.Node) {
+		parts := strings.Split(key, ".")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			next := mappingValue(node, part)
+			if next == nil || next.Kind != yaml.MappingNode {
+				next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+				setMappingNode(node, part, next)
+			}
+			node = next
+		}
+		setMappingNode(node, parts[len(parts)-1], &yaml.Node{Kind: yaml.ScalarNode, Tag: schema.tag, Value: value})
+	})
+}
+
+// UnsetProjectValue removes a setting from the .hopsule file at configPath.
+// The file is only written if the result is valid.
+func UnsetProjectValue(configPath, key string) error {
+	if _, err := lookupProjectKey(key); err != nil {
+		return err
+	}
+
+	return updateProjectDocument(configPath, func(root *yaml.Node) {
+		parts := strings.Split(key, ".")
+		node := root
+		for _, part := range parts[:len(parts)-1] {
+			if node = mappingValue(node, part); node == nil {
+				return
+			}
+		}
+		removeMappingKey(node, parts[len(parts)-1])
+	})
+}
+
+// lookupProjectKey returns the schema of a settable .hopsule key
+func lookupProjectKey(key string) (*schemaNode, error) {
+	schema := hopsuleSchema
+	for _, part := range strings.Split(key, ".") {
+		if schema.kind != yaml.MappingNode || schema.fields[part] == nil {
+			schema = nil
+			break
+		}
+		schema = schema.fields[part]
+	}
+	if schema == nil || schema.kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("unknown %s key %q (valid keys: %s)", HopsuleFileName, key, strings.Join(ProjectKeys(), ", "))
+	}
+	return schema, nil
+}
+
+func readProjectDocument(configPath string) (*yaml.Node, os.FileMode, error) {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, 0, fmt.Errorf("%s: expected a YAML mapping at the top level", configPath)
+	}
+	return &doc, info.Mode().Perm(), nil
+}
+
+func updateProjectDocument(configPath string, change func(root *yaml.Node)) error {
+	doc, perm, err := readProjectDocument(configPath)
+	if err != nil {
+		return err
+	}
+
+	change(doc.Content[0])
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to serialize: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to serialize: %w", err)
+	}
+
+	if err := ValidateProjectConfigData(configPath, buf.Bytes()); err != nil {
+		return fmt.Errorf("change would make the file invalid: %w", err)
+	}
+
+	return writeFileAtomic(configPath, buf.Bytes(), perm)
+}
+
+// setMappingNode sets key to value, appending it if missing
+func setMappingNode(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			// Keep comments attached to the old value
+			value.LineComment = node.Content[i+1].LineComment
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func removeMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func collectScalarKeys(schema *schemaNode, prefix string, names *[]string) {
+	for name, field := range schema.fields {
+		switch field.kind {
+		case yaml.ScalarNode:
+			*names = append(*names, joinField(prefix, name))
+		case yaml.MappingNode:
+			collectScalarKeys(field, joinField(prefix, name), names)
+		}
+	}
+}
+
+func flattenNode(node *yaml.Node, prefix string, values *[]ProjectValue) {
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			flattenNode(node.Content[i+1], joinField(prefix, node.Content[i].Value), values)
+		}
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			flattenNode(item, fmt.Sprintf("%s.%d", prefix, i), values)
+		}
+	case yaml.AliasNode:
+		flattenNode(node.Alias, prefix, values)
+	case yaml.ScalarNode:
+		*values = append(*values, ProjectValue{Key: prefix, Value: node.Value})
+	}
+}
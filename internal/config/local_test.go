@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetProjectValue(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), HopsuleFileName)
+	original := "# keep me\nversion: 1\nproject:\n  id: p1\n"
+	if err := os.WriteFile(configPath, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetProjectValue(configPath, "project.name", "Billing"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GetProjectValue(configPath, "project.name"); err != nil || got != "Billing" {
+		t.Errorf("project.name = %q, %v; want Billing", got, err)
+	}
+	data, _ := os.ReadFile(configPath)
+	if !strings.HasPrefix(string(data), "# keep me\n") {
+		t.Errorf("comment was lost:\n%s", data)
+	}
+
+	if err := SetProjectValue(configPath, "version", "abc"); err == nil {
+		t.Error("setting version to abc succeeded")
+	}
+	if err := SetProjectValue(configPath, "backend", "ftp"); err == nil {
+		t.Error("setting an unknown backend succeeded")
+	}
+	if err := SetProjectValue(configPath, "project.colour", "red"); err == nil {
+		t.Error("setting an unknown key succeeded")
+	}
+	if err := ValidateProjectConfigData(configPath, mustRead(t, configPath)); err != nil {
+		t.Errorf("rejected values left the file invalid: %v", err)
+	}
+
+	if err := UnsetProjectValue(configPath, "project.name"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetProjectValue(configPath, "project.name"); !errors.Is(err, ErrKeyNotSet) {
+		t.Errorf("after unset got %v, want ErrKeyNotSet", err)
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
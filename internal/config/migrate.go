@@ -0,0 +1,145 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// projectMigration upgrades a .hopsule document from one schema version to the next
+type projectMigration struct {
+	from        int
+	description string
+	apply       func(root *yaml.Node)
+}
+
+// projectMigrations must stay ordered by from version. Each step is applied
+// to the parsed YAML node tree so comments and key order survive, and sets
+// the version it upgrades to. Only add a step, and bump HopsuleFileVersion,
+// when the file's structure changes: a newer version makes older CLIs warn.
+var projectMigrations = []projectMigration{
+	{
+		from:        0,
+		description: "add schema version",
+		apply: func(root *yaml.Node) {
+			setMappingInt(root, "version", 1)
+		},
+	},
+}
+
+// MigrationResult describes what MigrateProjectConfigFile did (or would do)
+type MigrationResult struct {
+	Path        string
+	FromVersion int
+	ToVersion   int
+	Steps       []string
+	Data        []byte
+}
+
+// MigrateProjectConfigData upgrades the contents of a .hopsule file to the
+// current schema version, preserving comments
+func MigrateProjectConfigData(data []byte) (*MigrationResult, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a YAML mapping at the top level")
+	}
+	root := doc.Content[0]
+
+	version := 0
+	if v := mappingValue(root, "version"); v != nil {
+		n, err := strconv.Atoi(v.Value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: version must be an integer, got %q", v.Line, v.Value)
+		}
+		version = n
+	}
+
+	result := &MigrationResult{FromVersion: version, ToVersion: version}
+	if version > HopsuleFileVersion {
+		return nil, fmt.Errorf("schema version %d is newer than this CLI supports (%d); upgrade hopsule instead", version, HopsuleFileVersion)
+	}
+	if version == HopsuleFileVersion {
+		result.Data = data
+		return result, nil
+	}
+
+	for _, m := range projectMigrations {
+		if m.from < version {
+			continue
+		}
+		m.apply(root)
+		result.Steps = append(result.Steps, fmt.Sprintf("v%d → v%d: %s", m.from, m.from+1, m.description))
+		result.ToVersion = m.from + 1
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to serialize: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to serialize: %w", err)
+	}
+	result.Data = buf.Bytes()
+
+	return result, nil
+}
+
+// MigrateProjectConfigFile upgrades a .hopsule file in place. With dryRun the
+// file is left untouched and the migrated contents are returned in Data.
+func MigrateProjectConfigFile(configPath string, dryRun bool) (*MigrationResult, error) {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	result, err := MigrateProjectConfigData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate %s: %w", configPath, err)
+	}
+	result.Path = configPath
+
+	if dryRun || len(result.Steps) == 0 {
+		return result, nil
+	}
+
+	if err := ValidateProjectConfigData(configPath, result.Data); err != nil {
+		return nil, fmt.Errorf("migrated file would be invalid: %w", err)
+	}
+
+	if err := writeFileAtomic(configPath, result.Data, info.Mode().Perm()); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// setMappingInt sets key to an integer value, inserting it first if missing
+func setMappingInt(node *yaml.Node, key string, value int) {
+	if v := mappingValue(node, key); v != nil {
+		v.Kind = yaml.ScalarNode
+		v.Tag = "!!int"
+		v.Value = strconv.Itoa(value)
+		return
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(value)}
+	if len(node.Content) > 0 {
+		// Keep the file's header comment above the new first key
+		keyNode.HeadComment = node.Content[0].HeadComment
+		node.Content[0].HeadComment = ""
+	}
+	node.Content = append([]*yaml.Node{keyNode, valueNode}, node.Content...)
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"sync"
+)
+
+const (
+	appDirName = "hopsule"
+
+	// legacyDirName is where config lived before the XDG layout
+	legacyDirName = ".decision-cli"
+
+	// configDirEnv overrides the config directory, e.g. for tests or
+	// portable installs
+	configDirEnv = "HOPSULE_CONFIG_DIR"
+)
+
+var migrateLegacyOnce sync.Once
+
+// ConfigDir returns the directory holding config.yaml: $HOPSULE_CONFIG_DIR,
+// then $XDG_CONFIG_HOME/hopsule, then ~/.config/hopsule (%AppData%\hopsule
+// on Windows). Settings from ~/.decision-cli are moved there on first use.
+func ConfigDir() (string, error) {
+	dir, err := resolveConfigDir()
+	if err != nil {
+		return "", err
+	}
+	migrateLegacyOnce.Do(func() {
+		migrateLegacyDir(dir)
+	})
+	return dir, nil
+}
+
+// CacheDir returns the directory for data that can be rebuilt from the API:
+// $XDG_CACHE_HOME/hopsule, then ~/.cache/hopsule (%LocalAppData%\hopsule\cache
+// on Windows)
+func CacheDir() (string, error) {
+	return xdgDir("XDG_CACHE_HOME", ".cache", "cache")
+}
+
+// StateDir returns the directory for data that should survive restarts but
+// isn't configuration, such as logs and history: $XDG_STATE_HOME/hopsule,
+// then ~/.local/state/hopsule (%LocalAppData%\hopsule\state on Windows)
+func StateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"), "state")
+}
+
+func resolveConfigDir() (string, error) {
+	if dir := os.Getenv(configDirEnv); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, appDirName), nil
+	}
+	if runtime.GOOS == "windows" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get config directory: %w", err)
+		}
+		return filepath.Join(dir, appDirName), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".config", appDirName), nil
+}
+
+// xdgDir resolves an XDG base directory for hopsule. Relative values are
+// ignored, as the spec requires.
+func xdgDir(env, unixDefault, windowsSubdir string) (string, error) {
+	if dir := os.Getenv(env); dir != "" && filepath.IsAbs(dir) {
+		return filepath.Join(dir, appDirName), nil
+	}
+	if runtime.GOOS == "windows" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to get local app data directory: %w", err)
+		}
+		return filepath.Join(dir, appDirName, windowsSubdir), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, unixDefault, appDirName), nil
+}
+
+// migrateLegacyDir moves files from ~/.decision-cli into dir, unless dir
+// already has a config. Failures are reported but not fatal; whatever
+// wasn't moved stays in the old directory and is retried on the next run.
+func migrateLegacyDir(dir string) {
+	if os.Getenv(configDirEnv) != "" {
+		return
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return
+	}
+	legacy := filepath.Join(homeDir, legacyDirName)
+	if legacy == dir {
+		return
+	}
+
+	entries, err := os.ReadDir(legacy)
+	if err != nil || len(entries) == 0 {
+		return
+	}
+	if _, err := os.Stat(filepath.Join(dir, "config.yaml")); err == nil {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not move %s to %s: %v\n", legacy, dir, err)
+		return
+	}
+	// config.yaml goes last so an interrupted move is picked up again
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Name() != "config.yaml" && entries[j].Name() == "config.yaml"
+	})
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		from := filepath.Join(legacy, entry.Name())
+		to := filepath.Join(dir, entry.Name())
+		if err := moveFile(from, to); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: could not move %s to %s: %v\n", from, to, err)
+			return
+		}
+	}
+
+	// Only removes the directory if nothing else was left in it
+	os.Remove(legacy)
+	fmt.Fprintf(os.Stderr, "Moved settings from %s to %s\n", legacy, dir)
+}
+
+// moveFile renames from to to, copying when they are on different devices
+func moveFile(from, to string) error {
+	if err := os.Rename(from, to); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(to)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(to)
+		return err
+	}
+	return os.Remove(from)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,6 +13,12 @@ import (
 type ProjectConfig struct {
 	Version int            `yaml:"version"`
 	Project ProjectInfo    `yaml:"project"`
+	Scopes  []ScopeConfig  `yaml:"scopes,omitempty"`
+	// Backend overrides the backend setting for this project; "local"
+	// keeps the project's data in the .hopsule directory
+	Backend string `yaml:"backend,omitempty"`
+	// Workspace is set on a monorepo root to map subdirectories to projects
+	Workspace *WorkspaceConfig `yaml:"workspace,omitempty"`
 }
 
 // ProjectInfo contains project identification
@@ -31,9 +38,19 @@ type OrganizationInfo struct {
 
 const (
 	HopsuleFileName    = ".hopsule"
+	// HopsuleFileVersion is the newest .hopsule schema this CLI understands.
+	// Optional sections such as scopes, workspace and backend don't change it.
 	HopsuleFileVersion = 1
+	// HopsuleDirConfigName is the project config inside a .hopsule
+	// directory. Projects using the local backend keep their data next to
+	// it, so .hopsule is a directory rather than a file.
+	HopsuleDirConfigName = "config.yaml"
 )
 
+// ErrProjectConfigNotFound is returned when no .hopsule file exists in the
+// directory or any of its parents
+var ErrProjectConfigNotFound = errors.New("no " + HopsuleFileName + " file found in current directory or any parent")
+
 // LoadProjectConfig loads the .hopsule file from the current directory
 // or searches parent directories up to the filesystem root
 func LoadProjectConfig() (*ProjectConfig, string, error) {
@@ -51,21 +68,14 @@ func LoadProjectConfigFrom(startDir string) (*ProjectConfig, string, error) {
 	dir := startDir
 
 	for {
-		configPath := filepath.Join(dir, HopsuleFileName)
-		
-		if _, err := os.Stat(configPath); err == nil {
+		if configPath, ok := ProjectConfigFile(dir); ok {
 			// Found the file
-			data, err := os.ReadFile(configPath)
+			cfg, err := readProjectConfig(configPath)
 			if err != nil {
-				return nil, "", fmt.Errorf("failed to read %s: %w", configPath, err)
-			}
-
-			var cfg ProjectConfig
-			if err := yaml.Unmarshal(data, &cfg); err != nil {
-				return nil, "", fmt.Errorf("failed to parse %s: %w", configPath, err)
+				return nil, "", err
 			}
 
-			return &cfg, configPath, nil
+			return cfg, configPath, nil
 		}
 
 		// Move to parent directory
@@ -77,7 +87,27 @@ func LoadProjectConfigFrom(startDir string) (*ProjectConfig, string, error) {
 		dir = parent
 	}
 
-	return nil, "", fmt.Errorf("no %s file found in current directory or any parent", HopsuleFileName)
+	return nil, "", ErrProjectConfigNotFound
+}
+
+// readProjectConfig reads and parses a single .hopsule file
+func readProjectConfig(configPath string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	if err := ValidateProjectConfigData(configPath, data); err != nil {
+		return nil, err
+	}
+
+	var cfg ProjectConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+	warnIfNewerVersion(configPath, cfg.Version)
+
+	return &cfg, nil
 }
 
 // SaveProjectConfig saves the .hopsule file to the specified directory
@@ -91,17 +121,13 @@ func SaveProjectConfig(dir string, cfg *ProjectConfig) error {
 		return fmt.Errorf("failed to serialize config: %w", err)
 	}
 
-	configPath := filepath.Join(dir, HopsuleFileName)
-	
+	configPath, _ := ProjectConfigFile(dir)
+
 	// Add header comment
 	header := "# Hopsule Project Configuration\n# This file connects your local project to Hopsule.\n# Do not edit manually unless you know what you're doing.\n\n"
 	finalData := append([]byte(header), data...)
 
-	if err := os.WriteFile(configPath, finalData, 0644); err != nil {
-		return fmt.Errorf("failed to write %s: %w", configPath, err)
-	}
-
-	return nil
+	return writeFileAtomic(configPath, finalData, 0644)
 }
 
 // ProjectConfigExists checks if a .hopsule file exists in the current directory
@@ -111,9 +137,8 @@ func ProjectConfigExists() bool {
 		return false
 	}
 
-	configPath := filepath.Join(dir, HopsuleFileName)
-	_, err = os.Stat(configPath)
-	return err == nil
+	_, ok := ProjectConfigFile(dir)
+	return ok
 }
 
 // GetProjectConfigPath returns the path where .hopsule would be created
@@ -122,5 +147,22 @@ func GetProjectConfigPath() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to get current directory: %w", err)
 	}
-	return filepath.Join(dir, HopsuleFileName), nil
+	configPath, _ := ProjectConfigFile(dir)
+	return configPath, nil
+}
+
+// ProjectConfigFile returns the project config path for dir: the .hopsule
+// file, or config.yaml inside a .hopsule directory. ok reports whether it
+// exists.
+func ProjectConfigFile(dir string) (path string, ok bool) {
+	path = filepath.Join(dir, HopsuleFileName)
+	info, err := os.Stat(path)
+	if err != nil {
+		return path, false
+	}
+	if info.IsDir() {
+		path = filepath.Join(path, HopsuleDirConfigName)
+		_, err = os.Stat(path)
+	}
+	return path, err == nil
 }
@@ -0,0 +1,348 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SchemaIssue is a single problem found while validating a .hopsule file
+type SchemaIssue struct {
+	Line    int
+	Column  int
+	Field   string
+	Message string
+}
+
+// SchemaError is returned when a .hopsule file does not match the schema
+type SchemaError struct {
+	Path   string
+	Issues []SchemaIssue
+}
+
+func (e *SchemaError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "invalid %s:", e.Path)
+	for _, issue := range e.Issues {
+		fmt.Fprintf(&sb, "\n  %s:%d:%d: ", e.Path, issue.Line, issue.Column)
+		if issue.Field != "" {
+			fmt.Fprintf(&sb, "%s: ", issue.Field)
+		}
+		sb.WriteString(issue.Message)
+	}
+	return sb.String()
+}
+
+// schemaNode describes the expected shape of one YAML node
+type schemaNode struct {
+	kind     yaml.Kind
+	tag      string // for scalars: "!!str", "!!int"
+	fields   map[string]*schemaNode
+	required []string
+	items    *schemaNode
+}
+
+var (
+	stringSchema = &schemaNode{kind: yaml.ScalarNode, tag: "!!str"}
+	intSchema    = &schemaNode{kind: yaml.ScalarNode, tag: "!!int"}
+
+	organizationSchema = &schemaNode{
+		kind: yaml.MappingNode,
+		fields: map[string]*schemaNode{
+			"id":   stringSchema,
+			"slug": stringSchema,
+			"name": stringSchema,
+		},
+	}
+
+	projectSchema = &schemaNode{
+		kind: yaml.MappingNode,
+		fields: map[string]*schemaNode{
+			"id":           stringSchema,
+			"slug":         stringSchema,
+			"name":         stringSchema,
+			"organization": organizationSchema,
+		},
+	}
+
+	hopsuleSchema = &schemaNode{
+		kind: yaml.MappingNode,
+		fields: map[string]*schemaNode{
+			"version": intSchema,
+			"project": projectSchema,
+			"backend": stringSchema,
+			"scopes": {
+				kind: yaml.SequenceNode,
+				items: &schemaNode{
+					kind:     yaml.MappingNode,
+					required: []string{"key", "paths"},
+					fields: map[string]*schemaNode{
+						"key":         stringSchema,
+						"paths":       {kind: yaml.SequenceNode, items: stringSchema},
+						"description": stringSchema,
+					},
+				},
+			},
+			"workspace": {
+				kind: yaml.MappingNode,
+				fields: map[string]*schemaNode{
+					"projects": {
+						kind: yaml.SequenceNode,
+						items: &schemaNode{
+							kind:     yaml.MappingNode,
+							required: []string{"path", "project"},
+							fields: map[string]*schemaNode{
+								"path":    stringSchema,
+								"project": projectSchema,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+)
+
+// ValidateProjectConfigData checks the contents of a .hopsule file against
+// the schema and returns a *SchemaError listing every problem with its line
+// and column. Unknown keys are only reported for versions this CLI understands.
+func ValidateProjectConfigData(configPath string, data []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+	if len(doc.Content) == 0 {
+		return &SchemaError{Path: configPath, Issues: []SchemaIssue{{Line: 1, Column: 1, Message: "file is empty"}}}
+	}
+
+	root := doc.Content[0]
+	version := 0
+	if v := mappingValue(root, "version"); v != nil {
+		version, _ = strconv.Atoi(v.Value)
+	}
+
+	var issues []SchemaIssue
+	validateNode(root, hopsuleSchema, "", version > HopsuleFileVersion, &issues)
+
+	if len(issues) == 0 {
+		issues = append(issues, validateSemantics(root)...)
+	}
+
+	if len(issues) > 0 {
+		return &SchemaError{Path: configPath, Issues: issues}
+	}
+	return nil
+}
+
+func validateNode(node *yaml.Node, schema *schemaNode, field string, allowUnknown bool, issues *[]SchemaIssue) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	if node.Kind != schema.kind {
+		*issues = append(*issues, SchemaIssue{
+			Line:    node.Line,
+			Column:  node.Column,
+			Field:   field,
+			Message: fmt.Sprintf("expected %s, got %s", kindName(schema.kind, schema.tag), kindName(node.Kind, node.Tag)),
+		})
+		return
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		if schema.tag == "!!int" && !isInt(node) {
+			*issues = append(*issues, SchemaIssue{
+				Line: node.Line, Column: node.Column, Field: field,
+				Message: fmt.Sprintf("expected integer, got %q", node.Value),
+			})
+		}
+
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			validateNode(item, schema.items, fmt.Sprintf("%s[%d]", field, i), allowUnknown, issues)
+		}
+
+	case yaml.MappingNode:
+		present := make(map[string]bool)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			childField := joinField(field, key.Value)
+			if present[key.Value] {
+				*issues = append(*issues, SchemaIssue{
+					Line: key.Line, Column: key.Column, Field: childField,
+					Message: "duplicate key",
+				})
+				continue
+			}
+			present[key.Value] = true
+
+			childSchema, ok := schema.fields[key.Value]
+			if !ok {
+				if !allowUnknown {
+					*issues = append(*issues, SchemaIssue{
+						Line: key.Line, Column: key.Column, Field: childField,
+						Message: "unknown key",
+					})
+				}
+				continue
+			}
+			if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+				continue
+			}
+			validateNode(value, childSchema, childField, allowUnknown, issues)
+		}
+		for _, req := range schema.required {
+			if !present[req] {
+				*issues = append(*issues, SchemaIssue{
+					Line: node.Line, Column: node.Column, Field: joinField(field, req),
+					Message: "required key is missing",
+				})
+			}
+		}
+	}
+}
+
+// validateSemantics checks rules the structural schema can't express
+func validateSemantics(root *yaml.Node) []SchemaIssue {
+	var issues []SchemaIssue
+
+	project := mappingValue(root, "project")
+	workspace := mappingValue(root, "workspace")
+	if workspace == nil && (project == nil || mappingValue(project, "id") == nil || mappingValue(project, "id").Value == "") {
+		line, col := root.Line, root.Column
+		if project != nil {
+			line, col = project.Line, project.Column
+		}
+		issues = append(issues, SchemaIssue{
+			Line: line, Column: col, Field: "project.id",
+			Message: "project id is required unless the file declares a workspace",
+		})
+	}
+
+	if backend := mappingValue(root, "backend"); backend != nil && backend.Value != "" && !validBackend(backend.Value) {
+		issues = append(issues, SchemaIssue{
+			Line: backend.Line, Column: backend.Column, Field: "backend",
+			Message: fmt.Sprintf("unknown backend %q (use %s)", backend.Value, strings.Join(Backends, " or ")),
+		})
+	}
+
+	if scopes := mappingValue(root, "scopes"); scopes != nil {
+		seen := make(map[string]bool)
+		for i, scope := range scopes.Content {
+			key := mappingValue(scope, "key")
+			if key == nil {
+				continue
+			}
+			if seen[key.Value] {
+				issues = append(issues, SchemaIssue{
+					Line: key.Line, Column: key.Column, Field: fmt.Sprintf("scopes[%d].key", i),
+					Message: fmt.Sprintf("scope %q is declared more than once", key.Value),
+				})
+			}
+			seen[key.Value] = true
+
+			if paths := mappingValue(scope, "paths"); paths != nil {
+				for j, p := range paths.Content {
+					if _, err := path.Match(p.Value, ""); err != nil {
+						issues = append(issues, SchemaIssue{
+							Line: p.Line, Column: p.Column, Field: fmt.Sprintf("scopes[%d].paths[%d]", i, j),
+							Message: fmt.Sprintf("invalid glob %q", p.Value),
+						})
+					}
+				}
+			}
+		}
+	}
+
+	if workspace != nil {
+		if projects := mappingValue(workspace, "projects"); projects != nil {
+			for i, wp := range projects.Content {
+				p := mappingValue(wp, "path")
+				if p == nil {
+					continue
+				}
+				clean := path.Clean(p.Value)
+				if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+					issues = append(issues, SchemaIssue{
+						Line: p.Line, Column: p.Column, Field: fmt.Sprintf("workspace.projects[%d].path", i),
+						Message: "path must be relative and inside the workspace",
+					})
+				}
+			}
+		}
+	}
+
+	return issues
+}
+
+var warnedNewerVersion sync.Map
+
+// warnIfNewerVersion prints a one-time warning when a .hopsule file was
+// written by a newer CLI than this one
+func warnIfNewerVersion(configPath string, version int) {
+	if version <= HopsuleFileVersion {
+		return
+	}
+	if _, loaded := warnedNewerVersion.LoadOrStore(configPath, true); loaded {
+		return
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s uses schema version %d, but this CLI only understands up to version %d.\n", configPath, version, HopsuleFileVersion)
+	fmt.Fprintln(os.Stderr, "         Some settings may be ignored. Upgrade hopsule to use them.")
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// isInt reports whether a scalar is an integer. The tag alone isn't enough:
+// an explicit !!int tag can sit on any value.
+func isInt(node *yaml.Node) bool {
+	if node.Tag != "!!int" {
+		return false
+	}
+	_, err := strconv.Atoi(node.Value)
+	return err == nil
+}
+
+func joinField(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func kindName(kind yaml.Kind, tag string) string {
+	switch kind {
+	case yaml.MappingNode:
+		return "mapping"
+	case yaml.SequenceNode:
+		return "list"
+	case yaml.ScalarNode:
+		switch tag {
+		case "!!int":
+			return "integer"
+		case "!!str":
+			return "string"
+		case "!!bool":
+			return "boolean"
+		case "!!null":
+			return "null"
+		}
+		return "scalar"
+	}
+	return "unknown"
+}
@@ -0,0 +1,133 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateProjectConfigData(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		// issues are substrings of the expected issues, in order; none
+		// means the file is valid
+		issues []string
+	}{
+		{
+			name: "valid",
+			data: "version: 1\nproject:\n  id: p1\nscopes:\n  - key: api\n    paths: [services/api]\n",
+		},
+		{
+			name: "workspace without project id",
+			data: "version: 1\nworkspace:\n  projects:\n    - path: web\n      project:\n        id: p2\n",
+		},
+		{
+			name:   "non-numeric version",
+			data:   "version: abc\nproject:\n  id: p1\n",
+			issues: []string{"1:10: version: expected integer"},
+		},
+		{
+			name:   "non-numeric version tagged as integer",
+			data:   "version: !!int abc\nproject:\n  id: p1\n",
+			issues: []string{"1:10: version: expected integer"},
+		},
+		{
+			name:   "unknown key",
+			data:   "version: 1\nproject:\n  id: p1\n  colour: red\n",
+			issues: []string{"4:3: project.colour: unknown key"},
+		},
+		{
+			name:   "missing project id",
+			data:   "version: 1\nproject:\n  name: p\n",
+			issues: []string{"project.id: project id is required"},
+		},
+		{
+			name:   "scope without paths",
+			data:   "version: 1\nproject:\n  id: p1\nscopes:\n  - key: api\n",
+			issues: []string{"scopes[0].paths: required key is missing"},
+		},
+		{
+			name:   "invalid glob and duplicate scope",
+			data:   "version: 1\nproject:\n  id: p1\nscopes:\n  - key: api\n    paths: ['[']\n  - key: api\n    paths: [x]\n",
+			issues: []string{"scopes[0].paths[0]: invalid glob", "scopes[1].key: scope \"api\" is declared more than once"},
+		},
+		{
+			name:   "workspace path outside the workspace",
+			data:   "version: 1\nworkspace:\n  projects:\n    - path: ../web\n      project:\n        id: p2\n",
+			issues: []string{"workspace.projects[0].path: path must be relative"},
+		},
+		{
+			name:   "unknown backend",
+			data:   "version: 1\nproject:\n  id: p1\nbackend: ftp\n",
+			issues: []string{"backend: unknown backend \"ftp\""},
+		},
+		{
+			name: "newer version allows unknown keys",
+			data: "version: 99\nproject:\n  id: p1\nfuture: true\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProjectConfigData(".hopsule", []byte(tt.data))
+			if len(tt.issues) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("got %v, want a *SchemaError", err)
+			}
+			if len(schemaErr.Issues) != len(tt.issues) {
+				t.Fatalf("got %d issues, want %d:\n%v", len(schemaErr.Issues), len(tt.issues), err)
+			}
+			lines := strings.Split(err.Error(), "\n")[1:]
+			for i, want := range tt.issues {
+				if !strings.Contains(lines[i], want) {
+					t.Errorf("issue %d = %q, want it to contain %q", i, lines[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateProjectConfigData(t *testing.T) {
+	data := "# Project settings\nproject:\n  id: p1 # the server ID\n"
+
+	result, err := MigrateProjectConfigData([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.FromVersion != 0 || result.ToVersion != HopsuleFileVersion {
+		t.Errorf("migrated %d → %d, want 0 → %d", result.FromVersion, result.ToVersion, HopsuleFileVersion)
+	}
+	if len(result.Steps) != 1 {
+		t.Errorf("steps = %v, want one", result.Steps)
+	}
+	want := "# Project settings\nversion: 1\nproject:\n  id: p1 # the server ID\n"
+	if string(result.Data) != want {
+		t.Errorf("migrated file:\n%s\nwant:\n%s", result.Data, want)
+	}
+
+	// Migrating again is a no-op
+	again, err := MigrateProjectConfigData(result.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Steps) != 0 || string(again.Data) != want {
+		t.Errorf("second migration changed the file: %v\n%s", again.Steps, again.Data)
+	}
+}
+
+func TestMigrateProjectConfigDataRejectsNewerVersion(t *testing.T) {
+	if _, err := MigrateProjectConfigData([]byte("version: 99\n")); err == nil {
+		t.Fatal("migrating a newer version succeeded")
+	}
+	if _, err := MigrateProjectConfigData([]byte("version: abc\n")); err == nil {
+		t.Fatal("migrating a non-numeric version succeeded")
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ScopeConfig maps a named decision scope to one or more path globs,
+// relative to the directory containing the .hopsule file
+type ScopeConfig struct {
+	Key         string   `yaml:"key"`
+	Paths       []string `yaml:"paths"`
+	Description string   `yaml:"description,omitempty"`
+}
+
+// ScopeFor returns the scope key for dir, where root is the directory that
+// holds the .hopsule file. When several scopes match, the most specific
+// pattern wins. An empty string means the directory is not scoped.
+func (p *ProjectConfig) ScopeFor(root, dir string) string {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || outsideRoot(rel) {
+		return ""
+	}
+	rel = filepath.ToSlash(rel)
+
+	best := ""
+	bestLen := -1
+	for _, scope := range p.Scopes {
+		for _, pattern := range scope.Paths {
+			pattern = strings.Trim(filepath.ToSlash(pattern), "/")
+			if matchScopePath(pattern, rel) && len(pattern) > bestLen {
+				best = scope.Key
+				bestLen = len(pattern)
+			}
+		}
+	}
+	return best
+}
+
+// outsideRoot reports whether rel, a path made relative with filepath.Rel,
+// leaves the root. Names that merely start with "..", like "..cache", stay
+// inside it.
+func outsideRoot(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// HasScope reports whether key is declared in the .hopsule file
+func (p *ProjectConfig) HasScope(key string) bool {
+	for _, scope := range p.Scopes {
+		if scope.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// CurrentScope returns the scope key for the current working directory.
+// It returns an empty key when there is no .hopsule file or no scope matches.
+func CurrentScope() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("failed to get current directory: %w", err)
+	}
+
+	projectCfg, configPath, err := LoadProjectConfigFrom(dir)
+	if errors.Is(err, ErrProjectConfigNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return projectCfg.ScopeFor(filepath.Dir(configPath), dir), nil
+}
+
+// matchScopePath matches a slash-separated glob against a relative path.
+// A pattern also matches every path below the directory it names, and "**"
+// matches any number of path segments.
+func matchScopePath(pattern, rel string) bool {
+	if pattern == "" || pattern == "." {
+		return true
+	}
+	if rel == "." {
+		rel = ""
+	}
+
+	var relParts []string
+	if rel != "" {
+		relParts = strings.Split(rel, "/")
+	}
+	return matchSegments(strings.Split(pattern, "/"), relParts)
+}
+
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(parts) == 0 {
+		return false
+	}
+
+	ok, err := path.Match(pattern[0], parts[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchScopePath(t *testing.T) {
+	tests := []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"services/api", "services/api", true},
+		{"services/api", "services/api/handlers", true},
+		{"services/api", "services", false},
+		{"services/api", "services/apigw", false},
+		{"services/*", "services/billing/db", true},
+		{"services/*", "web", false},
+		{"**/migrations", "db/migrations", true},
+		{"**/migrations", "migrations", true},
+		{"**/migrations", "a/b/migrations/v1", true},
+		{"**/migrations", "a/b/seeds", false},
+		{"web/**/*.tsx", "web/src/app.tsx", true},
+		{"", "anything", true},
+		{".", ".", true},
+		{"services/api", ".", false},
+		{"[", "x", false},
+	}
+	for _, tt := range tests {
+		if got := matchScopePath(tt.pattern, tt.rel); got != tt.want {
+			t.Errorf("matchScopePath(%q, %q) = %v, want %v", tt.pattern, tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestScopeForPicksMostSpecificPattern(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	cfg := &ProjectConfig{Scopes: []ScopeConfig{
+		{Key: "backend", Paths: []string{"services/*"}},
+		{Key: "api", Paths: []string{"services/api/"}},
+		{Key: "db", Paths: []string{"**/migrations"}},
+		{Key: "dots", Paths: []string{"..cache"}},
+	}}
+
+	tests := []struct {
+		dir, want string
+	}{
+		{"/repo/services/api/handlers", "api"},
+		{"/repo/services/billing", "backend"},
+		{"/repo/services/billing/migrations", "db"},
+		{"/repo/docs", ""},
+		{"/repo/..cache", "dots"},
+		{"/repo/..cache/x", "dots"},
+		{"/repo", ""},
+		{"/elsewhere/services/api", ""},
+	}
+	for _, tt := range tests {
+		if got := cfg.ScopeFor(root, filepath.FromSlash(tt.dir)); got != tt.want {
+			t.Errorf("ScopeFor(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,710 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Layer identifies where the value of a setting came from
+type Layer int
+
+const (
+	LayerDefault Layer = iota
+	LayerFile
+	LayerEnv
+	LayerFlag
+)
+
+func (l Layer) String() string {
+	switch l {
+	case LayerFile:
+		return "file"
+	case LayerEnv:
+		return "env"
+	case LayerFlag:
+		return "flag"
+	default:
+		return "default"
+	}
+}
+
+// Overrides are settings given on the command line. They take precedence
+// over the environment and are never written to the config file.
+type Overrides struct {
+	APIURL  string
+	Token   string
+	Project string
+
+	Proxy      string
+	CAFile     string
+	ClientCert string
+	ClientKey  string
+	Insecure   bool
+	Timeout    string
+	UnixSocket string
+}
+
+func (o Overrides) values() []struct{ key, value string } {
+	insecure := ""
+	if o.Insecure {
+		insecure = "true"
+	}
+	return []struct{ key, value string }{
+		{"api_url", o.APIURL},
+		{"token", o.Token},
+		{"project", o.Project},
+		{"proxy", o.Proxy},
+		{"ca_file", o.CAFile},
+		{"client_cert", o.ClientCert},
+		{"client_key", o.ClientKey},
+		{"insecure_skip_verify", insecure},
+		{"timeout", o.Timeout},
+		{"unix_socket", o.UnixSocket},
+	}
+}
+
+// Store reads and writes one config directory. Each store keeps its own
+// profile selection, overrides and cache, so several can be used in one
+// process.
+type Store struct {
+	// dir is the config directory; empty means ConfigDir()
+	dir     string
+	env     func(string) string
+	profile string
+	flags   Overrides
+
+	mu sync.Mutex
+	// current is the config returned by Config, loaded on first use
+	current *Config
+	// layers records how each config returned by Load was assembled, so
+	// Save only writes what the caller changed
+	layers map[*Config]*configLayers
+}
+
+// configLayers is the file value of a loaded config and the result of
+// applying env and flag overrides and defaults on top of it
+type configLayers struct {
+	file    Config
+	merged  Config
+	origins map[string]Layer
+	// secretsLoaded is false when the credential store couldn't be read;
+	// Save then leaves the stored token alone unless it was changed
+	secretsLoaded bool
+	// persist lists keys to write even though they came from env or flags
+	persist map[string]bool
+}
+
+// NewStore returns a store for the config directory dir, or for ConfigDir()
+// when dir is empty
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:    dir,
+		env:    os.Getenv,
+		layers: make(map[*Config]*configLayers),
+	}
+}
+
+// WithEnv replaces the environment lookup, e.g. to load a config without
+// the process environment
+func (s *Store) WithEnv(lookup func(string) string) *Store {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.env = lookup
+	s.current = nil
+	return s
+}
+
+// SetProfile selects the profile to load, overriding HOPSULE_PROFILE and
+// the current profile stored in the config file
+func (s *Store) SetProfile(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.profile = name
+	s.current = nil
+}
+
+// SetOverrides sets the command line overrides applied on load
+func (s *Store) SetOverrides(o Overrides) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flags = o
+	s.current = nil
+}
+
+// Dir returns the config directory
+func (s *Store) Dir() (string, error) {
+	if s.dir != "" {
+		return s.dir, nil
+	}
+	return ConfigDir()
+}
+
+// Path returns the path of the config file
+func (s *Store) Path() (string, error) {
+	dir, err := s.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
+}
+
+// Config returns the loaded config, loading it on first use
+func (s *Store) Config() (*Config, error) {
+	s.mu.Lock()
+	current := s.current
+	s.mu.Unlock()
+	if current != nil {
+		return current, nil
+	}
+	return s.Load()
+}
+
+// Load reads the active profile and applies env overrides, flag overrides
+// and defaults, in that order
+func (s *Store) Load() (*Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.Dir()
+	if err != nil {
+		return nil, err
+	}
+	file, err := s.readFile()
+	if err != nil {
+		return nil, err
+	}
+
+	name := s.activeProfileName(file)
+	stored := file.profile(name)
+	if stored == nil {
+		if name != DefaultProfile {
+			return nil, fmt.Errorf("profile %q does not exist (run 'hopsule profile list')", name)
+		}
+		stored = &Config{}
+	}
+	stored.Profile = name
+
+	layers := &configLayers{origins: map[string]Layer{}, secretsLoaded: true}
+	if stored.Token == "" && stored.TokenRef != "" {
+		if err := file.loadSecrets(dir, name, stored); err != nil {
+			layers.secretsLoaded = false
+			fmt.Fprintf(os.Stderr, "Warning: could not read token for profile %s: %v\n", name, err)
+		}
+	}
+	layers.file = *stored
+
+	c := *stored
+	for _, k := range keys {
+		if k.set != nil && k.get(file, &c) != "" {
+			layers.origins[k.Name] = LayerFile
+		}
+	}
+	for _, override := range envOverrides {
+		if value := s.getenv(override.name); value != "" {
+			applyOverride(&c, override.key, value)
+			layers.origins[override.key] = LayerEnv
+		}
+	}
+	for _, override := range s.flags.values() {
+		if override.value != "" {
+			applyOverride(&c, override.key, override.value)
+			layers.origins[override.key] = LayerFlag
+		}
+	}
+
+	// Defaults
+	if c.APIURL == "" {
+		c.APIURL = defaultAPIURL
+	}
+	if c.WebURL == "" {
+		c.WebURL = defaultWebURL
+	}
+
+	layers.merged = c
+	layers.merged.User = copyUser(c.User)
+
+	// Ensure config directory exists for future writes
+	if err := os.MkdirAll(dir, 0700); err != nil { // 0700 for security
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	loaded := &c
+	s.layers[loaded] = layers
+	s.current = loaded
+	return loaded, nil
+}
+
+// Origin reports which layer the value of key in a loaded config came from
+func (s *Store) Origin(c *Config, key string) Layer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if layers, ok := s.layers[c]; ok {
+		return layers.origins[key]
+	}
+	return LayerFile
+}
+
+// Persist makes the next Save of c write the current values of keys, even
+// if they came from an env var or flag
+func (s *Store) Persist(c *Config, keys ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	layers, ok := s.layers[c]
+	if !ok {
+		return
+	}
+	if layers.persist == nil {
+		layers.persist = map[string]bool{}
+	}
+	for _, key := range keys {
+		layers.persist[key] = true
+	}
+}
+
+// Save writes c to its profile. For a config returned by Load only the
+// settings changed since then are written, so env and flag overrides and
+// defaults stay out of the file.
+func (s *Store) Save(c *Config) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.Dir()
+	if err != nil {
+		return err
+	}
+	file, err := s.readFile()
+	if err != nil {
+		return err
+	}
+
+	name := c.Profile
+	if name == "" {
+		name = s.activeProfileName(file)
+		c.Profile = name
+	}
+
+	layers, ok := s.layers[c]
+	if !ok || layers.file.Profile != name {
+		if err := file.saveProfile(dir, name, c); err != nil {
+			return err
+		}
+		return s.writeFile(file)
+	}
+
+	stored := layers.file
+	changed := c.changedSince(&layers.merged)
+	for key := range layers.persist {
+		changed[key] = true
+	}
+	for _, k := range keys {
+		if changed[k.Name] && k.set != nil {
+			k.set(file, &stored, k.get(file, c))
+		}
+	}
+	// Keep the refresh token and expiry that go with the token
+	stored.RefreshToken, stored.TokenExpiresAt = c.RefreshToken, c.TokenExpiresAt
+	if !changed["token"] && !changed["refresh_token"] && !changed["token_expires_at"] {
+		stored.RefreshToken, stored.TokenExpiresAt = layers.file.RefreshToken, layers.file.TokenExpiresAt
+	}
+	if changed["user"] {
+		stored.User = copyUser(c.User)
+	}
+
+	secretsChanged := changed["token"] || changed["refresh_token"]
+	if layers.secretsLoaded || secretsChanged {
+		if err := file.saveProfile(dir, name, &stored); err != nil {
+			return err
+		}
+	} else {
+		// The token couldn't be read, so storing would erase it
+		copied := stored
+		file.Profiles[name] = &copied
+	}
+	if err := s.writeFile(file); err != nil {
+		return err
+	}
+
+	c.TokenRef = stored.TokenRef
+	layers.file = stored
+	layers.merged = *c
+	layers.merged.User = copyUser(c.User)
+	layers.persist = nil
+	layers.secretsLoaded = layers.secretsLoaded || secretsChanged
+	return nil
+}
+
+// ClearAuth clears authentication data from c and saves it
+func (s *Store) ClearAuth(c *Config) error {
+	c.Token = ""
+	c.RefreshToken = ""
+	c.TokenExpiresAt = ""
+	c.User = nil
+	return s.Save(c)
+}
+
+// Profiles returns every stored profile, sorted by name
+func (s *Store) Profiles() ([]ProfileInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.readFile()
+	if err != nil {
+		return nil, err
+	}
+
+	current := s.activeProfileName(file)
+	if _, ok := file.Profiles[current]; !ok && current == DefaultProfile {
+		file.Profiles[DefaultProfile] = &Config{}
+	}
+
+	var profiles []ProfileInfo
+	for name, c := range file.Profiles {
+		profiles = append(profiles, ProfileInfo{Name: name, Current: name == current, Config: c})
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles, nil
+}
+
+// UseProfile makes name the profile used when no profile is selected with
+// SetProfile or HOPSULE_PROFILE
+func (s *Store) UseProfile(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.readFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Profiles[name]; !ok && name != DefaultProfile {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+
+	file.CurrentProfile = name
+	s.current = nil
+	return s.writeFile(file)
+}
+
+// AddProfile stores a new profile
+func (s *Store) AddProfile(name string, c *Config) error {
+	if name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.Dir()
+	if err != nil {
+		return err
+	}
+	file, err := s.readFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Profiles[name]; ok {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+
+	// saveProfile moves any token into the credential store, so secrets
+	// never reach the config file
+	c.Profile = name
+	if err := file.saveProfile(dir, name, c); err != nil {
+		return err
+	}
+	return s.writeFile(file)
+}
+
+// RemoveProfile deletes a stored profile and its stored token. If it was
+// the current profile, the default profile becomes current.
+func (s *Store) RemoveProfile(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.Dir()
+	if err != nil {
+		return err
+	}
+	file, err := s.readFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := file.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+
+	removed := file.Profiles[name]
+	if removed != nil && removed.TokenRef != "" {
+		removed.Token = ""
+		if err := file.storeToken(dir, name, removed); err != nil {
+			return fmt.Errorf("failed to remove stored token: %w", err)
+		}
+	}
+
+	delete(file.Profiles, name)
+	if file.CurrentProfile == name {
+		file.CurrentProfile = ""
+	}
+	s.current = nil
+	return s.writeFile(file)
+}
+
+// GetValue returns the stored value of a key in the active profile.
+// Environment overrides and defaults are not applied.
+func (s *Store) GetValue(name string) (string, error) {
+	key, err := LookupKey(name)
+	if err != nil {
+		return "", err
+	}
+
+	file, c, err := s.readProfile()
+	if err != nil {
+		return "", err
+	}
+
+	value := key.get(file, c)
+	if value == "" {
+		return "", fmt.Errorf("%s: %w", name, ErrKeyNotSet)
+	}
+	return value, nil
+}
+
+// ListValues returns every global setting with its stored value in the
+// active profile
+func (s *Store) ListValues() ([]KeyValue, error) {
+	file, c, err := s.readProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]KeyValue, len(keys))
+	for i, k := range keys {
+		values[i] = KeyValue{Key: k, Value: k.get(file, c)}
+	}
+	return values, nil
+}
+
+// SetValue validates value and stores it under a key in the active profile
+func (s *Store) SetValue(name, value string) error {
+	key, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+	if err := key.Validate(value); err != nil {
+		return err
+	}
+	return s.updateProfile(func(f *configFile, c *Config) {
+		key.set(f, c, value)
+	})
+}
+
+// UnsetValue removes a key from the active profile, so its default applies
+func (s *Store) UnsetValue(name string) error {
+	key, err := LookupKey(name)
+	if err != nil {
+		return err
+	}
+	if key.ReadOnly {
+		return fmt.Errorf("%s is read-only; use 'hopsule logout --local' to clear login data", key.Name)
+	}
+	return s.updateProfile(func(f *configFile, c *Config) {
+		key.set(f, c, "")
+	})
+}
+
+// readProfile reads the active profile as stored on disk, with its secrets
+// loaded from the credential store
+func (s *Store) readProfile() (*configFile, *Config, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.readFile()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := s.activeProfileName(file)
+	c := file.profile(name)
+	if c == nil {
+		if name != DefaultProfile {
+			return nil, nil, fmt.Errorf("profile %q does not exist (run 'hopsule profile list')", name)
+		}
+		c = &Config{}
+	}
+	c.Profile = name
+
+	if c.TokenRef != "" {
+		dir, err := s.Dir()
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := file.loadSecrets(dir, name, c); err != nil {
+			return nil, nil, fmt.Errorf("failed to read token for profile %s: %w", name, err)
+		}
+	}
+
+	return file, c, nil
+}
+
+// updateProfile applies change to the stored active profile and writes the
+// config file
+func (s *Store) updateProfile(change func(f *configFile, c *Config)) error {
+	file, c, err := s.readProfile()
+	if err != nil {
+		return err
+	}
+
+	change(file, c)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dir, err := s.Dir()
+	if err != nil {
+		return err
+	}
+	if err := file.saveProfile(dir, c.Profile, c); err != nil {
+		return err
+	}
+	s.current = nil
+	return s.writeFile(file)
+}
+
+// activeProfileName picks the profile: SetProfile (--profile), then
+// HOPSULE_PROFILE, then the current profile stored in the file
+func (s *Store) activeProfileName(f *configFile) string {
+	if s.profile != "" {
+		return s.profile
+	}
+	if name := s.env("HOPSULE_PROFILE"); name != "" {
+		return name
+	}
+	if f.CurrentProfile != "" {
+		return f.CurrentProfile
+	}
+	return DefaultProfile
+}
+
+// readFile reads config.yaml as stored on disk, without env overrides or
+// defaults, moving legacy top-level settings into the default profile
+func (s *Store) readFile() (*configFile, error) {
+	path, err := s.Path()
+	if err != nil {
+		return nil, err
+	}
+
+	file := &configFile{}
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if err == nil {
+		if err := yaml.Unmarshal(data, file); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	if file.Profiles == nil {
+		file.Profiles = make(map[string]*Config)
+	}
+	if file.Legacy != (Config{}) {
+		if _, ok := file.Profiles[DefaultProfile]; !ok {
+			legacy := file.Legacy
+			file.Profiles[DefaultProfile] = &legacy
+		}
+		file.Legacy = Config{}
+	}
+
+	return file, nil
+}
+
+func (s *Store) writeFile(file *configFile) error {
+	path, err := s.Path()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil { // 0700 for security
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(file); err != nil {
+		return fmt.Errorf("failed to serialize config: %w", err)
+	}
+
+	// Always 0600, also when replacing a file older versions created
+	// world-readable
+	return writeFileAtomic(path, buf.Bytes(), 0600)
+}
+
+// changedSince returns the keys whose values in c differ from before
+func (c *Config) changedSince(before *Config) map[string]bool {
+	changed := map[string]bool{}
+	for _, k := range keys {
+		if k.global {
+			continue
+		}
+		if k.get(nil, c) != k.get(nil, before) {
+			changed[k.Name] = true
+		}
+	}
+	if *storedUser(c) != *storedUser(before) {
+		changed["user"] = true
+	}
+	return changed
+}
+
+// applyOverride sets key in c from an env var or flag
+func applyOverride(c *Config, key, value string) {
+	if k, err := LookupKey(key); err == nil && k.set != nil {
+		k.set(nil, c, value)
+	}
+}
+
+func copyUser(u *User) *User {
+	if u == nil {
+		return nil
+	}
+	copied := *u
+	return &copied
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so readers never see a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestAddProfileKeepsSecretsOutOfTheFile(t *testing.T) {
+	keyring.MockInit()
+	dir := t.TempDir()
+	s := NewStore(dir).WithEnv(func(string) string { return "" })
+
+	err := s.AddProfile("ci", &Config{
+		APIURL:       "https://api.example.com",
+		Token:        "secret-token",
+		RefreshToken: "secret-refresh",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret-") {
+		t.Errorf("config file holds a secret:\n%s", data)
+	}
+
+	s.SetProfile("ci")
+	c, err := s.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token != "secret-token" || c.RefreshToken != "secret-refresh" {
+		t.Errorf("loaded token %q and refresh token %q from the credential store", c.Token, c.RefreshToken)
+	}
+
+	if err := s.AddProfile("ci", &Config{}); err == nil {
+		t.Error("adding a profile twice succeeded")
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// TokenExpiry returns when the token expires, from TokenExpiresAt or else the
+// token's JWT exp claim. It is zero when the expiry is unknown.
+func (c *Config) TokenExpiry() time.Time {
+	if c.TokenExpiresAt != "" {
+		if t, err := time.Parse(time.RFC3339, c.TokenExpiresAt); err == nil {
+			return t
+		}
+	}
+	return JWTExpiry(c.Token)
+}
+
+// TokenExpired reports whether the token is known to have expired
+func (c *Config) TokenExpired() bool {
+	expiry := c.TokenExpiry()
+	return c.Token != "" && !expiry.IsZero() && !time.Now().Before(expiry)
+}
+
+// SessionExpired reports whether the token has expired and can't be
+// refreshed, so the user has to sign in again
+func (c *Config) SessionExpired() bool {
+	return c.TokenExpired() && c.RefreshToken == ""
+}
+
+// JWTExpiry reads the exp claim of a JWT without verifying it. It returns
+// the zero time for tokens that aren't JWTs or carry no expiry.
+func JWTExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil || exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(exp), 0)
+}
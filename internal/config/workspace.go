@@ -0,0 +1,212 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// WorkspaceConfig maps subdirectories of a monorepo to Hopsule projects
+type WorkspaceConfig struct {
+	Projects []WorkspaceProject `yaml:"projects"`
+}
+
+// WorkspaceProject maps a directory, relative to the .hopsule file, to a project
+type WorkspaceProject struct {
+	Path    string      `yaml:"path"`
+	Project ProjectInfo `yaml:"project"`
+}
+
+// ResolvedProject is the project that applies to a directory, together with
+// the .hopsule file it came from
+type ResolvedProject struct {
+	Project    ProjectInfo
+	Config     *ProjectConfig
+	ConfigPath string
+	// Dir is the directory the project is mapped to: the .hopsule directory
+	// itself, or the workspace subdirectory for workspace entries
+	Dir string
+}
+
+// WorkspaceMember is one project discovered in a workspace
+type WorkspaceMember struct {
+	Dir        string
+	Project    ProjectInfo
+	ConfigPath string
+}
+
+// Workspace is the set of projects below a workspace root
+type Workspace struct {
+	Root    string
+	Members []WorkspaceMember
+}
+
+// skipWorkspaceDirs are never descended into when discovering nested .hopsule files
+var skipWorkspaceDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+	"target":       true,
+}
+
+// ResolveProject finds the project for dir. The nearest .hopsule file wins,
+// so nested files override their parents; within a file, the most specific
+// workspace mapping containing dir takes precedence over the file's own project.
+func ResolveProject(dir string) (*ResolvedProject, error) {
+	for current := dir; ; {
+		if configPath, ok := ProjectConfigFile(current); ok {
+			projectCfg, err := readProjectConfig(configPath)
+			if err != nil {
+				return nil, err
+			}
+
+			if member := projectCfg.workspaceProjectFor(current, dir); member != nil {
+				return &ResolvedProject{
+					Project:    member.Project,
+					Config:     projectCfg,
+					ConfigPath: configPath,
+					Dir:        filepath.Join(current, filepath.FromSlash(member.Path)),
+				}, nil
+			}
+
+			if projectCfg.Project.ID != "" {
+				return &ResolvedProject{
+					Project:    projectCfg.Project,
+					Config:     projectCfg,
+					ConfigPath: configPath,
+					Dir:        current,
+				}, nil
+			}
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+
+	return nil, ErrProjectConfigNotFound
+}
+
+// ResolveCurrentProject finds the project for the current working directory
+func ResolveCurrentProject() (*ResolvedProject, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	return ResolveProject(dir)
+}
+
+// LoadWorkspace finds the outermost .hopsule file above dir and collects every
+// project it maps, plus any nested .hopsule files below it
+func LoadWorkspace(dir string) (*Workspace, error) {
+	root := ""
+	for current := dir; ; {
+		if _, ok := ProjectConfigFile(current); ok {
+			root = current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+	if root == "" {
+		return nil, ErrProjectConfigNotFound
+	}
+
+	ws := &Workspace{Root: root}
+	seen := make(map[string]bool)
+	add := func(member WorkspaceMember) {
+		if member.Project.ID == "" || seen[member.Project.ID] {
+			return
+		}
+		seen[member.Project.ID] = true
+		ws.Members = append(ws.Members, member)
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		configPath := path
+		if d.IsDir() {
+			name := d.Name()
+			if name != HopsuleFileName {
+				if path != root && (strings.HasPrefix(name, ".") || skipWorkspaceDirs[name]) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			// A .hopsule directory holds the config of its parent
+			var ok bool
+			if configPath, ok = ProjectConfigFile(filepath.Dir(path)); !ok {
+				return filepath.SkipDir
+			}
+		} else if d.Name() != HopsuleFileName {
+			return nil
+		}
+
+		projectCfg, err := readProjectConfig(configPath)
+		if err != nil {
+			return err
+		}
+		cfgDir := filepath.Dir(path)
+		add(WorkspaceMember{Dir: cfgDir, Project: projectCfg.Project, ConfigPath: configPath})
+		if projectCfg.Workspace != nil {
+			for _, wp := range projectCfg.Workspace.Projects {
+				add(WorkspaceMember{
+					Dir:        filepath.Join(cfgDir, filepath.FromSlash(wp.Path)),
+					Project:    wp.Project,
+					ConfigPath: configPath,
+				})
+			}
+		}
+		if d.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ws.Members, func(i, j int) bool {
+		return ws.Members[i].Dir < ws.Members[j].Dir
+	})
+
+	return ws, nil
+}
+
+// workspaceProjectFor returns the most specific workspace entry containing dir
+func (p *ProjectConfig) workspaceProjectFor(root, dir string) *WorkspaceProject {
+	if p.Workspace == nil {
+		return nil
+	}
+
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || outsideRoot(rel) {
+		return nil
+	}
+	rel = filepath.ToSlash(rel)
+
+	var best *WorkspaceProject
+	for i := range p.Workspace.Projects {
+		wp := &p.Workspace.Projects[i]
+		path := strings.Trim(filepath.ToSlash(wp.Path), "/")
+		if path == "" || path == "." {
+			continue
+		}
+		if rel == path || strings.HasPrefix(rel, path+"/") {
+			if best == nil || len(path) > len(strings.Trim(best.Path, "/")) {
+				best = wp
+			}
+		}
+	}
+	return best
+}
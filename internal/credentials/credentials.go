@@ -0,0 +1,112 @@
+// Package credentials stores authentication tokens outside the plaintext
+// config file: in the OS keyring, an encrypted file, or an external
+// git-style credential helper.
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is returned when no secret is stored for a key
+var ErrNotFound = errors.New("credential not found")
+
+// Store holds secrets by key
+type Store interface {
+	// Name identifies the backend; it is recorded in the config file as the
+	// token reference so the token can be found again on load
+	Name() string
+	Get(key string) (string, error)
+	Set(key, secret string) error
+	Delete(key string) error
+}
+
+// Backend names accepted by Open
+const (
+	BackendAuto      = "auto"
+	BackendKeyring   = "keyring"
+	BackendFile      = "file"
+	BackendHelper    = "helper"
+	BackendPlaintext = "plaintext"
+)
+
+// Options configures Open
+type Options struct {
+	// Backend is one of the Backend* constants; empty means auto
+	Backend string
+	// Helper is an external credential helper command, e.g. "pass hopsule".
+	// Setting it selects the helper backend.
+	Helper string
+	// Dir is where the encrypted file backend keeps its files
+	Dir string
+}
+
+// Open returns the store selected by opts. It returns a nil Store for the
+// plaintext backend, meaning the token stays in the config file.
+//
+// In auto mode the OS keyring is used when it responds, falling back to the
+// encrypted file otherwise (for example on a headless Linux box without a
+// Secret Service).
+func Open(opts Options) (Store, error) {
+	if opts.Helper != "" {
+		return NewHelperStore(opts.Helper), nil
+	}
+
+	switch opts.Backend {
+	case BackendPlaintext:
+		return nil, nil
+	case BackendKeyring:
+		return NewKeyringStore(), nil
+	case BackendFile:
+		return NewFileStore(opts.Dir), nil
+	case BackendHelper:
+		return nil, fmt.Errorf("credential_store is %q but no credential_helper is configured", BackendHelper)
+	case "", BackendAuto:
+		keyring := NewKeyringStore()
+		if keyringAvailable(keyring) {
+			return keyring, nil
+		}
+		return NewFileStore(opts.Dir), nil
+	default:
+		return nil, fmt.Errorf("unknown credential store %q (use auto, keyring, file or plaintext)", opts.Backend)
+	}
+}
+
+// OpenByName returns the store a token reference points at. It is used on
+// load, where the backend is already known from the config file.
+func OpenByName(name string, opts Options) (Store, error) {
+	switch name {
+	case BackendKeyring:
+		return NewKeyringStore(), nil
+	case BackendFile:
+		return NewFileStore(opts.Dir), nil
+	case BackendHelper:
+		if opts.Helper == "" {
+			return nil, fmt.Errorf("token is stored by a credential helper, but credential_helper is not configured")
+		}
+		return NewHelperStore(opts.Helper), nil
+	default:
+		return nil, fmt.Errorf("unknown credential store %q", name)
+	}
+}
+
+// keyringProbeTimeout bounds the availability check; D-Bus lookups can block
+// indefinitely when no Secret Service is running
+const keyringProbeTimeout = 2 * time.Second
+
+// keyringAvailable probes the keyring with a lookup that is expected to miss
+func keyringAvailable(s Store) bool {
+	result := make(chan error, 1)
+	go func() {
+		_, err := s.Get("__probe__")
+		result <- err
+	}()
+
+	select {
+	case err := <-result:
+		return err == nil || errors.Is(err, ErrNotFound)
+	case <-time.After(keyringProbeTimeout):
+		return false
+	}
+}
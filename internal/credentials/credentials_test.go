@@ -0,0 +1,210 @@
+package credentials
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// testStore runs the behaviour every Store shares
+func testStore(t *testing.T, s Store) {
+	t.Helper()
+	if _, err := s.Get("profile.default"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get of a missing key: got %v, want ErrNotFound", err)
+	}
+	if err := s.Set("profile.default", "token-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("profile.ci", "token-2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Set("profile.default", "token-3"); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{"profile.default": "token-3", "profile.ci": "token-2"} {
+		if got, err := s.Get(key); err != nil || got != want {
+			t.Errorf("Get(%q) = %q, %v; want %q", key, got, err, want)
+		}
+	}
+
+	if err := s.Delete("profile.default"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get("profile.default"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: got %v, want ErrNotFound", err)
+	}
+	if err := s.Delete("profile.default"); err != nil {
+		t.Errorf("deleting a missing key: %v", err)
+	}
+	if got, _ := s.Get("profile.ci"); got != "token-2" {
+		t.Errorf("Delete removed another key")
+	}
+}
+
+func TestKeyringStore(t *testing.T) {
+	keyring.MockInit()
+	testStore(t, NewKeyringStore())
+}
+
+func TestFileStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "hopsule")
+	testStore(t, NewFileStore(dir))
+
+	// A new store reads what the first one wrote
+	if got, err := NewFileStore(dir).Get("profile.ci"); err != nil || got != "token-2" {
+		t.Errorf("reopened store: Get = %q, %v", got, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, credentialsFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "token-2") {
+		t.Error("credentials file holds the token as plain text")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("temporary file %s was left behind", entry.Name())
+		}
+		if runtime.GOOS == "windows" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if perm := info.Mode().Perm(); perm != 0600 {
+			t.Errorf("%s has mode %o, want 600", entry.Name(), perm)
+		}
+	}
+}
+
+func TestFileStoreRejectsAnotherKey(t *testing.T) {
+	dir := t.TempDir()
+	if err := NewFileStore(dir).Set("profile.default", "token"); err != nil {
+		t.Fatal(err)
+	}
+
+	key := make([]byte, 32)
+	if err := os.WriteFile(filepath.Join(dir, credentialsKeyName), key, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(dir).Get("profile.default"); err == nil || !strings.Contains(err.Error(), "decrypt") {
+		t.Errorf("reading with the wrong key: got %v, want a decryption error", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, credentialsKeyName), []byte("short"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileStore(dir).Get("profile.default"); err == nil || !strings.Contains(err.Error(), "invalid length") {
+		t.Errorf("reading with a short key: got %v, want an invalid length error", err)
+	}
+}
+
+// fakeHelper writes a git-style credential helper that keeps each secret in
+// a file named after its host
+func fakeHelper(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake credential helper is a shell script")
+	}
+	dir := t.TempDir()
+	script := `#!/bin/sh
+dir="` + dir + `"
+while IFS='=' read -r name value; do
+	[ -z "$name" ] && break
+	case "$name" in
+	protocol) [ "$value" = hopsule ] || exit 1 ;;
+	host) host=$value ;;
+	password) password=$value ;;
+	esac
+done
+case "$1" in
+get) [ -f "$dir/$host" ] && printf 'username=hopsule\npassword=%s\n' "$(cat "$dir/$host")" ;;
+store) printf '%s' "$password" > "$dir/$host" ;;
+erase) rm -f "$dir/$host" ;;
+esac
+exit 0
+`
+	path := filepath.Join(dir, "helper.sh")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestHelperStore(t *testing.T) {
+	helper := fakeHelper(t)
+	testStore(t, NewHelperStore(helper))
+	// git's "!" prefix for shell commands is accepted
+	if got, err := NewHelperStore("!" + helper).Get("profile.ci"); err != nil || got != "token-2" {
+		t.Errorf("Get through a ! command = %q, %v", got, err)
+	}
+}
+
+func TestHelperStoreReportsFailures(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	s := NewHelperStore("echo vault is sealed >&2; exit 1; :")
+	err := s.Set("profile.default", "token")
+	if err == nil || !strings.Contains(err.Error(), "vault is sealed") {
+		t.Errorf("got %v, want the helper's stderr in the error", err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	keyring.MockInit()
+	dir := t.TempDir()
+
+	tests := []struct {
+		opts    Options
+		want    string // store name; empty for no store
+		wantErr bool
+	}{
+		{opts: Options{Dir: dir}, want: BackendKeyring},
+		{opts: Options{Backend: BackendAuto, Dir: dir}, want: BackendKeyring},
+		{opts: Options{Backend: BackendKeyring}, want: BackendKeyring},
+		{opts: Options{Backend: BackendFile, Dir: dir}, want: BackendFile},
+		{opts: Options{Backend: BackendPlaintext}},
+		{opts: Options{Backend: BackendFile, Helper: "pass-hopsule"}, want: BackendHelper},
+		{opts: Options{Backend: BackendHelper}, wantErr: true},
+		{opts: Options{Backend: "vault"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		s, err := Open(tt.opts)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Open(%+v) succeeded", tt.opts)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Open(%+v): %v", tt.opts, err)
+			continue
+		}
+		got := ""
+		if s != nil {
+			got = s.Name()
+		}
+		if got != tt.want {
+			t.Errorf("Open(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+	}
+
+	if _, err := OpenByName(BackendHelper, Options{}); err == nil {
+		t.Error("OpenByName(helper) without a helper succeeded")
+	}
+	if s, err := OpenByName(BackendFile, Options{Dir: dir}); err != nil || s.Name() != BackendFile {
+		t.Errorf("OpenByName(file) = %v, %v", s, err)
+	}
+}
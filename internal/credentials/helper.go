@@ -0,0 +1,89 @@
+package credentials
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// helperStore talks to an external credential helper using the git
+// credential helper protocol: the helper is run with "get", "store" or
+// "erase" appended, receives key=value lines on stdin and, for "get",
+// answers with key=value lines including password=<token> on stdout.
+//
+// The request identifies the secret as protocol=hopsule and host=<key>.
+type helperStore struct {
+	command string
+}
+
+// NewHelperStore returns a store that delegates to the given helper command,
+// e.g. "pass-hopsule" or "/usr/local/bin/hopsule-vault"
+func NewHelperStore(command string) Store {
+	return &helperStore{command: command}
+}
+
+func (s *helperStore) Name() string { return BackendHelper }
+
+func (s *helperStore) Get(key string) (string, error) {
+	out, err := s.run("get", helperRequest(key, ""))
+	if err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), "=")
+		if ok && name == "password" && value != "" {
+			return value, nil
+		}
+	}
+	return "", ErrNotFound
+}
+
+func (s *helperStore) Set(key, secret string) error {
+	_, err := s.run("store", helperRequest(key, secret))
+	return err
+}
+
+func (s *helperStore) Delete(key string) error {
+	_, err := s.run("erase", helperRequest(key, ""))
+	return err
+}
+
+func (s *helperStore) run(action string, input string) ([]byte, error) {
+	command := strings.TrimPrefix(s.command, "!") + " " + action
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = strings.NewReader(input)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg != "" {
+			return nil, fmt.Errorf("credential helper %q %s failed: %w: %s", s.command, action, err, msg)
+		}
+		return nil, fmt.Errorf("credential helper %q %s failed: %w", s.command, action, err)
+	}
+	return out, nil
+}
+
+func helperRequest(key, secret string) string {
+	var sb strings.Builder
+	sb.WriteString("protocol=hopsule\n")
+	fmt.Fprintf(&sb, "host=%s\n", key)
+	if secret != "" {
+		fmt.Fprintf(&sb, "password=%s\n", secret)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,48 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+const keyringService = "hopsule"
+
+// keyringStore uses the OS keyring: Keychain on macOS, Credential Manager on
+// Windows and the Secret Service (GNOME Keyring, KWallet) on Linux
+type keyringStore struct{}
+
+// NewKeyringStore returns a store backed by the OS keyring. Tests replace
+// the OS keyring with keyring.MockInit.
+func NewKeyringStore() Store {
+	return keyringStore{}
+}
+
+func (keyringStore) Name() string { return BackendKeyring }
+
+func (keyringStore) Get(key string) (string, error) {
+	secret, err := keyring.Get(keyringService, key)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("keyring: %w", err)
+	}
+	return secret, nil
+}
+
+func (keyringStore) Set(key, secret string) error {
+	if err := keyring.Set(keyringService, key, secret); err != nil {
+		return fmt.Errorf("keyring: %w", err)
+	}
+	return nil
+}
+
+func (keyringStore) Delete(key string) error {
+	err := keyring.Delete(keyringService, key)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return fmt.Errorf("keyring: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+// Package graphexport writes a knowledge graph in formats other tools can
+// render: Graphviz DOT, Mermaid, JSON and GraphML.
+package graphexport
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+)
+
+// Formats lists the supported formats
+var Formats = []string{"dot", "mermaid", "json", "graphml"}
+
+// maxLabel is the longest node label drawn; longer ones are cut
+const maxLabel = 60
+
+// nodeStyle is how a node type is drawn
+type nodeStyle struct {
+	dotShape string
+	color    string // fill, as #rrggbb
+	// Mermaid shape delimiters around the label
+	open, close string
+}
+
+var nodeStyles = map[string]nodeStyle{
+	api.NodeDecision:  {"box", "#cfe8ff", "[", "]"},
+	api.NodeMemory:    {"note", "#fff3c4", "([", "])"},
+	api.NodeTask:      {"box", "#d7f5d0", "{{", "}}"},
+	api.NodeCapsule:   {"folder", "#e6dcff", "[(", ")]"},
+	api.NodeCodeChunk: {"component", "#eeeeee", "[/", "/]"},
+}
+
+func styleOf(nodeType string) nodeStyle {
+	if style, ok := nodeStyles[nodeType]; ok {
+		return style
+	}
+	return nodeStyle{"ellipse", "#ffffff", "(", ")"}
+}
+
+// Write writes g to w in format. name titles the graph where the format
+// has a place for it.
+func Write(w io.Writer, g *api.Graph, format, name string) error {
+	switch format {
+	case "dot":
+		return writeDOT(w, g, name)
+	case "mermaid":
+		return writeMermaid(w, g)
+	case "json":
+		return writeJSON(w, g)
+	case "graphml":
+		return writeGraphML(w, g, name)
+	}
+	return fmt.Errorf("unknown format %q: must be one of %s", format, strings.Join(Formats, ", "))
+}
+
+// label is the first line of a node's label, cut to maxLabel characters
+func label(n api.GraphNode) string {
+	text, _, _ := strings.Cut(n.Label, "\n")
+	if text == "" {
+		text = n.Path
+	}
+	if runes := []rune(text); len(runes) > maxLabel {
+		text = string(runes[:maxLabel-1]) + "…"
+	}
+	return text
+}
+
+// ============================================================================
+// DOT
+// ============================================================================
+
+func writeDOT(w io.Writer, g *api.Graph, name string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(name))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [style=\"rounded,filled\", fontname=\"Helvetica\", fontsize=10];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=8, color=\"#888888\"];\n")
+	for _, n := range g.Nodes {
+		style := styleOf(n.Type)
+		text := n.ID + "\n" + label(n)
+		if n.Status != "" {
+			text += "\n[" + n.Status + "]"
+		}
+		fmt.Fprintf(&b, "  %s [label=%s, shape=%s, fillcolor=%s];\n",
+			dotQuote(n.ID), dotQuote(text), style.dotShape, dotQuote(style.color))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(e.Source), dotQuote(e.Target), dotQuote(e.Type))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote makes s a DOT quoted string
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// ============================================================================
+// MERMAID
+// ============================================================================
+
+func writeMermaid(w io.Writer, g *api.Graph) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+
+	// Mermaid IDs can't hold every character an item ID can, so nodes are
+	// numbered
+	ids := make(map[string]string, len(g.Nodes))
+	for i, n := range g.Nodes {
+		ids[n.ID] = fmt.Sprintf("n%d", i)
+	}
+	byType := map[string][]string{}
+	var types []string
+	for _, n := range g.Nodes {
+		style := styleOf(n.Type)
+		text := n.ID + "<br/>" + mermaidEscape(label(n))
+		if n.Status != "" {
+			text += "<br/>" + n.Status
+		}
+		fmt.Fprintf(&b, "  %s%s\"%s\"%s\n", ids[n.ID], style.open, text, style.close)
+		if byType[n.Type] == nil {
+			types = append(types, n.Type)
+		}
+		byType[n.Type] = append(byType[n.Type], ids[n.ID])
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", ids[e.Source], e.Type, ids[e.Target])
+	}
+	for _, t := range types {
+		fmt.Fprintf(&b, "  classDef %s fill:%s,stroke:#888888\n", t, styleOf(t).color)
+		fmt.Fprintf(&b, "  class %s %s\n", strings.Join(byType[t], ","), t)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidEscape replaces the characters that end or break a quoted Mermaid
+// label with entity codes
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
+
+// ============================================================================
+// JSON
+// ============================================================================
+
+func writeJSON(w io.Writer, g *api.Graph) error {
+	out := struct {
+		Nodes []api.GraphNode `json:"nodes"`
+		Edges []api.GraphEdge `json:"edges"`
+	}{Nodes: g.Nodes, Edges: g.Edges}
+	if out.Nodes == nil {
+		out.Nodes = []api.GraphNode{}
+	}
+	if out.Edges == nil {
+		out.Edges = []api.GraphEdge{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode graph: %w", err)
+	}
+	return nil
+}
+
+// ============================================================================
+// GRAPHML
+// ============================================================================
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func writeGraphML(w io.Writer, g *api.Graph, name string) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "type", For: "node", AttrName: "type", AttrType: "string"},
+			{ID: "label", For: "node", AttrName: "label", AttrType: "string"},
+			{ID: "status", For: "node", AttrName: "status", AttrType: "string"},
+			{ID: "tags", For: "node", AttrName: "tags", AttrType: "string"},
+			{ID: "path", For: "node", AttrName: "path", AttrType: "string"},
+			{ID: "edge_type", For: "edge", AttrName: "type", AttrType: "string"},
+		},
+		Graph: graphMLGraph{ID: name, EdgeDefault: "directed"},
+	}
+	for _, n := range g.Nodes {
+		node := graphMLNode{ID: n.ID, Data: []graphMLData{
+			{Key: "type", Value: n.Type},
+			{Key: "label", Value: n.Label},
+		}}
+		if n.Status != "" {
+			node.Data = append(node.Data, graphMLData{Key: "status", Value: n.Status})
+		}
+		if len(n.Tags) > 0 {
+			node.Data = append(node.Data, graphMLData{Key: "tags", Value: strings.Join(n.Tags, ",")})
+		}
+		if n.Path != "" {
+			node.Data = append(node.Data, graphMLData{Key: "path", Value: n.Path})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for _, e := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			Source: e.Source,
+			Target: e.Target,
+			Data:   []graphMLData{{Key: "edge_type", Value: e.Type}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode graph: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,112 @@
+package graphexport
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+)
+
+func testGraph() *api.Graph {
+	return api.NewGraph(
+		[]api.Decision{{ID: "d1", Statement: `Use "quotes" & <tags> \ here` + "\nsecond line", Status: "ACCEPTED"}},
+		[]*api.Memory{{ID: "m1", Content: strings.Repeat("x", 100), RelatedDecisionIds: []string{"d1"}}},
+		nil, nil,
+	)
+}
+
+func write(t *testing.T, format string) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := Write(&b, testGraph(), format, `my "project"`); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestDOTEscaping(t *testing.T) {
+	out := write(t, "dot")
+	for _, want := range []string{
+		`digraph "my \"project\"" {`,
+		`"d1" [label="d1\nUse \"quotes\" & <tags> \\ here\n[ACCEPTED]"`,
+		`"m1" -> "d1" [label="memory_decision"];`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("DOT output is missing %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "second line") {
+		t.Error("DOT label has more than the first line")
+	}
+}
+
+func TestMermaidEscaping(t *testing.T) {
+	out := write(t, "mermaid")
+	want := `n0["d1<br/>Use #quot;quotes#quot; & #lt;tags#gt; \ here<br/>ACCEPTED"]`
+	if !strings.Contains(out, want) {
+		t.Errorf("Mermaid output is missing %s:\n%s", want, out)
+	}
+	if !strings.Contains(out, "n1 -->|memory_decision| n0") {
+		t.Errorf("Mermaid output is missing the edge:\n%s", out)
+	}
+	if !strings.Contains(out, strings.Repeat("x", maxLabel-1)+"…") {
+		t.Errorf("long label wasn't cut:\n%s", out)
+	}
+}
+
+func TestJSONIsNotHTMLEscaped(t *testing.T) {
+	out := write(t, "json")
+	if !strings.Contains(out, `& <tags>`) {
+		t.Errorf("JSON output escaped HTML characters:\n%s", out)
+	}
+	var decoded struct {
+		Nodes []api.GraphNode `json:"nodes"`
+		Edges []api.GraphEdge `json:"edges"`
+	}
+	if err := json.Unmarshal([]byte(out), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Nodes[0].Label != testGraph().Nodes[0].Label {
+		t.Errorf("label = %q after a round trip", decoded.Nodes[0].Label)
+	}
+}
+
+func TestJSONOfEmptyGraph(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, api.NewGraph(nil, nil, nil, nil), "json", ""); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(b.String(), `"nodes": []`) || !strings.Contains(b.String(), `"edges": []`) {
+		t.Errorf("empty graph should have empty lists:\n%s", b.String())
+	}
+}
+
+func TestGraphMLRoundTrips(t *testing.T) {
+	out := write(t, "graphml")
+	if !strings.HasPrefix(out, xml.Header) {
+		t.Errorf("GraphML has no XML header:\n%s", out)
+	}
+	var doc graphML
+	if err := xml.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("GraphML isn't valid XML: %v\n%s", err, out)
+	}
+	if doc.Graph.ID != `my "project"` {
+		t.Errorf("graph id = %q", doc.Graph.ID)
+	}
+	if got := doc.Graph.Nodes[0].Data[1].Value; got != testGraph().Nodes[0].Label {
+		t.Errorf("label = %q after a round trip", got)
+	}
+	if len(doc.Graph.Edges) != 1 || doc.Graph.Edges[0].Data[0].Value != api.EdgeMemoryDecision {
+		t.Errorf("edges = %+v", doc.Graph.Edges)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	err := Write(&bytes.Buffer{}, testGraph(), "svg", "")
+	if err == nil || !strings.Contains(err.Error(), "dot, mermaid, json, graphml") {
+		t.Errorf("got %v, want an error listing the formats", err)
+	}
+}
@@ -0,0 +1,112 @@
+package localstore
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+)
+
+// capsuleFile is the contents of capsules/<id>.yaml. Capsules are written
+// by hand; the CLI only reads them.
+type capsuleFile struct {
+	ID          string   `yaml:"id"`
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description,omitempty"`
+	Status      string   `yaml:"status"`
+	Active      bool     `yaml:"active,omitempty"`
+	Decisions   []string `yaml:"decisions,omitempty"`
+	Memories    []string `yaml:"memories,omitempty"`
+	CreatedAt   string   `yaml:"created_at"`
+	UpdatedAt   string   `yaml:"updated_at"`
+	FrozenAt    string   `yaml:"frozen_at,omitempty"`
+}
+
+// ListCapsules returns the project's capsules, oldest first
+func (s *Store) ListCapsules(projectID string) ([]*api.Capsule, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.listFiles(capsulesDir, ".yaml")
+	if err != nil {
+		return nil, err
+	}
+	capsules := make([]*api.Capsule, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		var c capsuleFile
+		if err := yaml.Unmarshal(data, &c); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		capsule := &api.Capsule{
+			ID:          idOr(c.ID, file),
+			Name:        c.Name,
+			Description: c.Description,
+			Status:      c.Status,
+			DecisionIds: c.Decisions,
+			MemoryIds:   c.Memories,
+			CreatedAt:   c.CreatedAt,
+			UpdatedAt:   c.UpdatedAt,
+			IsActive:    c.Active,
+		}
+		if c.FrozenAt != "" {
+			capsule.FrozenAt = &c.FrozenAt
+		}
+		if capsule.Status == "" {
+			capsule.Status = StatusDraft
+		}
+		capsules = append(capsules, capsule)
+	}
+	sort.SliceStable(capsules, func(i, j int) bool {
+		if capsules[i].CreatedAt != capsules[j].CreatedAt {
+			return capsules[i].CreatedAt < capsules[j].CreatedAt
+		}
+		return capsules[i].ID < capsules[j].ID
+	})
+	return capsules, nil
+}
+
+// GetGraphStats counts the items as nodes and the links between them as
+// edges
+func (s *Store) GetGraphStats(projectID string) (*api.GraphStats, error) {
+	g, err := s.graph(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return g.Stats(), nil
+}
+
+// GetGraphNeighbors finds the items linked to an item
+func (s *Store) GetGraphNeighbors(projectID, nodeID string, opts api.NeighborOptions) (*api.GraphNeighbors, error) {
+	g, err := s.graph(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return g.Neighbors(nodeID, opts)
+}
+
+// GetGraphPaths finds the chains of links between two items
+func (s *Store) GetGraphPaths(projectID, fromID, toID string, maxDepth int) ([]api.GraphPath, error) {
+	g, err := s.graph(projectID)
+	if err != nil {
+		return nil, err
+	}
+	return g.Paths(fromID, toID, maxDepth)
+}
+
+// graph builds the project's graph from its items
+func (s *Store) graph(projectID string) (*api.Graph, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	return api.LoadGraph(s, projectID)
+}
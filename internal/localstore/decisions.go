@@ -0,0 +1,234 @@
+package localstore
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+)
+
+// Decision statuses
+const (
+	StatusDraft      = "DRAFT"
+	StatusPending    = "PENDING"
+	StatusAccepted   = "ACCEPTED"
+	StatusRejected   = "REJECTED"
+	StatusDeprecated = "DEPRECATED"
+)
+
+// decisionMeta is the front matter of decisions/<id>.md. The statement is
+// the heading of the body and the rationale follows it.
+type decisionMeta struct {
+	ID         string   `yaml:"id"`
+	Status     string   `yaml:"status"`
+	Scope      string   `yaml:"scope,omitempty"`
+	Supersedes string   `yaml:"supersedes,omitempty"`
+	Tags       []string `yaml:"tags,omitempty,flow"`
+	CreatedAt  string   `yaml:"created_at"`
+	UpdatedAt  string   `yaml:"updated_at"`
+	AcceptedAt string   `yaml:"accepted_at,omitempty"`
+	AcceptedBy string   `yaml:"accepted_by,omitempty"`
+}
+
+// ListDecisions returns the project's decisions, oldest first
+func (s *Store) ListDecisions(projectID string) ([]api.Decision, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.listFiles(decisionsDir, ".md")
+	if err != nil {
+		return nil, err
+	}
+	decisions := make([]api.Decision, 0, len(files))
+	for _, file := range files {
+		d, err := readDecision(file)
+		if err != nil {
+			return nil, err
+		}
+		decisions = append(decisions, *d)
+	}
+	sort.SliceStable(decisions, func(i, j int) bool {
+		if decisions[i].CreatedAt != decisions[j].CreatedAt {
+			return decisions[i].CreatedAt < decisions[j].CreatedAt
+		}
+		return decisions[i].ID < decisions[j].ID
+	})
+	return decisions, nil
+}
+
+// GetDecision returns one decision
+func (s *Store) GetDecision(projectID, decisionID string) (*api.Decision, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadDecision(decisionID)
+}
+
+// CreateDecision adds a DRAFT decision
+func (s *Store) CreateDecision(projectID string, req api.CreateDecisionRequest) (*api.Decision, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Statement) == "" {
+		return nil, fmt.Errorf("statement is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := s.newID(decisionsDir, "dec", ".md")
+	if err != nil {
+		return nil, err
+	}
+	created := now()
+	d := &api.Decision{
+		ID:        id,
+		Statement: strings.Join(strings.Fields(req.Statement), " "),
+		Rationale: strings.TrimSpace(req.Rationale),
+		Status:    StatusDraft,
+		CreatedAt: created,
+		UpdatedAt: created,
+		Tags:      req.Tags,
+	}
+	if req.ScopeKey != nil {
+		d.ScopeKey = *req.ScopeKey
+	}
+	if err := s.saveDecision(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// AcceptDecision accepts a DRAFT or PENDING decision
+func (s *Store) AcceptDecision(projectID, decisionID string) (*api.Decision, error) {
+	return s.transitionDecision(projectID, decisionID, StatusAccepted, StatusDraft, StatusPending)
+}
+
+// DeprecateDecision deprecates an ACCEPTED decision
+func (s *Store) DeprecateDecision(projectID, decisionID string) (*api.Decision, error) {
+	return s.transitionDecision(projectID, decisionID, StatusDeprecated, StatusAccepted)
+}
+
+// transitionDecision moves a decision to status, which is only allowed from
+// one of the from statuses, the same rules the server enforces
+func (s *Store) transitionDecision(projectID, decisionID, status string, from ...string) (*api.Decision, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d, err := s.loadDecision(decisionID)
+	if err != nil {
+		return nil, err
+	}
+	allowed := false
+	for _, f := range from {
+		allowed = allowed || d.Status == f
+	}
+	if !allowed {
+		return nil, fmt.Errorf("decision %s is %s; only %s decisions can be %s", d.ID, d.Status, strings.Join(from, " or "), strings.ToLower(status))
+	}
+
+	d.Status = status
+	d.UpdatedAt = now()
+	if status == StatusAccepted {
+		acceptedAt, acceptedBy := d.UpdatedAt, s.currentUser().ID
+		d.AcceptedAt, d.AcceptedBy = &acceptedAt, &acceptedBy
+	}
+	if err := s.saveDecision(d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetProjectStatus counts the project's decisions by status
+func (s *Store) GetProjectStatus(projectID string) (*api.ProjectStatus, error) {
+	decisions, err := s.ListDecisions(projectID)
+	if err != nil {
+		return nil, err
+	}
+	status := &api.ProjectStatus{ProjectID: projectID, TotalDecisions: len(decisions)}
+	for _, d := range decisions {
+		switch d.Status {
+		case StatusAccepted:
+			status.Accepted++
+		case StatusPending:
+			status.Pending++
+		case StatusDraft:
+			status.Draft++
+		case StatusDeprecated:
+			status.Deprecated++
+		}
+	}
+	return status, nil
+}
+
+// loadDecision reads a decision by ID. The caller holds s.mu.
+func (s *Store) loadDecision(id string) (*api.Decision, error) {
+	file, err := s.itemFile(decisionsDir, id, ".md")
+	if err != nil {
+		return nil, err
+	}
+	d, err := readDecision(file)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("decision %s not found", id)
+	}
+	return d, err
+}
+
+// saveDecision writes a decision. The caller holds s.mu.
+func (s *Store) saveDecision(d *api.Decision) error {
+	meta := decisionMeta{
+		ID:         d.ID,
+		Status:     d.Status,
+		Scope:      d.ScopeKey,
+		Supersedes: d.Supersedes,
+		Tags:       d.Tags,
+		CreatedAt:  d.CreatedAt,
+		UpdatedAt:  d.UpdatedAt,
+	}
+	if d.AcceptedAt != nil {
+		meta.AcceptedAt = *d.AcceptedAt
+	}
+	if d.AcceptedBy != nil {
+		meta.AcceptedBy = *d.AcceptedBy
+	}
+	return writeDocument(s.path(decisionsDir, d.ID+".md"), meta, joinTitle(d.Statement, d.Rationale))
+}
+
+func readDecision(file string) (*api.Decision, error) {
+	var meta decisionMeta
+	body, err := readDocument(file, &meta)
+	if err != nil {
+		return nil, err
+	}
+	statement, rationale := splitTitle(body)
+	d := &api.Decision{
+		ID:         idOr(meta.ID, file),
+		Statement:  statement,
+		Rationale:  rationale,
+		Status:     meta.Status,
+		CreatedAt:  meta.CreatedAt,
+		UpdatedAt:  meta.UpdatedAt,
+		Tags:       meta.Tags,
+		ScopeKey:   meta.Scope,
+		Supersedes: meta.Supersedes,
+	}
+	if meta.AcceptedAt != "" {
+		d.AcceptedAt = &meta.AcceptedAt
+	}
+	if meta.AcceptedBy != "" {
+		d.AcceptedBy = &meta.AcceptedBy
+	}
+	if d.Status == "" {
+		d.Status = StatusDraft
+	}
+	return d, nil
+}
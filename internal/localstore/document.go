@@ -0,0 +1,103 @@
+package localstore
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const frontMatterDelim = "---"
+
+// readDocument reads a Markdown file with YAML front matter into meta and
+// returns the body after it
+func readDocument(path string, meta any) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	rest, ok := strings.CutPrefix(text, frontMatterDelim+"\n")
+	if !ok {
+		return "", fmt.Errorf("%s: missing front matter", path)
+	}
+	header, body, ok := strings.Cut(rest, "\n"+frontMatterDelim+"\n")
+	if !ok {
+		header, ok = strings.CutSuffix(rest, "\n"+frontMatterDelim)
+		if !ok {
+			return "", fmt.Errorf("%s: unterminated front matter", path)
+		}
+		body = ""
+	}
+	if err := yaml.Unmarshal([]byte(header), meta); err != nil {
+		return "", fmt.Errorf("%s: invalid front matter: %w", path, err)
+	}
+	return strings.TrimSpace(body), nil
+}
+
+// writeDocument writes meta as YAML front matter followed by body
+func writeDocument(path string, meta any, body string) error {
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelim + "\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(meta); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	enc.Close()
+	buf.WriteString(frontMatterDelim + "\n\n")
+	if body = strings.TrimSpace(body); body != "" {
+		buf.WriteString(body + "\n")
+	}
+	return writeFile(path, buf.Bytes())
+}
+
+// splitTitle splits a body into its "# title" line and the text after it
+func splitTitle(body string) (title, text string) {
+	first, rest, _ := strings.Cut(body, "\n")
+	if heading, ok := strings.CutPrefix(first, "# "); ok {
+		return strings.TrimSpace(heading), strings.TrimSpace(rest)
+	}
+	return "", body
+}
+
+// joinTitle is the inverse of splitTitle. Titles are kept on one line.
+func joinTitle(title, text string) string {
+	title = strings.Join(strings.Fields(title), " ")
+	if text = strings.TrimSpace(text); text == "" {
+		return "# " + title
+	}
+	return "# " + title + "\n\n" + text
+}
+
+// writeFile replaces path through a temporary file, so an interrupted write
+// never leaves half an item behind
+func writeFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package localstore
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+)
+
+// memoryMeta is the front matter of memories/<id>.md; the content is the
+// body
+type memoryMeta struct {
+	ID               string   `yaml:"id"`
+	Tags             []string `yaml:"tags,omitempty,flow"`
+	RelatedDecisions []string `yaml:"related_decisions,omitempty,flow"`
+	CreatedBy        string   `yaml:"created_by,omitempty"`
+	CreatedByName    string   `yaml:"created_by_name,omitempty"`
+	CreatedAt        string   `yaml:"created_at"`
+	UpdatedAt        string   `yaml:"updated_at,omitempty"`
+}
+
+// ListMemories returns the project's memories, oldest first
+func (s *Store) ListMemories(projectID string) ([]*api.Memory, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.listFiles(memoriesDir, ".md")
+	if err != nil {
+		return nil, err
+	}
+	memories := make([]*api.Memory, 0, len(files))
+	for _, file := range files {
+		m, err := readMemory(file)
+		if err != nil {
+			return nil, err
+		}
+		memories = append(memories, m)
+	}
+	sort.SliceStable(memories, func(i, j int) bool {
+		if memories[i].CreatedAt != memories[j].CreatedAt {
+			return memories[i].CreatedAt < memories[j].CreatedAt
+		}
+		return memories[i].ID < memories[j].ID
+	})
+	return memories, nil
+}
+
+// CreateMemory adds a memory written by the current user
+func (s *Store) CreateMemory(projectID string, req api.CreateMemoryRequest) (*api.Memory, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, fmt.Errorf("content is required")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkDecisions(req.RelatedDecisionIds); err != nil {
+		return nil, err
+	}
+	id, err := s.newID(memoriesDir, "mem", ".md")
+	if err != nil {
+		return nil, err
+	}
+	u := s.currentUser()
+	created := now()
+	m := &api.Memory{
+		ID:                 id,
+		Content:            strings.TrimSpace(req.Content),
+		CreatedAt:          created,
+		UpdatedAt:          created,
+		Tags:               req.Tags,
+		RelatedDecisionIds: req.RelatedDecisionIds,
+		CreatedByUserID:    u.ID,
+		CreatedByName:      u.Name,
+	}
+	if err := s.saveMemory(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// UpdateMemory changes the non-empty fields of req
+func (s *Store) UpdateMemory(projectID, memoryID string, req api.UpdateMemoryRequest) (*api.Memory, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, err := s.loadMemory(memoryID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Content != "" {
+		m.Content = strings.TrimSpace(req.Content)
+	}
+	if req.Tags != nil {
+		m.Tags = req.Tags
+	}
+	if req.RelatedDecisionIds != nil {
+		if err := s.checkDecisions(req.RelatedDecisionIds); err != nil {
+			return nil, err
+		}
+		m.RelatedDecisionIds = req.RelatedDecisionIds
+	}
+	m.UpdatedAt = now()
+	if err := s.saveMemory(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// DeleteMemory removes a memory
+func (s *Store) DeleteMemory(projectID, memoryID string) error {
+	if err := s.checkProject(projectID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.itemFile(memoriesDir, memoryID, ".md")
+	if err != nil {
+		return err
+	}
+	return removeItem(file, "memory", memoryID)
+}
+
+// checkDecisions fails if any of ids is not a decision in the store. The
+// caller holds s.mu.
+func (s *Store) checkDecisions(ids []string) error {
+	for _, id := range ids {
+		if _, err := s.loadDecision(id); err != nil {
+			return fmt.Errorf("related decision: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadMemory reads a memory by ID. The caller holds s.mu.
+func (s *Store) loadMemory(id string) (*api.Memory, error) {
+	file, err := s.itemFile(memoriesDir, id, ".md")
+	if err != nil {
+		return nil, err
+	}
+	m, err := readMemory(file)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("memory %s not found", id)
+	}
+	return m, err
+}
+
+// saveMemory writes a memory. The caller holds s.mu.
+func (s *Store) saveMemory(m *api.Memory) error {
+	meta := memoryMeta{
+		ID:               m.ID,
+		Tags:             m.Tags,
+		RelatedDecisions: m.RelatedDecisionIds,
+		CreatedBy:        m.CreatedByUserID,
+		CreatedByName:    m.CreatedByName,
+		CreatedAt:        m.CreatedAt,
+		UpdatedAt:        m.UpdatedAt,
+	}
+	return writeDocument(s.path(memoriesDir, m.ID+".md"), meta, m.Content)
+}
+
+func readMemory(file string) (*api.Memory, error) {
+	var meta memoryMeta
+	body, err := readDocument(file, &meta)
+	if err != nil {
+		return nil, err
+	}
+	return &api.Memory{
+		ID:                 idOr(meta.ID, file),
+		Content:            body,
+		CreatedAt:          meta.CreatedAt,
+		UpdatedAt:          meta.UpdatedAt,
+		Tags:               meta.Tags,
+		RelatedDecisionIds: meta.RelatedDecisions,
+		CreatedByUserID:    meta.CreatedBy,
+		CreatedByName:      meta.CreatedByName,
+	}, nil
+}
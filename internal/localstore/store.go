@@ -0,0 +1,374 @@
+// Package localstore is the local backend: a project's decisions, memories,
+// tasks and capsules kept as files in its .hopsule directory, so they can
+// be reviewed and merged like code.
+//
+//	.hopsule/
+//	  config.yaml            project config (backend: local)
+//	  decisions/<id>.md      front matter + "# statement" + rationale
+//	  memories/<id>.md       front matter + content
+//	  tasks/<id>.md          front matter + "# title" + description
+//	  capsules/<id>.yaml
+//	  push.yaml              IDs of items already pushed to a server
+package localstore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+)
+
+// Directories inside .hopsule holding each kind of item
+const (
+	decisionsDir = "decisions"
+	memoriesDir  = "memories"
+	tasksDir     = "tasks"
+	capsulesDir  = "capsules"
+)
+
+// ErrChatUnavailable is returned by SendChatMessage; Hopper runs on the
+// server
+var ErrChatUnavailable = errors.New("Hopper chat needs the http backend; it isn't available for local projects")
+
+func init() {
+	api.RegisterBackend(config.BackendLocal, func(cfg *config.Config, dir string) (api.Service, error) {
+		store, err := Open(dir)
+		if err != nil {
+			return nil, err
+		}
+		store.user = cfg.User
+		return store, nil
+	})
+}
+
+// Store reads and writes the files of one local project
+type Store struct {
+	// Dir is the .hopsule directory
+	Dir     string
+	Project config.ProjectInfo
+
+	mu   sync.Mutex
+	user *config.User
+}
+
+var _ api.Service = (*Store)(nil)
+
+// Open finds the nearest .hopsule directory at or above dir
+func Open(dir string) (*Store, error) {
+	for current := dir; ; {
+		hopsuleDir := filepath.Join(current, config.HopsuleFileName)
+		if info, err := os.Stat(hopsuleDir); err == nil && info.IsDir() {
+			projectCfg, _, err := config.LoadProjectConfigFrom(current)
+			if err != nil {
+				return nil, err
+			}
+			return &Store{Dir: hopsuleDir, Project: projectCfg.Project}, nil
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			break
+		}
+		current = parent
+	}
+	return nil, fmt.Errorf("no %s directory found in current directory or any parent (run 'hopsule init --local')", config.HopsuleFileName)
+}
+
+// Init sets up dir for the local backend. An existing .hopsule file is
+// moved into the new .hopsule directory, keeping its project and scopes;
+// otherwise projectCfg is written.
+func Init(dir string, projectCfg *config.ProjectConfig) (*Store, error) {
+	hopsulePath := filepath.Join(dir, config.HopsuleFileName)
+	if info, err := os.Stat(hopsulePath); err == nil && !info.IsDir() {
+		existing, _, err := config.LoadProjectConfigFrom(dir)
+		if err != nil {
+			return nil, err
+		}
+		projectCfg = existing
+		if err := os.Remove(hopsulePath); err != nil {
+			return nil, fmt.Errorf("failed to replace %s: %w", hopsulePath, err)
+		}
+	}
+
+	for _, sub := range []string{decisionsDir, memoriesDir, tasksDir, capsulesDir} {
+		if err := os.MkdirAll(filepath.Join(hopsulePath, sub), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create %s: %w", hopsulePath, err)
+		}
+	}
+
+	projectCfg.Version = config.HopsuleFileVersion
+	projectCfg.Backend = config.BackendLocal
+	if err := config.SaveProjectConfig(dir, projectCfg); err != nil {
+		return nil, fmt.Errorf("failed to save project config: %w", err)
+	}
+	return &Store{Dir: hopsulePath, Project: projectCfg.Project}, nil
+}
+
+// checkProject fails for projects other than the store's own
+func (s *Store) checkProject(projectID string) error {
+	if projectID != s.Project.ID {
+		return fmt.Errorf("project %s is not stored in %s (it holds %s)", projectID, s.Dir, s.Project.ID)
+	}
+	return nil
+}
+
+// ============================================================================
+// IDENTITY
+// ============================================================================
+
+// GetMe returns the signed-in user, or the git user, with the local
+// project as the only project
+func (s *Store) GetMe() (*api.MeResponse, error) {
+	orgs, _ := s.ListOrganizations()
+	projects, _ := s.ListProjects()
+	u := s.currentUser()
+	return &api.MeResponse{
+		User:          &api.User{ID: u.ID, Name: u.Name, Email: u.Email, AvatarURL: u.AvatarURL},
+		Organizations: orgs,
+		Projects:      projects,
+		TokenType:     api.TokenTypeUser,
+	}, nil
+}
+
+// ListOrganizations returns the organization from the project config, or a
+// placeholder for projects that were never on a server
+func (s *Store) ListOrganizations() ([]*api.Organization, error) {
+	return []*api.Organization{s.organization()}, nil
+}
+
+// ListProjects returns the local project
+func (s *Store) ListProjects() ([]*api.Project, error) {
+	return []*api.Project{{
+		ID:             s.Project.ID,
+		Name:           s.Project.Name,
+		Slug:           s.Project.Slug,
+		Description:    "Stored in " + s.Dir,
+		OrganizationID: s.organization().ID,
+	}}, nil
+}
+
+func (s *Store) organization() *api.Organization {
+	org := s.Project.Organization
+	if org.ID == "" {
+		return &api.Organization{ID: "local", Name: "Local", Slug: "local"}
+	}
+	return &api.Organization{ID: org.ID, Name: org.Name, Slug: org.Slug}
+}
+
+// currentUser is the signed-in user if there is one, else the git author
+func (s *Store) currentUser() config.User {
+	if s.user != nil && (s.user.ID != "" || s.user.Email != "") {
+		return *s.user
+	}
+	u := config.User{Name: gitConfig("user.name"), Email: gitConfig("user.email")}
+	if u.Name == "" {
+		if current, err := user.Current(); err == nil {
+			u.Name = current.Username
+		}
+	}
+	u.ID = u.Email
+	if u.ID == "" {
+		u.ID = u.Name
+	}
+	return u
+}
+
+func gitConfig(key string) string {
+	out, err := exec.Command("git", "config", "--get", key).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// SendChatMessage always fails: Hopper runs on the server
+func (s *Store) SendChatMessage(projectID string, req *api.ChatRequest, onChunk func(string)) error {
+	return ErrChatUnavailable
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+func (s *Store) path(dir, name string) string {
+	return filepath.Join(s.Dir, dir, name)
+}
+
+// newID returns an unused random ID such as dec-1f2e3d4c. Random IDs keep
+// items created on different branches from colliding when merged.
+func (s *Store) newID(dir, prefix, ext string) (string, error) {
+	for {
+		b := make([]byte, 4)
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("failed to generate ID: %w", err)
+		}
+		id := prefix + "-" + hex.EncodeToString(b)
+		if _, err := os.Stat(s.path(dir, id+ext)); os.IsNotExist(err) {
+			return id, nil
+		}
+	}
+}
+
+// NewProjectID returns a random project ID such as local-app-1f2e3d4c.
+// Projects are told apart by ID, e.g. in the search cache, so two
+// directories with the same name must not share one.
+func NewProjectID(slug string) (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate project ID: %w", err)
+	}
+	return "local-" + slug + "-" + hex.EncodeToString(b), nil
+}
+
+// itemFile returns the file of an item, rejecting IDs that aren't plain
+// file names
+func (s *Store) itemFile(dir, id, ext string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid ID %q", id)
+	}
+	return s.path(dir, id+ext), nil
+}
+
+// listFiles returns the files with ext in dir, sorted by name
+func (s *Store) listFiles(dir, ext string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(s.Dir, dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Join(s.Dir, dir), err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ext {
+			files = append(files, filepath.Join(s.Dir, dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// idOr returns id, or the file name for files written by hand without one
+func idOr(id, file string) string {
+	if id != "" {
+		return id
+	}
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
+
+func removeItem(path, kind, id string) error {
+	if err := os.Remove(path); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("%s %s not found", kind, id)
+		}
+		return fmt.Errorf("failed to delete %s %s: %w", kind, id, err)
+	}
+	return nil
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// ============================================================================
+// PUSH STATE
+// ============================================================================
+
+// pushFile records which items were pushed to which server project, so
+// pushing again only sends new items
+const pushFile = "push.yaml"
+
+type pushState struct {
+	// Projects maps a server project ID to local item IDs and the IDs the
+	// server gave them
+	Projects map[string]map[string]string `yaml:"projects"`
+	// Pending lists, per server project, the local items that were created
+	// but whose status is not set on the server yet
+	Pending map[string][]string `yaml:"pending,omitempty"`
+}
+
+// Pushed returns the local-to-server ID mapping for a server project, and
+// the local items whose status still has to be set
+func (s *Store) Pushed(projectID string) (map[string]string, map[string]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.loadPushState()
+	if err != nil {
+		return nil, nil, err
+	}
+	ids := map[string]string{}
+	for local, remote := range state.Projects[projectID] {
+		ids[local] = remote
+	}
+	pending := map[string]bool{}
+	for _, local := range state.Pending[projectID] {
+		pending[local] = true
+	}
+	return ids, pending, nil
+}
+
+// SetPushed records that a local item now exists on a server project.
+// pending marks that its status still has to be set there.
+func (s *Store) SetPushed(projectID, localID, remoteID string, pending bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, err := s.loadPushState()
+	if err != nil {
+		return err
+	}
+	if state.Projects == nil {
+		state.Projects = map[string]map[string]string{}
+	}
+	if state.Projects[projectID] == nil {
+		state.Projects[projectID] = map[string]string{}
+	}
+	state.Projects[projectID][localID] = remoteID
+
+	ids := slices.DeleteFunc(state.Pending[projectID], func(id string) bool { return id == localID })
+	if pending {
+		ids = append(ids, localID)
+	}
+	if state.Pending == nil {
+		state.Pending = map[string][]string{}
+	}
+	state.Pending[projectID] = ids
+	if len(ids) == 0 {
+		delete(state.Pending, projectID)
+	}
+
+	data, err := yaml.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", pushFile, err)
+	}
+	return writeFile(filepath.Join(s.Dir, pushFile), data)
+}
+
+// loadPushState reads push.yaml. The caller holds s.mu.
+func (s *Store) loadPushState() (*pushState, error) {
+	var state pushState
+	data, err := os.ReadFile(filepath.Join(s.Dir, pushFile))
+	if os.IsNotExist(err) {
+		return &state, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", pushFile, err)
+	}
+	if err := yaml.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", pushFile, err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,244 @@
+package localstore
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/config"
+)
+
+const testProject = "local-app-00000000"
+
+func newStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Init(t.TempDir(), &config.ProjectConfig{
+		Project: config.ProjectInfo{ID: testProject, Slug: "app"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestInitAndOpen(t *testing.T) {
+	store := newStore(t)
+	root := filepath.Dir(store.Dir)
+	nested := filepath.Join(root, "services", "api")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	opened, err := Open(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opened.Dir != store.Dir || opened.Project.ID != testProject {
+		t.Errorf("opened %s (%s), want %s (%s)", opened.Dir, opened.Project.ID, store.Dir, testProject)
+	}
+	if _, err := opened.ListDecisions("other"); err == nil {
+		t.Error("listing another project's decisions succeeded")
+	}
+}
+
+func TestDecisionLifecycle(t *testing.T) {
+	store := newStore(t)
+
+	d, err := store.CreateDecision(testProject, api.CreateDecisionRequest{
+		Statement: "  Use   Go ",
+		Rationale: "One static binary\n",
+		Tags:      []string{"lang"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Statement != "Use Go" || d.Status != StatusDraft {
+		t.Errorf("created %+v", d)
+	}
+
+	// The file round-trips
+	got, err := store.GetDecision(testProject, d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Statement != "Use Go" || got.Rationale != "One static binary" || len(got.Tags) != 1 {
+		t.Errorf("read back %+v", got)
+	}
+
+	if _, err := store.DeprecateDecision(testProject, d.ID); err == nil {
+		t.Error("deprecating a draft succeeded")
+	}
+	accepted, err := store.AcceptDecision(testProject, d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepted.Status != StatusAccepted || accepted.AcceptedAt == nil {
+		t.Errorf("accepted %+v", accepted)
+	}
+	if _, err := store.AcceptDecision(testProject, d.ID); err == nil {
+		t.Error("accepting twice succeeded")
+	}
+	if _, err := store.DeprecateDecision(testProject, d.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	decisions, err := store.ListDecisions(testProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 1 || decisions[0].Status != StatusDeprecated {
+		t.Errorf("listed %+v", decisions)
+	}
+
+	if _, err := store.CreateDecision(testProject, api.CreateDecisionRequest{Statement: " "}); err == nil {
+		t.Error("creating a decision without a statement succeeded")
+	}
+	if _, err := store.GetDecision(testProject, "../config"); err == nil {
+		t.Error("reading a path as a decision ID succeeded")
+	}
+}
+
+func TestMemoriesAndTasks(t *testing.T) {
+	store := newStore(t)
+	d, err := store.CreateDecision(testProject, api.CreateDecisionRequest{Statement: "Use Go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.CreateMemory(testProject, api.CreateMemoryRequest{Content: "x", RelatedDecisionIds: []string{"dec-missing"}}); err == nil {
+		t.Error("linking a memory to a missing decision succeeded")
+	}
+	m, err := store.CreateMemory(testProject, api.CreateMemoryRequest{Content: "Builds are fast", RelatedDecisionIds: []string{d.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	task, err := store.CreateTask(testProject, api.CreateTaskRequest{Title: "Set up CI", RelatedMemoryIds: []string{m.ID}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != "TODO" || task.Priority != "MEDIUM" {
+		t.Errorf("created %+v", task)
+	}
+	done, err := store.UpdateTask(testProject, task.ID, api.UpdateTaskRequest{Status: "DONE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done.CompletedAt == nil {
+		t.Error("a DONE task has no completed_at")
+	}
+	reopened, err := store.UpdateTask(testProject, task.ID, api.UpdateTaskRequest{Status: "IN_PROGRESS"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.CompletedAt != nil {
+		t.Error("a reopened task kept completed_at")
+	}
+	if _, err := store.UpdateTask(testProject, task.ID, api.UpdateTaskRequest{Status: "LATER"}); err == nil {
+		t.Error("setting an unknown status succeeded")
+	}
+
+	if err := store.DeleteMemory(testProject, m.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.DeleteMemory(testProject, m.ID); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("deleting twice: got %v, want not found", err)
+	}
+}
+
+func TestCapsulesAndGraph(t *testing.T) {
+	store := newStore(t)
+	d, _ := store.CreateDecision(testProject, api.CreateDecisionRequest{Statement: "Use Go"})
+	m, _ := store.CreateMemory(testProject, api.CreateMemoryRequest{Content: "Builds are fast", RelatedDecisionIds: []string{d.ID}})
+
+	capsule := "name: Storage\ndecisions: [" + d.ID + "]\nmemories: [" + m.ID + "]\n"
+	if err := os.WriteFile(filepath.Join(store.Dir, capsulesDir, "storage.yaml"), []byte(capsule), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	capsules, err := store.ListCapsules(testProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(capsules) != 1 || capsules[0].ID != "storage" || capsules[0].Status != StatusDraft || len(capsules[0].DecisionIds) != 1 {
+		t.Fatalf("capsules = %+v", capsules)
+	}
+
+	stats, err := store.GetGraphStats(testProject)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.NodeCount != 3 || stats.EdgeCount != 3 || stats.NodesByType[api.NodeTask] != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	neighbors, err := store.GetGraphNeighbors(testProject, d.ID, api.NeighborOptions{NodeTypes: []string{api.NodeCapsule}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(neighbors.Neighbors) != 1 || neighbors.Neighbors[0].Node.ID != "storage" {
+		t.Errorf("neighbors = %+v", neighbors.Neighbors)
+	}
+	paths, err := store.GetGraphPaths(testProject, m.ID, d.ID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 2 {
+		t.Errorf("found %d paths, want the direct one and the one through the capsule", len(paths))
+	}
+}
+
+func TestPushState(t *testing.T) {
+	store := newStore(t)
+
+	ids, pending, err := store.Pushed("proj-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 0 || len(pending) != 0 {
+		t.Fatalf("new store has push state %v %v", ids, pending)
+	}
+
+	if err := store.SetPushed("proj-1", "dec-1", "remote-1", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetPushed("proj-1", "dec-2", "remote-2", false); err != nil {
+		t.Fatal(err)
+	}
+	ids, pending, _ = store.Pushed("proj-1")
+	if ids["dec-1"] != "remote-1" || ids["dec-2"] != "remote-2" || !pending["dec-1"] || pending["dec-2"] {
+		t.Errorf("push state = %v %v", ids, pending)
+	}
+
+	// Finishing the item clears its pending mark
+	if err := store.SetPushed("proj-1", "dec-1", "remote-1", false); err != nil {
+		t.Fatal(err)
+	}
+	if _, pending, _ = store.Pushed("proj-1"); len(pending) != 0 {
+		t.Errorf("pending = %v after finishing", pending)
+	}
+
+	// Other server projects are tracked separately
+	if ids, _, _ := store.Pushed("proj-2"); len(ids) != 0 {
+		t.Errorf("proj-2 has push state %v", ids)
+	}
+}
+
+func TestNewProjectIDIsUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for range 100 {
+		id, err := NewProjectID("app")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(id, "local-app-") || len(id) != len("local-app-")+8 {
+			t.Errorf("id = %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("%s was generated twice", id)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,244 @@
+package localstore
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+)
+
+// Task statuses and priorities
+var (
+	TaskStatuses   = []string{"TODO", "IN_PROGRESS", "REVIEW", "DONE"}
+	TaskPriorities = []string{"LOW", "MEDIUM", "HIGH"}
+)
+
+// taskMeta is the front matter of tasks/<id>.md. The title is the heading
+// of the body and the description follows it.
+type taskMeta struct {
+	ID               string   `yaml:"id"`
+	Status           string   `yaml:"status"`
+	Priority         string   `yaml:"priority"`
+	Owner            string   `yaml:"owner,omitempty"`
+	OwnerName        string   `yaml:"owner_name,omitempty"`
+	RelatedDecisions []string `yaml:"related_decisions,omitempty,flow"`
+	RelatedMemories  []string `yaml:"related_memories,omitempty,flow"`
+	CreatedAt        string   `yaml:"created_at"`
+	UpdatedAt        string   `yaml:"updated_at"`
+	CompletedAt      string   `yaml:"completed_at,omitempty"`
+}
+
+// ListTasks returns the project's tasks, oldest first
+func (s *Store) ListTasks(projectID string) ([]*api.Task, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files, err := s.listFiles(tasksDir, ".md")
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]*api.Task, 0, len(files))
+	for _, file := range files {
+		t, err := readTask(file)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if tasks[i].CreatedAt != tasks[j].CreatedAt {
+			return tasks[i].CreatedAt < tasks[j].CreatedAt
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks, nil
+}
+
+// CreateTask adds a TODO task owned by the current user
+func (s *Store) CreateTask(projectID string, req api.CreateTaskRequest) (*api.Task, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		return nil, fmt.Errorf("title is required")
+	}
+	priority := req.Priority
+	if priority == "" {
+		priority = "MEDIUM"
+	}
+	if !oneOf(priority, TaskPriorities) {
+		return nil, fmt.Errorf("invalid priority %q (must be one of %s)", priority, strings.Join(TaskPriorities, ", "))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkDecisions(req.RelatedDecisionIds); err != nil {
+		return nil, err
+	}
+	for _, id := range req.RelatedMemoryIds {
+		if _, err := s.loadMemory(id); err != nil {
+			return nil, fmt.Errorf("related memory: %w", err)
+		}
+	}
+	id, err := s.newID(tasksDir, "task", ".md")
+	if err != nil {
+		return nil, err
+	}
+	u := s.currentUser()
+	created := now()
+	t := &api.Task{
+		ID:                 id,
+		Title:              req.Title,
+		Description:        req.Description,
+		Status:             "TODO",
+		Priority:           priority,
+		CreatedAt:          created,
+		UpdatedAt:          created,
+		OwnerID:            u.ID,
+		OwnerName:          u.Name,
+		RelatedDecisionIds: req.RelatedDecisionIds,
+		RelatedMemoryIds:   req.RelatedMemoryIds,
+	}
+	if err := s.saveTask(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UpdateTask changes the non-empty fields of req. Moving a task to DONE
+// sets completed_at and moving it out clears it.
+func (s *Store) UpdateTask(projectID, taskID string, req api.UpdateTaskRequest) (*api.Task, error) {
+	if err := s.checkProject(projectID); err != nil {
+		return nil, err
+	}
+	if req.Status != "" && !oneOf(req.Status, TaskStatuses) {
+		return nil, fmt.Errorf("invalid status %q (must be one of %s)", req.Status, strings.Join(TaskStatuses, ", "))
+	}
+	if req.Priority != "" && !oneOf(req.Priority, TaskPriorities) {
+		return nil, fmt.Errorf("invalid priority %q (must be one of %s)", req.Priority, strings.Join(TaskPriorities, ", "))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, err := s.loadTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Title != "" {
+		t.Title = req.Title
+	}
+	if req.Description != "" {
+		t.Description = req.Description
+	}
+	if req.Priority != "" {
+		t.Priority = req.Priority
+	}
+	if req.Status != "" {
+		t.Status = req.Status
+	}
+	t.UpdatedAt = now()
+	switch {
+	case t.Status == "DONE" && t.CompletedAt == nil:
+		completedAt := t.UpdatedAt
+		t.CompletedAt = &completedAt
+	case t.Status != "DONE":
+		t.CompletedAt = nil
+	}
+	if err := s.saveTask(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// DeleteTask removes a task
+func (s *Store) DeleteTask(projectID, taskID string) error {
+	if err := s.checkProject(projectID); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := s.itemFile(tasksDir, taskID, ".md")
+	if err != nil {
+		return err
+	}
+	return removeItem(file, "task", taskID)
+}
+
+// loadTask reads a task by ID. The caller holds s.mu.
+func (s *Store) loadTask(id string) (*api.Task, error) {
+	file, err := s.itemFile(tasksDir, id, ".md")
+	if err != nil {
+		return nil, err
+	}
+	t, err := readTask(file)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("task %s not found", id)
+	}
+	return t, err
+}
+
+// saveTask writes a task. The caller holds s.mu.
+func (s *Store) saveTask(t *api.Task) error {
+	meta := taskMeta{
+		ID:               t.ID,
+		Status:           t.Status,
+		Priority:         t.Priority,
+		Owner:            t.OwnerID,
+		OwnerName:        t.OwnerName,
+		RelatedDecisions: t.RelatedDecisionIds,
+		RelatedMemories:  t.RelatedMemoryIds,
+		CreatedAt:        t.CreatedAt,
+		UpdatedAt:        t.UpdatedAt,
+	}
+	if t.CompletedAt != nil {
+		meta.CompletedAt = *t.CompletedAt
+	}
+	return writeDocument(s.path(tasksDir, t.ID+".md"), meta, joinTitle(t.Title, t.Description))
+}
+
+func readTask(file string) (*api.Task, error) {
+	var meta taskMeta
+	body, err := readDocument(file, &meta)
+	if err != nil {
+		return nil, err
+	}
+	title, description := splitTitle(body)
+	t := &api.Task{
+		ID:                 idOr(meta.ID, file),
+		Title:              title,
+		Description:        description,
+		Status:             meta.Status,
+		Priority:           meta.Priority,
+		CreatedAt:          meta.CreatedAt,
+		UpdatedAt:          meta.UpdatedAt,
+		OwnerID:            meta.Owner,
+		OwnerName:          meta.OwnerName,
+		RelatedDecisionIds: meta.RelatedDecisions,
+		RelatedMemoryIds:   meta.RelatedMemories,
+	}
+	if meta.CompletedAt != "" {
+		t.CompletedAt = &meta.CompletedAt
+	}
+	if t.Status == "" {
+		t.Status = "TODO"
+	}
+	if t.Priority == "" {
+		t.Priority = "MEDIUM"
+	}
+	return t, nil
+}
+
+func oneOf(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
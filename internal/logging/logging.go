@@ -0,0 +1,102 @@
+// Package logging holds the process-wide diagnostic logger used by
+// --verbose and --debug. Logging is off by default.
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Levels accepted by Setup. Verbose logs one line per request; Debug adds
+// headers and bodies.
+const (
+	LevelOff     = slog.Level(100)
+	LevelVerbose = slog.LevelInfo
+	LevelDebug   = slog.LevelDebug
+)
+
+// DebugEnv enables logging without flags: 1, true or debug for debug
+// output, verbose for one line per request
+const DebugEnv = "HOPSULE_DEBUG"
+
+// FileEnv sends logs to a file instead of stderr, like --log-file
+const FileEnv = "HOPSULE_LOG_FILE"
+
+var (
+	mu     sync.RWMutex
+	logger = slog.New(slog.DiscardHandler)
+	level  = LevelOff
+)
+
+// Options configures Setup
+type Options struct {
+	Level slog.Level
+	// File is the log file path. Empty logs to stderr.
+	File string
+}
+
+// Setup enables logging at opts.Level. Logs go to stderr as text, or to
+// opts.File as JSON lines, rotated when the file grows too large.
+func Setup(opts Options) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if opts.Level >= LevelOff {
+		logger = slog.New(slog.DiscardHandler)
+		level = LevelOff
+		return nil
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: opts.Level}
+	var handler slog.Handler
+	if opts.File == "" {
+		handler = slog.NewTextHandler(os.Stderr, handlerOpts)
+	} else {
+		w, err := openRotating(opts.File)
+		if err != nil {
+			return err
+		}
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	}
+
+	logger = slog.New(handler)
+	level = opts.Level
+	return nil
+}
+
+// Logger returns the diagnostic logger. It discards everything until Setup
+// enables logging.
+func Logger() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return logger
+}
+
+// Enabled reports whether messages at l are logged
+func Enabled(l slog.Level) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return l >= level
+}
+
+// ParseLevel reads a HOPSULE_DEBUG value
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "0", "false", "off":
+		return LevelOff, nil
+	case "1", "true", "debug":
+		return LevelDebug, nil
+	case "verbose", "info":
+		return LevelVerbose, nil
+	}
+	return LevelOff, fmt.Errorf("invalid %s value %q (use 1, debug or verbose)", DebugEnv, value)
+}
+
+// DefaultFile returns the log file path under the state directory
+func DefaultFile(stateDir string) string {
+	return filepath.Join(stateDir, "logs", "hopsule.log")
+}
@@ -0,0 +1,546 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// BRAIN
+// ============================================================================
+//
+// The Brain view explores the project's knowledge graph. It opens on a
+// picker listing the nodes; enter focuses one and draws its neighbors as a
+// tree of edges. Enter follows the selected edge, ← goes back along the
+// path, f cycles the node type shown and o opens the selected node's
+// detail.
+
+// brainNodeTypes are the node types in the order they're listed and cycled
+// through by the type filter
+var brainNodeTypes = []string{api.NodeDecision, api.NodeMemory, api.NodeTask, api.NodeCapsule, api.NodeCodeChunk}
+
+// brainVisible is how many nodes or neighbors are listed at once
+const brainVisible = 10
+
+type brainLoadedMsg struct {
+	stats *api.GraphStats
+	graph *api.Graph
+	err   error
+}
+
+type brainNeighborsLoadedMsg struct {
+	id        string
+	neighbors *api.GraphNeighbors
+	history   []string // nodes focused before this one
+	from      string   // neighbor to select, when going back
+	err       error
+}
+
+// loadBrain loads the graph stats and builds the graph the picker lists
+func (m model) loadBrain() tea.Msg {
+	if m.client == nil || m.currentProj == nil {
+		return brainLoadedMsg{err: fmt.Errorf("not authenticated or no project selected")}
+	}
+	projectID := m.currentProj.ID
+	stats, err := m.client.GetGraphStats(projectID)
+	if err != nil {
+		return brainLoadedMsg{err: err}
+	}
+	graph, err := api.LoadGraph(m.client, projectID)
+	if err != nil {
+		return brainLoadedMsg{err: err}
+	}
+	return brainLoadedMsg{stats: stats, graph: graph}
+}
+
+// focusNode focuses a node, listing the nodes one edge away. Servers with
+// the graph endpoints are asked for the neighbors, which include code
+// chunks; otherwise they come from the graph loaded with the view.
+func (m *model) focusNode(id string, history []string, from string) tea.Cmd {
+	if m.brainGraph == nil {
+		return nil
+	}
+	if client, ok := m.client.(*api.Client); ok && client.HasGraphEndpoints() {
+		m.loading = true
+		return m.loadNeighbors(client, id, history, from)
+	}
+	neighbors, err := m.brainGraph.Neighbors(id, api.NeighborOptions{Depth: 1})
+	if err != nil {
+		m.errorMsg = err.Error()
+		return nil
+	}
+	m.showNeighbors(neighbors, history, from)
+	return nil
+}
+
+// loadNeighbors asks the server for the nodes one edge away from a node
+func (m model) loadNeighbors(client *api.Client, id string, history []string, from string) tea.Cmd {
+	projectID := m.currentProj.ID
+	return func() tea.Msg {
+		neighbors, err := client.GetGraphNeighbors(projectID, id, api.NeighborOptions{Depth: 1})
+		return brainNeighborsLoadedMsg{id: id, neighbors: neighbors, history: history, from: from, err: err}
+	}
+}
+
+// neighborsLoaded shows the neighbors the server returned. If the request
+// failed, the loaded graph is used when it has the node.
+func (m *model) neighborsLoaded(msg brainNeighborsLoadedMsg) {
+	if msg.err != nil {
+		if _, ok := m.brainGraph.Node(msg.id); !ok {
+			m.errorMsg = msg.err.Error()
+			return
+		}
+		neighbors, err := m.brainGraph.Neighbors(msg.id, api.NeighborOptions{Depth: 1})
+		if err != nil {
+			m.errorMsg = err.Error()
+			return
+		}
+		m.showNeighbors(neighbors, msg.history, msg.from)
+		return
+	}
+	mergeNeighbors(m.brainGraph, msg.neighbors)
+	m.showNeighbors(msg.neighbors, msg.history, msg.from)
+}
+
+// showNeighbors focuses a node whose neighbors have been found
+func (m *model) showNeighbors(neighbors *api.GraphNeighbors, history []string, from string) {
+	sort.SliceStable(neighbors.Neighbors, func(i, j int) bool {
+		a, b := neighbors.Neighbors[i].Node, neighbors.Neighbors[j].Node
+		if ra, rb := brainTypeRank(a.Type), brainTypeRank(b.Type); ra != rb {
+			return ra < rb
+		}
+		return a.ID < b.ID
+	})
+	m.brainFocus = neighbors
+	m.brainHistory = history
+	m.brainSelect(from)
+}
+
+// mergeNeighbors adds the nodes and edges the server returned to the loaded
+// graph, so code chunks found while exploring can be picked and going back
+// to them works
+func mergeNeighbors(g *api.Graph, neighbors *api.GraphNeighbors) {
+	for _, n := range append([]api.GraphNeighbor{{Node: neighbors.Node}}, neighbors.Neighbors...) {
+		if _, ok := g.Node(n.Node.ID); !ok {
+			g.AddNode(n.Node)
+		}
+	}
+	for _, n := range neighbors.Neighbors {
+		known := slices.ContainsFunc(g.EdgesOf(n.Edge.Source), func(e api.GraphEdge) bool {
+			return e == n.Edge
+		})
+		if !known {
+			g.AddEdge(n.Edge)
+		}
+	}
+}
+
+// resetBrain drops the focus, path and type filter
+func (m *model) resetBrain() {
+	m.brainFocus = nil
+	m.brainHistory = nil
+	m.brainType = ""
+	m.selected = 0
+	m.scrollOffset = 0
+}
+
+// brainNodes are the picker's nodes of the filtered type
+func (m model) brainNodes() []api.GraphNode {
+	if m.brainGraph == nil {
+		return nil
+	}
+	var nodes []api.GraphNode
+	for _, n := range m.brainGraph.Nodes {
+		if m.brainType == "" || n.Type == m.brainType {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// brainNeighbors are the focused node's neighbors of the filtered type
+func (m model) brainNeighbors() []api.GraphNeighbor {
+	if m.brainFocus == nil {
+		return nil
+	}
+	var neighbors []api.GraphNeighbor
+	for _, n := range m.brainFocus.Neighbors {
+		if m.brainType == "" || n.Node.Type == m.brainType {
+			neighbors = append(neighbors, n)
+		}
+	}
+	return neighbors
+}
+
+// brainCount is how many nodes or neighbors can be selected
+func (m model) brainCount() int {
+	if m.brainFocus != nil {
+		return len(m.brainNeighbors())
+	}
+	return len(m.brainNodes())
+}
+
+// brainSelected returns the node under the cursor
+func (m model) brainSelected() (api.GraphNode, bool) {
+	if m.brainFocus != nil {
+		neighbors := m.brainNeighbors()
+		if m.selected < len(neighbors) {
+			return neighbors[m.selected].Node, true
+		}
+		return api.GraphNode{}, false
+	}
+	nodes := m.brainNodes()
+	if m.selected < len(nodes) {
+		return nodes[m.selected], true
+	}
+	return api.GraphNode{}, false
+}
+
+// brainSelect moves the cursor to the node with id, if it's listed
+func (m *model) brainSelect(id string) {
+	m.selected = 0
+	m.scrollOffset = 0
+	var ids []string
+	if m.brainFocus != nil {
+		for _, n := range m.brainNeighbors() {
+			ids = append(ids, n.Node.ID)
+		}
+	} else {
+		for _, n := range m.brainNodes() {
+			ids = append(ids, n.ID)
+		}
+	}
+	for i, nodeID := range ids {
+		if nodeID == id {
+			m.selected = i
+			if i >= brainVisible {
+				m.scrollOffset = i - brainVisible + 1
+			}
+			return
+		}
+	}
+}
+
+// handleBrainKey handles the explorer's own keys. Navigation up and down
+// and leaving the view fall through to the shared key handling.
+func (m model) handleBrainKey(msg tea.KeyMsg) (model, tea.Cmd, bool) {
+	switch msg.String() {
+	case "f", "tab":
+		i := 0
+		if m.brainType != "" {
+			i = brainTypeRank(m.brainType) + 1
+		}
+		if i < len(brainNodeTypes) {
+			m.brainType = brainNodeTypes[i]
+		} else {
+			m.brainType = ""
+		}
+		m.selected = 0
+		m.scrollOffset = 0
+		return m, nil, true
+
+	case "enter", "right", "l":
+		node, ok := m.brainSelected()
+		if !ok {
+			return m, nil, true
+		}
+		var history []string
+		if m.brainFocus != nil {
+			history = append(append(history, m.brainHistory...), m.brainFocus.Node.ID)
+		}
+		return m, m.focusNode(node.ID, history, ""), true
+
+	case "left", "h", "backspace":
+		if m.brainFocus == nil {
+			return m, nil, true
+		}
+		from := m.brainFocus.Node.ID
+		if len(m.brainHistory) == 0 {
+			m.brainFocus = nil
+			m.brainSelect(from)
+			return m, nil, true
+		}
+		last := len(m.brainHistory) - 1
+		history := m.brainHistory[:last:last]
+		return m, m.focusNode(m.brainHistory[last], history, from), true
+
+	case "esc":
+		// Back to the picker; esc in the picker leaves the view
+		if m.brainFocus == nil {
+			return m, nil, false
+		}
+		from := m.brainFocus.Node.ID
+		if len(m.brainHistory) > 0 {
+			from = m.brainHistory[0]
+		}
+		m.brainFocus = nil
+		m.brainHistory = nil
+		m.brainSelect(from)
+		return m, nil, true
+
+	case "o":
+		node, ok := m.brainSelected()
+		if !ok && m.brainFocus != nil {
+			node, ok = m.brainFocus.Node, true
+		}
+		if !ok {
+			return m, nil, true
+		}
+		if _, ok := listView(node.Type); !ok {
+			m.errorMsg = fmt.Sprintf("%s %s has no detail view", node.Type, node.ID)
+			return m, nil, true
+		}
+		m.brainReturn = &brainReturn{selected: m.selected, scrollOffset: m.scrollOffset}
+		m.target = &Target{ProjectID: m.currentProj.ID, Type: node.Type, ID: node.ID}
+		m, cmd := m.openTarget()
+		if m.currentView == viewBrain {
+			m.brainReturn = nil
+		}
+		return m, cmd, true
+	}
+	return m, nil, false
+}
+
+// brainReturn is where the explorer was when a node's detail was opened,
+// so closing the detail goes back to it
+type brainReturn struct {
+	selected     int
+	scrollOffset int
+}
+
+// returnToBrain closes a detail opened from the explorer
+func (m *model) returnToBrain() {
+	m.currentView = viewBrain
+	m.selected = m.brainReturn.selected
+	m.scrollOffset = m.brainReturn.scrollOffset
+	m.brainReturn = nil
+	m.clearFilter()
+}
+
+// brainTypeRank orders node types as brainNodeTypes lists them, unknown
+// types last
+func brainTypeRank(nodeType string) int {
+	for i, t := range brainNodeTypes {
+		if t == nodeType {
+			return i
+		}
+	}
+	return len(brainNodeTypes)
+}
+
+// brainNodeStyle returns a node type's icon and colour
+func brainNodeStyle(nodeType string) (string, lipgloss.Color) {
+	switch nodeType {
+	case api.NodeDecision:
+		return "📋", cyanColor
+	case api.NodeMemory:
+		return "💾", magentaColor
+	case api.NodeTask:
+		return "✅", greenColor
+	case api.NodeCapsule:
+		return "📦", yellowColor
+	case api.NodeCodeChunk:
+		return "📄", blueColor
+	}
+	return "○", grayColor
+}
+
+// brainNodeLabel is the first line of a node's label, or a code chunk's
+// path, cut to width characters
+func brainNodeLabel(n api.GraphNode, width int) string {
+	label, _, _ := strings.Cut(n.Label, "\n")
+	if label == "" {
+		label = n.Path
+	}
+	return truncate(label, width)
+}
+
+func (m model) renderBrainView() string {
+	var s string
+
+	// Title
+	s += "\n"
+	s += "  " + titleStyle.Render("🧠 Brain") + "\n"
+	if m.currentProj != nil {
+		s += "  " + dimStyle.Render(m.currentProj.Name) + "\n"
+	}
+	s += "\n"
+
+	if m.graphStats == nil {
+		s += "  " + dimStyle.Render("Loading brain stats...") + "\n"
+		return s
+	}
+
+	if m.brainFocus != nil {
+		s += m.renderBrainFocus()
+	} else {
+		s += m.renderBrainPicker()
+	}
+
+	filter := "all types"
+	if m.brainType != "" {
+		filter = m.brainType
+	}
+	s += "\n  " + dimStyle.Render("Showing: "+filter+" • [f] change") + "\n"
+	return s
+}
+
+// renderBrainPicker shows the graph's stats and the nodes to pick from
+func (m model) renderBrainPicker() string {
+	var s string
+
+	// Stats cards - fixed width with margin
+	statStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(grayColor).
+		Padding(1, 3).
+		Width(25).
+		MarginRight(2)
+
+	nodeCard := statStyle.Render(fmt.Sprintf("🔵 Nodes\n%s",
+		lipgloss.NewStyle().Bold(true).Foreground(cyanColor).Render(fmt.Sprintf("%d", m.graphStats.NodeCount))))
+
+	edgeCard := statStyle.Render(fmt.Sprintf("🔗 Connections\n%s",
+		lipgloss.NewStyle().Bold(true).Foreground(magentaColor).Render(fmt.Sprintf("%d", m.graphStats.EdgeCount))))
+
+	row := lipgloss.JoinHorizontal(lipgloss.Top, nodeCard, edgeCard)
+	s += lipgloss.NewStyle().MarginLeft(2).Render(row) + "\n\n"
+
+	// Node types breakdown, in a fixed order
+	var types []string
+	for nodeType := range m.graphStats.NodesByType {
+		types = append(types, nodeType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if ri, rj := brainTypeRank(types[i]), brainTypeRank(types[j]); ri != rj {
+			return ri < rj
+		}
+		return types[i] < types[j]
+	})
+	var counts []string
+	for _, nodeType := range types {
+		icon, color := brainNodeStyle(nodeType)
+		counts = append(counts, fmt.Sprintf("%s %s: %s", icon, nodeType,
+			lipgloss.NewStyle().Foreground(color).Bold(true).Render(fmt.Sprintf("%d", m.graphStats.NodesByType[nodeType]))))
+	}
+	if len(counts) > 0 {
+		s += "  " + strings.Join(counts, "   ") + "\n\n"
+	}
+
+	s += "  " + dimStyle.Render("─────────────────────────────────────────────────────") + "\n\n"
+	s += "  " + titleStyle.Render("Pick a node") + "\n\n"
+
+	nodes := m.brainNodes()
+	if len(nodes) == 0 {
+		s += "  " + dimStyle.Render("No nodes to explore") + "\n"
+		return s
+	}
+
+	end := min(m.scrollOffset+brainVisible, len(nodes))
+	if m.scrollOffset > 0 {
+		s += "  " + dimStyle.Render(fmt.Sprintf("  ↑ %d more", m.scrollOffset)) + "\n"
+	}
+	for i := m.scrollOffset; i < end; i++ {
+		n := nodes[i]
+		icon, _ := brainNodeStyle(n.Type)
+		line := fmt.Sprintf("%s %-14s %s", icon, n.ID, brainNodeLabel(n, 50))
+		if n.Status != "" {
+			line += "  [" + n.Status + "]"
+		}
+		if i == m.selected {
+			s += "  " + selectedStyle.Render("▸ "+line) + "\n"
+		} else {
+			s += "  " + normalStyle.Render("  "+line) + "\n"
+		}
+	}
+	if end < len(nodes) {
+		s += "  " + dimStyle.Render(fmt.Sprintf("  ↓ %d more", len(nodes)-end)) + "\n"
+	}
+	return s
+}
+
+// renderBrainFocus draws the focused node in a box with its neighbors
+// hanging off it, one edge per branch
+func (m model) renderBrainFocus() string {
+	var s string
+	focus := m.brainFocus.Node
+	icon, color := brainNodeStyle(focus.Type)
+
+	meta := focus.Type
+	if focus.Status != "" {
+		meta += " · " + focus.Status
+	}
+	if len(focus.Tags) > 0 {
+		meta += " · #" + strings.Join(focus.Tags, " #")
+	}
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(color).
+		Padding(0, 1).
+		Render(fmt.Sprintf("%s %s  %s\n%s",
+			icon,
+			lipgloss.NewStyle().Bold(true).Foreground(color).Render(focus.ID),
+			brainNodeLabel(focus, 60),
+			dimStyle.Render(meta)))
+	s += lipgloss.NewStyle().MarginLeft(2).Render(box) + "\n"
+
+	neighbors := m.brainNeighbors()
+	if len(neighbors) == 0 {
+		s += "  " + dimStyle.Render("   (no neighbors)") + "\n"
+	} else {
+		s += "  " + dimStyle.Render("   │") + "\n"
+
+		edgeWidth := 0
+		for _, n := range neighbors {
+			edgeWidth = max(edgeWidth, len(n.Edge.Type))
+		}
+		end := min(m.scrollOffset+brainVisible, len(neighbors))
+		if m.scrollOffset > 0 {
+			s += "  " + dimStyle.Render(fmt.Sprintf("   ┆ ↑ %d more", m.scrollOffset)) + "\n"
+		}
+		for i := m.scrollOffset; i < end; i++ {
+			n := neighbors[i]
+			branch := "├──"
+			if i == len(neighbors)-1 {
+				branch = "└──"
+			}
+			// Edges point from source to target
+			arrow := "──▶"
+			if n.Edge.Source != focus.ID {
+				arrow = "◀──"
+			}
+			nodeIcon, _ := brainNodeStyle(n.Node.Type)
+			edge := fmt.Sprintf("%-*s %s", edgeWidth, n.Edge.Type, arrow)
+			node := fmt.Sprintf("%s %-14s %s", nodeIcon, n.Node.ID, brainNodeLabel(n.Node, 40))
+			if i == m.selected {
+				s += "  " + dimStyle.Render("   "+branch) + " " + selectedStyle.Render(edge+" "+node) + "\n"
+			} else {
+				s += "  " + dimStyle.Render("   "+branch+" "+edge) + " " + normalStyle.Render(node) + "\n"
+			}
+		}
+		if end < len(neighbors) {
+			s += "  " + dimStyle.Render(fmt.Sprintf("   ┆ ↓ %d more", len(neighbors)-end)) + "\n"
+		}
+	}
+
+	// Preview of the selected neighbor
+	if node, ok := m.brainSelected(); ok {
+		preview := fmt.Sprintf("%s %s", node.Type, node.ID)
+		if node.Status != "" {
+			preview += " [" + node.Status + "]"
+		}
+		preview += ": " + brainNodeLabel(node, 70)
+		s += "\n  " + accentStyle.Render(preview) + "\n"
+	}
+
+	// Path taken to the focused node
+	path := append(append([]string{}, m.brainHistory...), focus.ID)
+	s += "\n  " + breadcrumbStyle.Render("Path: "+strings.Join(path, " → ")) + "\n"
+	return s
+}
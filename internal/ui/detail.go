@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/cache"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// DETAIL
+// ============================================================================
+//
+// Enter on a decision, memory, task or capsule shows all of its fields in
+// place of the list; esc goes back to the list. A Target opens the
+// dashboard straight on one item's detail, e.g. from 'hopsule search --open'.
+
+// Target is an item to open the dashboard on
+type Target struct {
+	ProjectID string
+	// Type is one of the cache item types (decision, memory, task, capsule)
+	Type string
+	ID   string
+}
+
+// listView returns the list view showing items of a cache item type
+func listView(itemType string) (viewType, bool) {
+	switch itemType {
+	case cache.TypeDecision:
+		return viewDecisions, true
+	case cache.TypeMemory:
+		return viewMemories, true
+	case cache.TypeTask:
+		return viewTasks, true
+	case cache.TypeCapsule:
+		return viewCapsules, true
+	}
+	return 0, false
+}
+
+// hasDetail reports whether the current view's items can be opened
+func (m model) hasDetail() bool {
+	switch m.currentView {
+	case viewDecisions, viewMemories, viewTasks, viewCapsules:
+		return m.selected < m.getMaxSelection()
+	}
+	return false
+}
+
+// openTarget opens the target's project and loads the list holding it,
+// once the projects are known
+func (m model) openTarget() (model, tea.Cmd) {
+	target := m.target
+	view, ok := listView(target.Type)
+	if !ok {
+		m.target = nil
+		m.errorMsg = fmt.Sprintf("Can't open a %s", target.Type)
+		return m, nil
+	}
+
+	var proj *api.Project
+	for _, p := range m.projects {
+		if p.ID == target.ProjectID {
+			proj = p
+			break
+		}
+	}
+	if proj == nil {
+		m.target = nil
+		m.errorMsg = fmt.Sprintf("Project %s not found", target.ProjectID)
+		return m, nil
+	}
+	for _, org := range m.organizations {
+		if org.ID == proj.OrganizationID {
+			m.currentOrg = org
+			break
+		}
+	}
+	m.openProject(proj)
+
+	m.currentView = view
+	m.selected = 0
+	m.scrollOffset = 0
+	m.loading = true
+	switch view {
+	case viewDecisions:
+		return m, m.loadDecisions
+	case viewMemories:
+		return m, m.loadMemories
+	case viewTasks:
+		return m, m.loadTasks
+	default:
+		return m, m.loadCapsules
+	}
+}
+
+// selectTarget selects the target in the list that was just loaded and
+// shows its detail
+func (m *model) selectTarget() {
+	if m.target == nil {
+		return
+	}
+	if view, _ := listView(m.target.Type); view != m.currentView {
+		return
+	}
+	id := m.target.ID
+	m.target = nil
+
+	var ids []string
+	switch m.currentView {
+	case viewDecisions:
+		for _, d := range m.decisions {
+			ids = append(ids, d.ID)
+		}
+	case viewMemories:
+		for _, mem := range m.memories {
+			ids = append(ids, mem.ID)
+		}
+	case viewTasks:
+		for _, t := range m.tasks {
+			ids = append(ids, t.ID)
+		}
+	case viewCapsules:
+		for _, c := range m.capsules {
+			ids = append(ids, c.ID)
+		}
+	}
+	for i, itemID := range ids {
+		if itemID == id {
+			m.selected = i
+			// Keep the selection on the list's page of 10
+			if i >= 10 {
+				m.scrollOffset = i - 9
+			}
+			m.showDetail = true
+			return
+		}
+	}
+	m.errorMsg = fmt.Sprintf("%s %s not found", m.currentView.itemName(), id)
+}
+
+// itemName names the items in a list view
+func (v viewType) itemName() string {
+	switch v {
+	case viewDecisions:
+		return "Decision"
+	case viewMemories:
+		return "Memory"
+	case viewTasks:
+		return "Task"
+	case viewCapsules:
+		return "Capsule"
+	}
+	return "Item"
+}
+
+// detailField is one labelled line of the detail view
+type detailField struct {
+	label string
+	value string
+}
+
+// renderDetailView shows every field of the selected item
+func (m model) renderDetailView() string {
+	var title, body string
+	var fields []detailField
+	icon := ""
+
+	switch m.currentView {
+	case viewDecisions:
+		d := m.decisions[m.selected]
+		icon, title, body = "📋", d.Statement, d.Rationale
+		fields = []detailField{
+			{"ID", d.ID},
+			{"Status", d.Status},
+			{"Scope", d.ScopeKey},
+			{"Tags", strings.Join(d.Tags, ", ")},
+			{"Created", d.CreatedAt},
+			{"Updated", d.UpdatedAt},
+		}
+		if d.AcceptedAt != nil {
+			accepted := *d.AcceptedAt
+			if d.AcceptedBy != nil {
+				accepted += " by " + *d.AcceptedBy
+			}
+			fields = append(fields, detailField{"Accepted", accepted})
+		}
+	case viewMemories:
+		mem := m.memories[m.selected]
+		icon, title, body = "💾", "Memory", mem.Content
+		fields = []detailField{
+			{"ID", mem.ID},
+			{"Tags", strings.Join(mem.Tags, ", ")},
+			{"Decisions", strings.Join(mem.RelatedDecisionIds, ", ")},
+			{"Author", mem.CreatedByName},
+			{"Created", mem.CreatedAt},
+			{"Updated", mem.UpdatedAt},
+		}
+	case viewTasks:
+		t := m.tasks[m.selected]
+		icon, title, body = "✅", t.Title, t.Description
+		fields = []detailField{
+			{"ID", t.ID},
+			{"Status", t.Status},
+			{"Priority", t.Priority},
+			{"Owner", t.OwnerName},
+			{"Decisions", strings.Join(t.RelatedDecisionIds, ", ")},
+			{"Memories", strings.Join(t.RelatedMemoryIds, ", ")},
+			{"Created", t.CreatedAt},
+			{"Updated", t.UpdatedAt},
+		}
+		if t.CompletedAt != nil {
+			fields = append(fields, detailField{"Completed", *t.CompletedAt})
+		}
+	case viewCapsules:
+		c := m.capsules[m.selected]
+		icon, title, body = "📦", c.Name, c.Description
+		fields = []detailField{
+			{"ID", c.ID},
+			{"Status", c.Status},
+			{"Decisions", strings.Join(c.DecisionIds, ", ")},
+			{"Memories", strings.Join(c.MemoryIds, ", ")},
+			{"Created", c.CreatedAt},
+			{"Updated", c.UpdatedAt},
+		}
+		if c.FrozenAt != nil {
+			fields = append(fields, detailField{"Frozen", *c.FrozenAt})
+		}
+	}
+
+	var s string
+	s += "\n"
+	s += "  " + titleStyle.Render(icon+" "+m.currentView.itemName()) + "\n"
+	if m.currentProj != nil {
+		s += "  " + dimStyle.Render(m.currentProj.Name) + "\n"
+	}
+	s += "\n"
+
+	labelStyle := lipgloss.NewStyle().Foreground(dimColor).Width(11)
+	content := cardTitleStyle.Render(title) + "\n\n"
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		content += labelStyle.Render(f.label) + normalStyle.Render(f.value) + "\n"
+	}
+	if body != "" && body != title {
+		content += "\n" + cardDescStyle.Render(body)
+	}
+
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(grayColor).
+		Padding(1, 2).
+		MarginLeft(2).
+		Width(80)
+	s += boxStyle.Render(strings.TrimRight(content, "\n")) + "\n"
+
+	s += "\n"
+	s += "  " + dimStyle.Render(fmt.Sprintf("%d of %d", m.selected+1, m.getMaxSelection())) + "\n"
+
+	return s
+}
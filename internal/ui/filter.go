@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/Cagangedik/cli-tool/internal/cache"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ============================================================================
+// FILTER
+// ============================================================================
+//
+// Pressing / in the decision, memory and task lists filters them as you
+// type. Lists are indexed in the offline cache when they load, so each
+// keystroke is a full-text query against a local database rather than a
+// request.
+
+// openSearch opens the cache for the backend selected in cfg; without it
+// the lists can't be filtered
+func (m *model) openSearch(cfg *config.Config) {
+	if m.search != nil {
+		m.search.Close()
+	}
+	m.search, m.searchErr = cache.Open(cache.Source(cfg))
+}
+
+// filterType returns the cache item type listed in the current view, or ""
+// if the view can't be filtered
+func (m model) filterType() string {
+	switch m.currentView {
+	case viewDecisions:
+		return cache.TypeDecision
+	case viewMemories:
+		return cache.TypeMemory
+	case viewTasks:
+		return cache.TypeTask
+	}
+	return ""
+}
+
+// startFilter starts typing a filter in a list view
+func (m model) startFilter() model {
+	if m.filterType() == "" {
+		return m
+	}
+	if m.search == nil {
+		m.errorMsg = fmt.Sprintf("Filter unavailable: %v", m.searchErr)
+		return m
+	}
+	m.filtering = true
+	m.showDetail = false
+	return m
+}
+
+// handleFilterKey edits the filter while it is being typed
+func (m model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.filtering = false
+		m.filterQuery = ""
+	case "enter":
+		m.filtering = false
+		return m, nil
+	case "backspace":
+		if len(m.filterQuery) > 0 {
+			runes := []rune(m.filterQuery)
+			m.filterQuery = string(runes[:len(runes)-1])
+		}
+	case "space":
+		m.filterQuery += " "
+	default:
+		if len(msg.Runes) == 0 {
+			return m, nil
+		}
+		m.filterQuery += string(msg.Runes)
+	}
+
+	m.selected = 0
+	m.scrollOffset = 0
+	m.applyFilter()
+	return m, nil
+}
+
+// clearFilter drops the filter, e.g. when another list is opened
+func (m *model) clearFilter() {
+	m.filtering = false
+	m.filterQuery = ""
+}
+
+// applyFilter sets the current view's list from the loaded one, keeping
+// only the items matching the filter, in their original order
+func (m *model) applyFilter() {
+	switch m.filterType() {
+	case cache.TypeDecision:
+		m.decisions = m.allDecisions
+	case cache.TypeMemory:
+		m.memories = m.allMemories
+	case cache.TypeTask:
+		m.tasks = m.allTasks
+	default:
+		return
+	}
+	if m.filterQuery == "" || m.search == nil || m.currentProj == nil {
+		return
+	}
+
+	results, err := m.search.Search(m.currentProj.ID, m.filterQuery, cache.SearchOptions{
+		Types: []string{m.filterType()},
+		Limit: -1,
+	})
+	if err != nil {
+		m.errorMsg = fmt.Sprintf("Filter failed: %v", err)
+		return
+	}
+	matched := make(map[string]bool, len(results))
+	for _, r := range results {
+		matched[r.ID] = true
+	}
+
+	switch m.filterType() {
+	case cache.TypeDecision:
+		m.decisions = nil
+		for _, d := range m.allDecisions {
+			if matched[d.ID] {
+				m.decisions = append(m.decisions, d)
+			}
+		}
+	case cache.TypeMemory:
+		m.memories = nil
+		for _, mem := range m.allMemories {
+			if matched[mem.ID] {
+				m.memories = append(m.memories, mem)
+			}
+		}
+	case cache.TypeTask:
+		m.tasks = nil
+		for _, t := range m.allTasks {
+			if matched[t.ID] {
+				m.tasks = append(m.tasks, t)
+			}
+		}
+	}
+
+	if m.selected >= m.getMaxSelection() {
+		m.selected = 0
+		m.scrollOffset = 0
+	}
+}
+
+// index replaces the cached items of one type with a freshly loaded list.
+// It runs in the load command, so the cache is current before the list is
+// shown. Failures only make the filter stale, so they are ignored.
+func (m model) index(itemType string, items []cache.Item) {
+	if m.search == nil || m.currentProj == nil {
+		return
+	}
+	m.search.Replace(m.currentProj.ID, itemType, items)
+}
+
+// renderFilterBar shows the filter being typed or applied
+func (m model) renderFilterBar() string {
+	if !m.filtering && m.filterQuery == "" {
+		return ""
+	}
+
+	total, shown := 0, 0
+	switch m.filterType() {
+	case cache.TypeDecision:
+		total, shown = len(m.allDecisions), len(m.decisions)
+	case cache.TypeMemory:
+		total, shown = len(m.allMemories), len(m.memories)
+	case cache.TypeTask:
+		total, shown = len(m.allTasks), len(m.tasks)
+	}
+
+	cursor := ""
+	if m.filtering {
+		cursor = "▌"
+	}
+	queryStyle := lipgloss.NewStyle().Foreground(yellowColor).Bold(true)
+	return "  " + dimStyle.Render("/") + queryStyle.Render(m.filterQuery) + cursor + "  " +
+		dimStyle.Render(fmt.Sprintf("%d of %d", shown, total)) + "\n"
+}
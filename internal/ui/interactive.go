@@ -1,13 +1,14 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
-	"os/exec"
-	"runtime"
 	"strings"
 	"time"
 
 	"github.com/Cagangedik/cli-tool/internal/api"
+	"github.com/Cagangedik/cli-tool/internal/auth"
+	"github.com/Cagangedik/cli-tool/internal/cache"
 	"github.com/Cagangedik/cli-tool/internal/config"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -108,7 +109,8 @@ const (
 
 type model struct {
 	cfg           *config.Config
-	client        *api.Client
+	client        api.Service
+	clientErr     error
 	currentView   viewType
 	
 	// Data
@@ -127,6 +129,28 @@ type model struct {
 	capsules      []*api.Capsule
 	graphStats    *api.GraphStats
 	
+	// Unfiltered lists; the fields above hold what the filter lets through
+	allDecisions  []api.Decision
+	allMemories   []*api.Memory
+	allTasks      []*api.Task
+	
+	// Filter state (see filter.go)
+	search        *cache.Cache
+	searchErr     error
+	filtering     bool
+	filterQuery   string
+	
+	// Detail state (see detail.go)
+	showDetail    bool
+	target        *Target // item to open once its list has loaded
+	
+	// Brain explorer state (see brain.go)
+	brainGraph    *api.Graph
+	brainFocus    *api.GraphNeighbors // nil while picking a node
+	brainHistory  []string            // nodes focused before brainFocus
+	brainType     string              // node type shown, "" for all
+	brainReturn   *brainReturn        // set while a detail opened from Brain is shown
+	
 	// Hopper chat state
 	chatMessages      []api.ChatMessage
 	chatInput         string
@@ -146,6 +170,13 @@ type model struct {
 	height        int
 	loading       bool
 	errorMsg      string
+	sessionExpired bool // show why the login view is back
+	
+	// Login state (see login.go)
+	loginFlow     *auth.DeviceFlow
+	loginEvent    auth.Event
+	loginQR       string
+	loginNote     string
 	
 	// Actions
 	executeCmd    string
@@ -193,11 +224,6 @@ type capsulesLoadedMsg struct {
 	err      error
 }
 
-type brainStatsLoadedMsg struct {
-	stats *api.GraphStats
-	err   error
-}
-
 type dashboardLoadedMsg struct {
 	decisions []api.Decision
 	memories  []*api.Memory
@@ -226,20 +252,23 @@ type hopperContextLoadedMsg struct {
 // ============================================================================
 
 func NewInteractiveModel(cfg *config.Config) model {
-	isLoggedIn := cfg != nil && cfg.IsAuthenticated()
+	// The local backend needs no sign-in
+	isLoggedIn := cfg != nil && (cfg.IsAuthenticated() || config.ResolveBackend(cfg) == config.BackendLocal)
 	
 	m := model{
 		cfg:      cfg,
 		selected: 0,
 		hopperSessionID: fmt.Sprintf("cli-%d", time.Now().UnixNano()),
 	}
+	m.openSearch(cfg)
 	
 	if isLoggedIn {
-		m.client = api.NewClient(cfg)
+		m.connect(cfg)
 		m.currentView = viewOrganizations
 		m.loading = true
 	} else {
 		m.currentView = viewLogin
+		m.sessionExpired = cfg != nil && cfg.SessionExpired()
 	}
 	
 	return m
@@ -252,7 +281,16 @@ func (m model) Init() tea.Cmd {
 	return nil
 }
 
+// connect creates the client for the backend selected in cfg. A backend
+// that can't be created is reported when data is loaded.
+func (m *model) connect(cfg *config.Config) {
+	m.client, m.clientErr = api.NewService(cfg)
+}
+
 func (m model) loadData() tea.Msg {
+	if m.clientErr != nil {
+		return dataLoadedMsg{err: m.clientErr}
+	}
 	if m.client == nil {
 		return dataLoadedMsg{err: fmt.Errorf("not authenticated")}
 	}
@@ -276,6 +314,7 @@ func (m model) loadDecisions() tea.Msg {
 	if err != nil {
 		return decisionsLoadedMsg{err: err}
 	}
+	m.index(cache.TypeDecision, cache.DecisionItems(decisions))
 	return decisionsLoadedMsg{decisions: decisions}
 }
 
@@ -287,6 +326,7 @@ func (m model) loadMemories() tea.Msg {
 	if err != nil {
 		return memoriesLoadedMsg{err: err}
 	}
+	m.index(cache.TypeMemory, cache.MemoryItems(memories))
 	return memoriesLoadedMsg{memories: memories}
 }
 
@@ -298,6 +338,7 @@ func (m model) loadTasks() tea.Msg {
 	if err != nil {
 		return tasksLoadedMsg{err: err}
 	}
+	m.index(cache.TypeTask, cache.TaskItems(tasks))
 	return tasksLoadedMsg{tasks: tasks}
 }
 
@@ -312,17 +353,6 @@ func (m model) loadCapsules() tea.Msg {
 	return capsulesLoadedMsg{capsules: capsules}
 }
 
-func (m model) loadBrainStats() tea.Msg {
-	if m.client == nil || m.currentProj == nil {
-		return brainStatsLoadedMsg{err: fmt.Errorf("not authenticated or no project selected")}
-	}
-	stats, err := m.client.GetGraphStats(m.currentProj.ID)
-	if err != nil {
-		return brainStatsLoadedMsg{err: err}
-	}
-	return brainStatsLoadedMsg{stats: stats}
-}
-
 // loadHopperContext loads decisions and memories for RAG context
 func (m model) loadHopperContext() tea.Msg {
 	if m.client == nil || m.currentProj == nil {
@@ -434,7 +464,10 @@ func (m model) loadDashboardData() tea.Msg {
 		return dashboardLoadedMsg{err: fmt.Errorf("not authenticated or no project selected")}
 	}
 	
-	decisions, _ := m.client.ListDecisions(m.currentProj.ID)
+	decisions, err := m.client.ListDecisions(m.currentProj.ID)
+	if errors.Is(err, api.ErrSessionExpired) {
+		return dashboardLoadedMsg{err: err}
+	}
 	memories, _ := m.client.ListMemories(m.currentProj.ID)
 	tasks, _ := m.client.ListTasks(m.currentProj.ID)
 	capsules, _ := m.client.ListCapsules(m.currentProj.ID)
@@ -448,6 +481,10 @@ func (m model) loadDashboardData() tea.Msg {
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if errors.Is(messageError(msg), api.ErrSessionExpired) {
+		return m.expireSession(), nil
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
@@ -464,14 +501,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.organizations = msg.organizations
 			m.projects = msg.projects
+			if m.target != nil {
+				return m.openTarget()
+			}
 		}
 		return m, nil
 		
+	case loginEventMsg:
+		return m.handleLoginEvent(msg)
+		
 	case loginCompleteMsg:
 		if msg.success {
 			// Reload config and data
 			m.cfg, _ = config.GetConfig()
-			m.client = api.NewClient(m.cfg)
+			m.connect(m.cfg)
+			m.openSearch(m.cfg)
 			m.currentView = viewOrganizations
 			m.loading = true
 			m.selected = 0
@@ -486,7 +530,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.err != nil {
 			m.errorMsg = msg.err.Error()
 		} else {
-			m.decisions = msg.decisions
+			m.allDecisions = msg.decisions
+			m.applyFilter()
+			m.selectTarget()
 		}
 		return m, nil
 		
@@ -495,7 +541,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.err != nil {
 			m.errorMsg = msg.err.Error()
 		} else {
-			m.memories = msg.memories
+			m.allMemories = msg.memories
+			m.applyFilter()
+			m.selectTarget()
 		}
 		return m, nil
 		
@@ -504,7 +552,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if msg.err != nil {
 			m.errorMsg = msg.err.Error()
 		} else {
-			m.tasks = msg.tasks
+			m.allTasks = msg.tasks
+			m.applyFilter()
+			m.selectTarget()
 		}
 		return m, nil
 		
@@ -514,15 +564,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.errorMsg = msg.err.Error()
 		} else {
 			m.capsules = msg.capsules
+			m.selectTarget()
 		}
 		return m, nil
 		
-	case brainStatsLoadedMsg:
+	case brainLoadedMsg:
 		m.loading = false
 		if msg.err != nil {
 			m.errorMsg = msg.err.Error()
 		} else {
 			m.graphStats = msg.stats
+			m.brainGraph = msg.graph
+		}
+		return m, nil
+		
+	case brainNeighborsLoadedMsg:
+		m.loading = false
+		if m.brainGraph != nil {
+			m.neighborsLoaded(msg)
 		}
 		return m, nil
 		
@@ -571,6 +630,47 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// messageError returns the error carried by a load message, if any
+func messageError(msg tea.Msg) error {
+	switch msg := msg.(type) {
+	case dataLoadedMsg:
+		return msg.err
+	case loginCompleteMsg:
+		return msg.err
+	case decisionsLoadedMsg:
+		return msg.err
+	case memoriesLoadedMsg:
+		return msg.err
+	case tasksLoadedMsg:
+		return msg.err
+	case capsulesLoadedMsg:
+		return msg.err
+	case brainLoadedMsg:
+		return msg.err
+	case brainNeighborsLoadedMsg:
+		return msg.err
+	case dashboardLoadedMsg:
+		return msg.err
+	case chatStreamDoneMsg:
+		return msg.err
+	case hopperContextLoadedMsg:
+		return msg.err
+	}
+	return nil
+}
+
+// expireSession drops the client and sends the user back to the login view
+func (m model) expireSession() model {
+	m.client = nil
+	m.currentView = viewLogin
+	m.selected = 0
+	m.loading = false
+	m.chatStreaming = false
+	m.errorMsg = ""
+	m.sessionExpired = true
+	return m
+}
+
 func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Clear error on any key press
 	m.errorMsg = ""
@@ -614,6 +714,46 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 	
+	// While a filter is typed every key edits it; esc also clears an
+	// applied filter before leaving the list
+	if m.filtering {
+		return m.handleFilterKey(msg)
+	}
+	if msg.String() == "esc" && m.filterQuery != "" && m.filterType() != "" && !m.showDetail {
+		m.clearFilter()
+		m.applyFilter()
+		return m, nil
+	}
+	
+	// An open detail goes back to its list, or to Brain when opened there
+	if m.showDetail {
+		switch msg.String() {
+		case "esc", "q", "enter", " ":
+			m.showDetail = false
+			if m.brainReturn != nil {
+				m.returnToBrain()
+			}
+			return m, nil
+		}
+	}
+	
+	if m.currentView == viewBrain {
+		if m, cmd, handled := m.handleBrainKey(msg); handled {
+			return m, cmd
+		}
+	}
+	
+	// A running login only listens for cancellation
+	if m.currentView == viewLogin && m.loginInProgress() {
+		switch msg.String() {
+		case "esc", "q":
+			return m.cancelLogin(), nil
+		case "ctrl+c":
+			return m.cancelLogin(), tea.Quit
+		}
+		return m, nil
+	}
+	
 	switch msg.String() {
 	case "ctrl+c", "q":
 		// Feature views go back to project menu
@@ -622,6 +762,8 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.currentView == viewTasks || m.currentView == viewBrain {
 			m.currentView = viewProjectMenu
 			m.selected = 0
+			m.showDetail = false
+			m.brainReturn = nil
 				return m, nil
 			}
 		if m.currentView == viewProjectMenu {
@@ -645,6 +787,7 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.currentView == viewTasks || m.currentView == viewBrain {
 			m.currentView = viewProjectMenu
 			m.selected = 0
+			m.brainReturn = nil
 			return m, nil
 		}
 		if m.currentView == viewProjectMenu {
@@ -711,6 +854,9 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				// Accept decision
 				_, err := m.client.AcceptDecision(m.currentProj.ID, d.ID)
 				if err != nil {
+					if errors.Is(err, api.ErrSessionExpired) {
+						return m.expireSession(), nil
+					}
 					m.errorMsg = fmt.Sprintf("Failed to accept: %v", err)
 				} else {
 					m.loading = true
@@ -728,6 +874,9 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				// Deprecate decision
 				_, err := m.client.DeprecateDecision(m.currentProj.ID, d.ID)
 				if err != nil {
+					if errors.Is(err, api.ErrSessionExpired) {
+						return m.expireSession(), nil
+					}
 					m.errorMsg = fmt.Sprintf("Failed to deprecate: %v", err)
 				} else {
 					m.loading = true
@@ -743,6 +892,9 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			mem := m.memories[m.selected]
 			err := m.client.DeleteMemory(m.currentProj.ID, mem.ID)
 			if err != nil {
+				if errors.Is(err, api.ErrSessionExpired) {
+					return m.expireSession(), nil
+				}
 				m.errorMsg = fmt.Sprintf("Failed to delete: %v", err)
 			} else {
 				m.loading = true
@@ -752,6 +904,9 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			task := m.tasks[m.selected]
 			err := m.client.DeleteTask(m.currentProj.ID, task.ID)
 			if err != nil {
+				if errors.Is(err, api.ErrSessionExpired) {
+					return m.expireSession(), nil
+				}
 				m.errorMsg = fmt.Sprintf("Failed to delete: %v", err)
 			} else {
 				m.loading = true
@@ -772,6 +927,9 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			_, err := m.client.UpdateTask(m.currentProj.ID, task.ID, api.UpdateTaskRequest{Status: newStatus})
 			if err != nil {
+				if errors.Is(err, api.ErrSessionExpired) {
+					return m.expireSession(), nil
+				}
 				m.errorMsg = fmt.Sprintf("Failed to update: %v", err)
 			} else {
 				m.loading = true
@@ -779,6 +937,9 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 		}
 		
+	case "/":
+		return m.startFilter(), nil
+		
 	case "enter", " ":
 		return m.handleSelect()
 	}
@@ -789,9 +950,10 @@ func (m model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m model) handleSelect() (tea.Model, tea.Cmd) {
 	switch m.currentView {
 	case viewLogin:
-		// Start login flow
-		m.executeCmd = "login"
-		return m, tea.Quit
+		if m.loginInProgress() {
+			return m, nil
+		}
+		return m.startLogin()
 		
 	case viewOrganizations:
 		if m.selected < len(m.organizations) {
@@ -808,22 +970,14 @@ func (m model) handleSelect() (tea.Model, tea.Cmd) {
 		orgProjects := m.getOrgProjects()
 		if m.selected < len(orgProjects) {
 			// Open project menu
-			m.currentProj = orgProjects[m.selected]
-			m.menuItems = []menuItem{
-				{"📊", "Dashboard", "Project overview & stats", "dashboard"},
-				{"📋", "Decisions", "View & manage decisions", "decisions"},
-				{"💾", "Memories", "Project memories & context", "memories"},
-				{"📦", "Capsules", "Context packs", "capsules"},
-				{"✅", "Tasks", "Task management", "tasks"},
-				{"🧠", "Brain", "Knowledge graph", "brain"},
-				{"🤖", "Hopper", "AI Assistant", "hopper"},
-				{"", "", "", ""},
-				{"🔙", "Back", "Return to projects", "back"},
-			}
+			m.openProject(orgProjects[m.selected])
 			m.currentView = viewProjectMenu
 			m.selected = 0
 		}
 		
+	case viewDecisions, viewMemories, viewTasks, viewCapsules:
+		m.showDetail = m.hasDetail()
+		
 	case viewProjectMenu:
 		if m.selected < len(m.menuItems) {
 			item := m.menuItems[m.selected]
@@ -841,12 +995,14 @@ func (m model) handleSelect() (tea.Model, tea.Cmd) {
 				m.currentView = viewDecisions
 				m.selected = 0
 				m.scrollOffset = 0
+				m.clearFilter()
 				m.loading = true
 				return m, m.loadDecisions
 			case "memories":
 				m.currentView = viewMemories
 				m.selected = 0
 				m.scrollOffset = 0
+				m.clearFilter()
 				m.loading = true
 				return m, m.loadMemories
 			case "capsules":
@@ -859,13 +1015,14 @@ func (m model) handleSelect() (tea.Model, tea.Cmd) {
 				m.currentView = viewTasks
 				m.selected = 0
 				m.scrollOffset = 0
+				m.clearFilter()
 				m.loading = true
 				return m, m.loadTasks
 			case "brain":
 				m.currentView = viewBrain
-				m.selected = 0
+				m.resetBrain()
 				m.loading = true
-				return m, m.loadBrainStats
+				return m, m.loadBrain
 			case "hopper":
 				m.currentView = viewHopper
 				m.selected = 0
@@ -882,6 +1039,22 @@ func (m model) handleSelect() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// openProject makes proj the current project and sets up its menu
+func (m *model) openProject(proj *api.Project) {
+	m.currentProj = proj
+	m.menuItems = []menuItem{
+		{"📊", "Dashboard", "Project overview & stats", "dashboard"},
+		{"📋", "Decisions", "View & manage decisions", "decisions"},
+		{"💾", "Memories", "Project memories & context", "memories"},
+		{"📦", "Capsules", "Context packs", "capsules"},
+		{"✅", "Tasks", "Task management", "tasks"},
+		{"🧠", "Brain", "Knowledge graph", "brain"},
+		{"🤖", "Hopper", "AI Assistant", "hopper"},
+		{"", "", "", ""},
+		{"🔙", "Back", "Return to projects", "back"},
+	}
+}
+
 func (m model) getMaxSelection() int {
 	switch m.currentView {
 	case viewLogin:
@@ -900,8 +1073,10 @@ func (m model) getMaxSelection() int {
 		return len(m.tasks)
 	case viewCapsules:
 		return len(m.capsules)
-	case viewDashboard, viewBrain:
-		return 0 // Read-only views
+	case viewBrain:
+		return m.brainCount()
+	case viewDashboard:
+		return 0 // Read-only view
 	}
 	return 0
 }
@@ -942,28 +1117,30 @@ func (m model) View() string {
 	}
 	
 	// Main content
-	switch m.currentView {
-	case viewLogin:
+	switch {
+	case m.showDetail && m.hasDetail():
+		s += m.renderDetailView()
+	case m.currentView == viewLogin:
 		s += m.renderLoginView()
-	case viewOrganizations:
+	case m.currentView == viewOrganizations:
 		s += m.renderOrganizationsView()
-	case viewProjects:
+	case m.currentView == viewProjects:
 		s += m.renderProjectsView()
-	case viewProjectMenu:
+	case m.currentView == viewProjectMenu:
 		s += m.renderProjectMenuView()
-	case viewDashboard:
+	case m.currentView == viewDashboard:
 		s += m.renderDashboardView()
-	case viewDecisions:
+	case m.currentView == viewDecisions:
 		s += m.renderDecisionsView()
-	case viewMemories:
+	case m.currentView == viewMemories:
 		s += m.renderMemoriesView()
-	case viewCapsules:
+	case m.currentView == viewCapsules:
 		s += m.renderCapsulesView()
-	case viewTasks:
+	case m.currentView == viewTasks:
 		s += m.renderTasksView()
-	case viewBrain:
+	case m.currentView == viewBrain:
 		s += m.renderBrainView()
-	case viewHopper:
+	case m.currentView == viewHopper:
 		s += m.renderHopperView()
 	}
 	
@@ -1004,29 +1181,6 @@ func (m model) renderHeader() string {
 	return s
 }
 
-func (m model) renderLoginView() string {
-	var s string
-	
-	s += "\n"
-	s += m.renderLogo()
-	s += "\n\n"
-	
-	s += "  " + titleStyle.Render("Welcome to Hopsule") + "\n"
-	s += "  " + dimStyle.Render("Decision & Memory Layer for AI teams") + "\n\n"
-	
-	// Login button
-	if m.selected == 0 {
-		s += "  " + selectedStyle.Render("> ") + accentStyle.Render("Login with Browser") + "\n"
-	} else {
-		s += "    " + normalStyle.Render("Login with Browser") + "\n"
-	}
-	
-	s += "\n"
-	s += "  " + dimStyle.Render("Press Enter to open browser and sign in") + "\n"
-	
-	return s
-}
-
 func (m model) renderOrganizationsView() string {
 	var s string
 	
@@ -1225,6 +1379,9 @@ func (m model) renderFooter() string {
 	switch m.currentView {
 	case viewLogin:
 		help = "enter login • q quit"
+		if m.loginInProgress() {
+			help = "esc cancel • ctrl+c quit"
+		}
 	case viewOrganizations:
 		help = "↑↓ navigate • enter select • q quit"
 	case viewProjects:
@@ -1234,18 +1391,27 @@ func (m model) renderFooter() string {
 	case viewDashboard:
 		help = "esc back • q quit"
 	case viewDecisions:
-		help = "↑↓ navigate • [n]ew • [a]ccept • [d]eprecate • esc back • q quit"
+		help = "↑↓ navigate • enter details • / filter • [n]ew • [a]ccept • [d]eprecate • esc back • q quit"
 	case viewMemories:
-		help = "↑↓ navigate • [n]ew • [d]elete • esc back • q quit"
+		help = "↑↓ navigate • enter details • / filter • [n]ew • [d]elete • esc back • q quit"
 	case viewCapsules:
-		help = "↑↓ navigate • esc back • q quit"
+		help = "↑↓ navigate • enter details • esc back • q quit"
 	case viewTasks:
-		help = "↑↓ navigate • [n]ew • [t]oggle • [d]elete • esc back • q quit"
+		help = "↑↓ navigate • enter details • / filter • [n]ew • [t]oggle • [d]elete • esc back • q quit"
 	case viewBrain:
-		help = "esc back • q quit"
+		help = "↑↓ navigate • enter focus • [f] type • [o]pen • esc back • q quit"
+		if m.brainFocus != nil {
+			help = "↑↓ navigate • enter/→ follow edge • ← back • [f] type • [o]pen • esc nodes • q quit"
+		}
 	case viewHopper:
 		help = "Type your message • enter send • esc back"
 	}
+	if m.showDetail && m.hasDetail() {
+		help = "↑↓ previous/next • esc back to list • ctrl+c menu"
+	}
+	if m.filtering {
+		help = "type to filter • enter keep • esc clear"
+	}
 	
 	return "  " + helpStyle.Render(help) + "\n"
 }
@@ -1417,6 +1583,7 @@ func (m model) renderDecisionsView() string {
 	if m.currentProj != nil {
 		s += "  " + dimStyle.Render(m.currentProj.Name) + "\n"
 	}
+	s += m.renderFilterBar()
 	s += "\n"
 	
 	// Header
@@ -1509,6 +1676,7 @@ func (m model) renderMemoriesView() string {
 	if m.currentProj != nil {
 		s += "  " + dimStyle.Render(m.currentProj.Name) + "\n"
 	}
+	s += m.renderFilterBar()
 	s += "\n"
 	
 	// Header
@@ -1639,6 +1807,7 @@ func (m model) renderTasksView() string {
 	if m.currentProj != nil {
 		s += "  " + dimStyle.Render(m.currentProj.Name) + "\n"
 	}
+	s += m.renderFilterBar()
 	s += "\n"
 	
 	// Header
@@ -1739,74 +1908,6 @@ func (m model) renderTasksView() string {
 	return s
 }
 
-func (m model) renderBrainView() string {
-	var s string
-	
-	// Title
-	s += "\n"
-	s += "  " + titleStyle.Render("🧠 Brain") + "\n"
-	if m.currentProj != nil {
-		s += "  " + dimStyle.Render(m.currentProj.Name) + "\n"
-	}
-	s += "\n"
-	
-	if m.graphStats == nil {
-		s += "  " + dimStyle.Render("Loading brain stats...") + "\n"
-		return s
-	}
-	
-	// Stats cards - fixed width with margin
-	statStyle := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(grayColor).
-		Padding(1, 3).
-		Width(25).
-		MarginRight(2)
-	
-	nodeCard := statStyle.Render(fmt.Sprintf("🔵 Nodes\n%s",
-		lipgloss.NewStyle().Bold(true).Foreground(cyanColor).Render(fmt.Sprintf("%d", m.graphStats.NodeCount))))
-	
-	edgeCard := statStyle.Render(fmt.Sprintf("🔗 Connections\n%s",
-		lipgloss.NewStyle().Bold(true).Foreground(magentaColor).Render(fmt.Sprintf("%d", m.graphStats.EdgeCount))))
-	
-	row := lipgloss.JoinHorizontal(lipgloss.Top, nodeCard, edgeCard)
-	s += lipgloss.NewStyle().MarginLeft(2).Render(row) + "\n\n"
-	
-	// Node types breakdown
-	s += "  " + dimStyle.Render("─────────────────────────────────────────────────────") + "\n\n"
-	s += "  " + titleStyle.Render("Nodes by Type") + "\n\n"
-	
-	if m.graphStats.NodesByType != nil {
-		for nodeType, count := range m.graphStats.NodesByType {
-			icon := "○"
-			color := grayColor
-			switch nodeType {
-			case "decision":
-				icon = "📋"
-				color = cyanColor
-			case "memory":
-				icon = "💾"
-				color = magentaColor
-			case "task":
-				icon = "✅"
-				color = greenColor
-			case "capsule":
-				icon = "📦"
-				color = yellowColor
-			case "code_chunk":
-				icon = "📄"
-				color = blueColor
-			}
-			s += fmt.Sprintf("  %s %s: %s\n", icon, nodeType, 
-				lipgloss.NewStyle().Foreground(color).Bold(true).Render(fmt.Sprintf("%d", count)))
-		}
-	} else {
-		s += "  " + dimStyle.Render("No node data available") + "\n"
-	}
-	
-	return s
-}
-
 func (m model) renderHopperView() string {
 	var s string
 	
@@ -1946,12 +2047,24 @@ func (m model) renderHopperView() string {
 // ============================================================================
 
 func RunInteractive() (string, error) {
+	return RunInteractiveAt(nil)
+}
+
+// RunInteractiveAt runs the dashboard opened on target's detail, or at the
+// start if target is nil
+func RunInteractiveAt(target *Target) (string, error) {
 	cfg, _ := config.GetConfig()
 	if cfg == nil {
 		cfg = &config.Config{}
 	}
 	
-	p := tea.NewProgram(NewInteractiveModel(cfg), tea.WithAltScreen())
+	initial := NewInteractiveModel(cfg)
+	initial.target = target
+	if initial.search != nil {
+		defer initial.search.Close()
+	}
+	
+	p := tea.NewProgram(initial, tea.WithAltScreen())
 	finalModel, err := p.Run()
 	if err != nil {
 		return "", err
@@ -1965,120 +2078,6 @@ func RunInteractive() (string, error) {
 	return m.GetSelectedCommand(), nil
 }
 
-// ============================================================================
-// EXECUTE LOGIN
-// ============================================================================
-
-func ExecuteLogin(cfg *config.Config) error {
-	if cfg == nil {
-		cfg = &config.Config{}
-	}
-
-	if cfg.APIURL == "" {
-		cfg.APIURL = "http://localhost:8080"
-	}
-	if cfg.WebURL == "" {
-		cfg.WebURL = "http://localhost:3000"
-	}
-
-	client := api.NewClient(cfg)
-	deviceName := "CLI"
-
-	fmt.Println()
-	fmt.Println("  Initializing login...")
-
-	initResp, err := client.DeviceAuthInit(deviceName)
-	if err != nil {
-		return fmt.Errorf("failed to initialize login: %w", err)
-	}
-
-	authURL := fmt.Sprintf("%s/auth/device?code=%s", cfg.WebURL, initResp.Code)
-
-	fmt.Printf("  Device Code: %s\n\n", accentStyle.Render(initResp.Code))
-	fmt.Println("  Opening browser to complete sign-in...")
-
-	if err := openBrowser(authURL); err != nil {
-		fmt.Println("  Could not open browser automatically.")
-	}
-
-	fmt.Println()
-	fmt.Println("  If the browser doesn't open, visit:")
-	fmt.Printf("  %s\n\n", logoStyle.Render(authURL))
-
-	fmt.Println("  Waiting for authentication...")
-	fmt.Println("  (Press Ctrl+C to cancel)")
-	fmt.Println()
-
-	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-	spinnerIdx := 0
-	maxAttempts := 300
-
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		fmt.Printf("\r  %s Waiting for browser authentication... (%ds)",
-			logoStyle.Render(spinner[spinnerIdx]), attempt*2)
-		spinnerIdx = (spinnerIdx + 1) % len(spinner)
-
-		resp, err := client.DeviceAuthPoll(initResp.Code)
-		if err != nil {
-			fmt.Println()
-			return fmt.Errorf("failed to check login status: %w", err)
-		}
-
-		switch resp.Status {
-		case "complete":
-			fmt.Printf("\r  %s Authentication complete!                    \n\n",
-				statusOnStyle.Render("✓"))
-
-			cfg.Token = resp.Token
-			cfg.User = &config.User{
-				ID:        resp.UserID,
-				Email:     resp.Email,
-				Name:      resp.Name,
-				AvatarURL: resp.AvatarURL,
-			}
-
-			if err := config.SaveConfig(cfg); err != nil {
-				return fmt.Errorf("failed to save config: %w", err)
-			}
-
-			fmt.Printf("  Signed in as: %s (%s)\n\n",
-				titleStyle.Render(resp.Name),
-				dimStyle.Render(resp.Email))
-
-			return nil
-
-		case "expired":
-			fmt.Println()
-			return fmt.Errorf("login session expired - please try again")
-
-		case "pending":
-			// Continue polling
-		}
-
-		time.Sleep(2 * time.Second)
-	}
-
-	fmt.Println()
-	return fmt.Errorf("login timed out")
-}
-
-func openBrowser(url string) error {
-	var cmd *exec.Cmd
-
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", url)
-	case "linux":
-		cmd = exec.Command("xdg-open", url)
-	case "windows":
-		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
-	}
-
-	return cmd.Start()
-}
-
 // ShowOrganizations - kept for backwards compatibility
 func ShowOrganizations(cfg *config.Config) {
 	// Now handled in TUI
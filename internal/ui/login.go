@@ -0,0 +1,170 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Cagangedik/cli-tool/internal/auth"
+	"github.com/Cagangedik/cli-tool/internal/browser"
+	"github.com/Cagangedik/cli-tool/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Native login view: drives auth.DeviceFlow from Bubble Tea commands so the
+// dashboard doesn't have to exit around sign-in
+
+// loginEventMsg carries a device flow transition. flow identifies the login
+// attempt, so events from a cancelled attempt are dropped.
+type loginEventMsg struct {
+	flow *auth.DeviceFlow
+	ev   auth.Event
+}
+
+func (m model) loginInProgress() bool {
+	return m.loginFlow != nil && !m.loginFlow.State().Done()
+}
+
+// startLogin begins a new device flow
+func (m model) startLogin() (tea.Model, tea.Cmd) {
+	cfg := m.cfg
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+
+	flow := auth.NewDeviceFlow(cfg)
+	m.loginFlow = flow
+	m.loginEvent = auth.Event{}
+	m.loginQR = ""
+	m.loginNote = ""
+	m.sessionExpired = false
+
+	return m, func() tea.Msg {
+		return loginEventMsg{flow: flow, ev: flow.Start()}
+	}
+}
+
+func (m model) cancelLogin() model {
+	if m.loginFlow != nil {
+		m.loginEvent = m.loginFlow.Cancel()
+	}
+	m.loginFlow = nil
+	return m
+}
+
+// pollLogin schedules the next status check
+func pollLogin(flow *auth.DeviceFlow) tea.Cmd {
+	return tea.Tick(flow.PollInterval, func(time.Time) tea.Msg {
+		return loginEventMsg{flow: flow, ev: flow.Poll()}
+	})
+}
+
+func (m model) handleLoginEvent(msg loginEventMsg) (tea.Model, tea.Cmd) {
+	if msg.flow != m.loginFlow {
+		return m, nil
+	}
+	m.loginEvent = msg.ev
+
+	switch msg.ev.State {
+	case auth.StateInitiated:
+		if headless, reason := browser.Headless(); headless {
+			m.loginNote = fmt.Sprintf("No browser available (%s). Scan the code or open the URL on another device.", reason)
+			if qr, err := browser.QRCode(msg.ev.URL); err == nil {
+				m.loginQR = qr
+			}
+		} else if err := browser.Open(msg.ev.URL); err != nil {
+			m.loginNote = "Could not open browser automatically. Open the URL below to sign in."
+		}
+		return m, pollLogin(msg.flow)
+
+	case auth.StateWaiting:
+		return m, pollLogin(msg.flow)
+
+	case auth.StateComplete:
+		m.loginFlow = nil
+		cfg := m.cfg
+		if cfg == nil {
+			cfg = &config.Config{}
+		}
+		if err := auth.Save(cfg, msg.ev.Result); err != nil {
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+		m.cfg = cfg
+		m.connect(cfg)
+		m.currentView = viewOrganizations
+		m.loading = true
+		m.selected = 0
+		return m, m.loadData
+
+	default:
+		m.loginFlow = nil
+		if msg.ev.Err != nil {
+			m.errorMsg = msg.ev.Err.Error()
+		}
+		return m, nil
+	}
+}
+
+func (m model) renderLoginView() string {
+	var s string
+
+	s += "\n"
+	s += m.renderLogo()
+	s += "\n\n"
+
+	s += "  " + titleStyle.Render("Welcome to Hopsule") + "\n"
+	s += "  " + dimStyle.Render("Decision & Memory Layer for AI teams") + "\n\n"
+
+	if m.loginFlow != nil {
+		return s + m.renderLoginProgress()
+	}
+
+	if m.sessionExpired {
+		s += "  " + accentStyle.Render("Your session has expired. Sign in again to continue.") + "\n\n"
+	}
+	if m.loginEvent.State == auth.StateCancelled {
+		s += "  " + dimStyle.Render("Login cancelled.") + "\n\n"
+	}
+
+	// Login button
+	if m.selected == 0 {
+		s += "  " + selectedStyle.Render("> ") + accentStyle.Render("Login with Browser") + "\n"
+	} else {
+		s += "    " + normalStyle.Render("Login with Browser") + "\n"
+	}
+
+	s += "\n"
+	s += "  " + dimStyle.Render("Press Enter to open browser and sign in") + "\n"
+
+	return s
+}
+
+func (m model) renderLoginProgress() string {
+	var s string
+	ev := m.loginEvent
+
+	if ev.State == auth.StateIdle {
+		return s + "  " + dimStyle.Render("Initializing login...") + "\n"
+	}
+
+	s += "  Device Code: " + accentStyle.Render(ev.Code) + "\n\n"
+
+	if m.loginNote != "" {
+		s += "  " + dimStyle.Render(m.loginNote) + "\n\n"
+	}
+	if m.loginQR != "" {
+		s += "  " + strings.ReplaceAll(m.loginQR, "\n", "\n  ") + "\n\n"
+	}
+
+	s += "  " + logoStyle.Render(ev.URL) + "\n\n"
+
+	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	frame := spinner[int(ev.Elapsed/auth.DefaultPollInterval)%len(spinner)]
+	s += fmt.Sprintf("  %s Waiting for browser authentication... (%ds)\n",
+		logoStyle.Render(frame), int(ev.Elapsed.Seconds()))
+	s += "\n"
+	s += "  " + dimStyle.Render("Press Esc to cancel") + "\n"
+
+	return s
+}
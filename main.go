@@ -3,9 +3,12 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/Cagangedik/cli-tool/internal/api"
 	"github.com/Cagangedik/cli-tool/internal/commands"
 	"github.com/Cagangedik/cli-tool/internal/config"
+	"github.com/Cagangedik/cli-tool/internal/logging"
 	"github.com/Cagangedik/cli-tool/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -17,9 +20,12 @@ var (
 	date    = "unknown"
 )
 
-func runInteractiveTUI() {
+// runInteractiveTUI runs the dashboard, first opened on target if it isn't
+// nil, until the user quits
+func runInteractiveTUI(target *ui.Target) {
 	for {
-		action, err := ui.RunInteractive()
+		action, err := ui.RunInteractiveAt(target)
+		target = nil
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
@@ -37,21 +43,16 @@ func runInteractiveTUI() {
 
 		// Execute the selected action
 		switch {
-		case action == "login":
-			if err := ui.ExecuteLogin(cfg); err != nil {
-				fmt.Fprintf(os.Stderr, "\n  Login failed: %v\n\n", err)
-				fmt.Println("  Press Enter to continue...")
-				fmt.Scanln()
-			}
-			// Restart TUI after login
-			continue
-
 		case action == "logout":
-			if cfg.IsAuthenticated() {
+			if cfg.Token != "" {
 				userName := ""
 				if cfg.User != nil {
 					userName = cfg.User.Name
 				}
+				if api.TokenTypeOf(cfg.Token) == api.TokenTypeUser && !cfg.SessionExpired() {
+					// Best effort: the local sign-out still happens if this fails
+					_ = api.NewClient(cfg).Logout()
+				}
 				if err := config.ClearAuth(cfg); err != nil {
 					fmt.Fprintf(os.Stderr, "\n  Logout failed: %v\n\n", err)
 				} else {
@@ -77,6 +78,59 @@ func runInteractiveTUI() {
 	}
 }
 
+// setupLogging enables diagnostic logging from --verbose, --debug, --log-file
+// and HOPSULE_DEBUG / HOPSULE_LOG_FILE
+func setupLogging(cmd *cobra.Command) error {
+	flags := cmd.Root().PersistentFlags()
+
+	level, err := logging.ParseLevel(os.Getenv(logging.DebugEnv))
+	if err != nil {
+		return err
+	}
+	if verbose, _ := flags.GetBool("verbose"); verbose && level > logging.LevelVerbose {
+		level = logging.LevelVerbose
+	}
+	if debug, _ := flags.GetBool("debug"); debug {
+		level = logging.LevelDebug
+	}
+	if level >= logging.LevelOff {
+		return nil
+	}
+
+	file, _ := flags.GetString("log-file")
+	if file == "" {
+		file = os.Getenv(logging.FileEnv)
+	}
+	// The TUI owns the terminal, so its logs always go to a file
+	if file == "default" || (file == "" && cmd == cmd.Root()) {
+		stateDir, err := config.StateDir()
+		if err != nil {
+			return err
+		}
+		file = logging.DefaultFile(stateDir)
+	}
+
+	return logging.Setup(logging.Options{Level: level, File: file})
+}
+
+// setupRecording starts recording or replaying API traffic for --record and
+// --replay
+func setupRecording(cmd *cobra.Command) error {
+	flags := cmd.Root().PersistentFlags()
+	record, _ := flags.GetString("record")
+	replay, _ := flags.GetString("replay")
+
+	switch {
+	case record != "" && replay != "":
+		return fmt.Errorf("--record and --replay can't be used together")
+	case record != "":
+		return api.RecordTo(record)
+	case replay != "":
+		return api.ReplayFrom(replay)
+	}
+	return nil
+}
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "hopsule",
@@ -86,9 +140,40 @@ func main() {
 Hopsule helps you track architectural decisions, project context, 
 and team knowledge in a portable, AI-friendly format.`,
 		Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			if profile, _ := cmd.Flags().GetString("profile"); profile != "" {
+				config.SetActiveProfile(profile)
+			}
+
+			// Flag values are layered over the config file and env vars, but
+			// never saved to the file
+			overrides := config.Overrides{}
+			overrides.APIURL, _ = cmd.Root().PersistentFlags().GetString("api-url")
+			overrides.Token, _ = cmd.Root().PersistentFlags().GetString("token")
+			overrides.Project, _ = cmd.Root().PersistentFlags().GetString("project")
+			overrides.Proxy, _ = cmd.Root().PersistentFlags().GetString("proxy")
+			caFiles, _ := cmd.Root().PersistentFlags().GetStringSlice("ca-file")
+			overrides.CAFile = strings.Join(caFiles, ",")
+			overrides.ClientCert, _ = cmd.Root().PersistentFlags().GetString("client-cert")
+			overrides.ClientKey, _ = cmd.Root().PersistentFlags().GetString("client-key")
+			overrides.Insecure, _ = cmd.Root().PersistentFlags().GetBool("insecure")
+			if timeout, _ := cmd.Root().PersistentFlags().GetDuration("timeout"); timeout > 0 {
+				overrides.Timeout = timeout.String()
+			}
+			overrides.UnixSocket, _ = cmd.Root().PersistentFlags().GetString("unix-socket")
+			config.Default().SetOverrides(overrides)
+
+			if err := setupLogging(cmd); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			if err := setupRecording(cmd); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			// Run interactive TUI when no subcommand is provided
-			runInteractiveTUI()
+			runInteractiveTUI(nil)
 		},
 	}
 
@@ -96,6 +181,24 @@ and team knowledge in a portable, AI-friendly format.`,
 	rootCmd.PersistentFlags().String("api-url", "", "Override API URL")
 	rootCmd.PersistentFlags().String("token", "", "Override authentication token")
 	rootCmd.PersistentFlags().String("project", "", "Override project ID")
+	rootCmd.PersistentFlags().String("profile", "", "Config profile to use (default: $HOPSULE_PROFILE or current profile)")
+
+	// Network flags (override the network settings in the config file)
+	rootCmd.PersistentFlags().String("proxy", "", "Proxy URL for API requests, or 'direct' to ignore HTTP(S)_PROXY")
+	rootCmd.PersistentFlags().StringSlice("ca-file", nil, "Extra CA bundle to trust (repeatable)")
+	rootCmd.PersistentFlags().String("client-cert", "", "Client certificate for mutual TLS (PEM)")
+	rootCmd.PersistentFlags().String("client-key", "", "Private key for --client-cert, if not in the same file")
+	rootCmd.PersistentFlags().Bool("insecure", false, "Skip TLS certificate verification (local development only)")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Timeout for API requests (default 30s)")
+	rootCmd.PersistentFlags().String("unix-socket", "", "Connect to the API over a Unix domain socket")
+
+	// Diagnostic flags
+	rootCmd.PersistentFlags().Bool("verbose", false, "Log each API request (method, URL, status, timing) to stderr")
+	rootCmd.PersistentFlags().Bool("debug", false, "Like --verbose, plus redacted headers and bodies")
+	rootCmd.PersistentFlags().String("log-file", "", "Write logs to this file instead of stderr (default with no path: state dir)")
+	rootCmd.PersistentFlags().Lookup("log-file").NoOptDefVal = "default"
+	rootCmd.PersistentFlags().String("record", "", "Record API requests and responses to a HAR file (tokens are scrubbed)")
+	rootCmd.PersistentFlags().String("replay", "", "Serve API responses from a HAR file instead of the network")
 
 	// ========================================================================
 	// AUTH COMMANDS
@@ -103,6 +206,7 @@ and team knowledge in a portable, AI-friendly format.`,
 	rootCmd.AddCommand(commands.NewLoginCommand())
 	rootCmd.AddCommand(commands.NewLogoutCommand())
 	rootCmd.AddCommand(commands.NewWhoamiCommand())
+	rootCmd.AddCommand(commands.NewAuthCommand())
 
 	// ========================================================================
 	// ORGANIZATION & PROJECT COMMANDS
@@ -110,6 +214,7 @@ and team knowledge in a portable, AI-friendly format.`,
 	rootCmd.AddCommand(commands.NewOrgsCommand())
 	rootCmd.AddCommand(commands.NewProjectsCommand())
 	rootCmd.AddCommand(commands.NewInitCommand())
+	rootCmd.AddCommand(commands.NewPushCommand())
 
 	// ========================================================================
 	// DECISION COMMANDS
@@ -119,13 +224,26 @@ and team knowledge in a portable, AI-friendly format.`,
 	rootCmd.AddCommand(commands.NewCreateCommand())
 	rootCmd.AddCommand(commands.NewAcceptCommand())
 	rootCmd.AddCommand(commands.NewDeprecateCommand())
+	rootCmd.AddCommand(commands.NewSearchCommand())
+	commands.OpenDashboard = func(target *ui.Target) error {
+		runInteractiveTUI(target)
+		return nil
+	}
+
+	// ========================================================================
+	// GRAPH COMMANDS
+	// ========================================================================
+	rootCmd.AddCommand(commands.NewRelatedCommand())
+	rootCmd.AddCommand(commands.NewGraphCommand())
 
 	// ========================================================================
 	// UTILITY COMMANDS
 	// ========================================================================
 	rootCmd.AddCommand(commands.NewConfigCommand())
+	rootCmd.AddCommand(commands.NewProfileCommand())
 	rootCmd.AddCommand(commands.NewStatusCommand())
 	rootCmd.AddCommand(commands.NewSyncCommand())
+	rootCmd.AddCommand(commands.NewDoctorCommand())
 
 	// Execute
 	if err := rootCmd.Execute(); err != nil {